@@ -0,0 +1,237 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yendefrr/commet/internal/changelog"
+	"github.com/yendefrr/commet/internal/config"
+	"github.com/yendefrr/commet/internal/fsutil"
+	"github.com/yendefrr/commet/internal/git"
+	"github.com/yendefrr/commet/internal/github"
+
+	"github.com/BurntSushi/toml"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	aggregateSources string
+	aggregateOutput  string
+)
+
+var aggregateCmd = &cobra.Command{
+	Use:   "aggregate",
+	Short: "Combine the latest release notes of several repositories",
+	Long: `Builds one release bulletin for a product made of many services from the
+latest release of each repository listed in the sources file:
+
+  title = "Acme Platform"
+  changelog = "CHANGELOG.md"   # default changelog path in each repository
+
+  [[sources]]
+  name = "api"
+  path = "../api"              # local checkout: changelog at its latest tag
+
+  [[sources]]
+  name = "web"
+  url = "https://github.com/acme/web.git"   # read without a checkout
+
+  [[sources]]
+  name = "worker"
+  github = "acme/worker"       # notes of the latest GitHub release
+
+GitHub releases use GITHUB_TOKEN when set, as do remote repositories on github.com
+or a host of git.github_hosts.`,
+	Args: cobra.NoArgs,
+	RunE: aggregate,
+}
+
+func init() {
+	rootCmd.AddCommand(aggregateCmd)
+
+	aggregateCmd.Flags().StringVar(&aggregateSources, "sources", "repos.toml", "file listing the repositories to aggregate")
+	aggregateCmd.Flags().StringVarP(&aggregateOutput, "output", "o", "", "write the bulletin to this file instead of stdout")
+}
+
+// aggregateConfig is the sources file of "commet aggregate".
+type aggregateConfig struct {
+	// Title heads the bulletin (default "Release bulletin")
+	Title string `toml:"title"`
+	// Changelog is the changelog path in each repository (default "CHANGELOG.md")
+	Changelog string            `toml:"changelog"`
+	Sources   []aggregateSource `toml:"sources"`
+}
+
+// aggregateSource is one repository; exactly one of Path, URL and GitHub is set.
+type aggregateSource struct {
+	Name string `toml:"name"`
+	// Path is a local checkout
+	Path string `toml:"path"`
+	// URL is a git remote, cloned into memory
+	URL string `toml:"url"`
+	// GitHub is owner/repo, whose latest GitHub release is used
+	GitHub string `toml:"github"`
+	// Changelog overrides the changelog path for this repository
+	Changelog string `toml:"changelog"`
+	// TagPattern overrides detection.tag_pattern for this repository
+	TagPattern string `toml:"tag_pattern"`
+}
+
+// sourceRelease is the latest release of a source.
+type sourceRelease struct {
+	version string
+	notes   string
+}
+
+func aggregate(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	var sources aggregateConfig
+	if _, err := toml.DecodeFile(aggregateSources, &sources); err != nil {
+		return fmt.Errorf("failed to read %s: %w", aggregateSources, err)
+	}
+	if len(sources.Sources) == 0 {
+		return fmt.Errorf("%s lists no sources", aggregateSources)
+	}
+	if sources.Title == "" {
+		sources.Title = "Release bulletin"
+	}
+	if sources.Changelog == "" {
+		sources.Changelog = "CHANGELOG.md"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n", sources.Title)
+
+	failed := 0
+	for _, source := range sources.Sources {
+		release, err := latestSourceRelease(cfg, sources, source)
+		if err != nil {
+			color.Yellow("[WARN] %s: %v", source.Name, err)
+			failed++
+			continue
+		}
+
+		if verbose {
+			color.Cyan("[AGGREGATE] %s: %s", source.Name, release.version)
+		}
+
+		fmt.Fprintf(&sb, "\n## %s %s\n\n", source.Name, release.version)
+		if release.notes != "" {
+			sb.WriteString(demoteHeadings(release.notes) + "\n")
+		}
+	}
+
+	if failed == len(sources.Sources) {
+		return fmt.Errorf("no release notes found for any source")
+	}
+
+	if aggregateOutput == "" {
+		fmt.Fprint(color.Output, sb.String())
+		return nil
+	}
+
+	if err := fsutil.WriteFile(aggregateOutput, []byte(sb.String())); err != nil {
+		return fmt.Errorf("failed to write %s: %w", aggregateOutput, err)
+	}
+	color.Green("✓ Wrote the bulletin of %d repositories to %s", len(sources.Sources)-failed, aggregateOutput)
+
+	return nil
+}
+
+// latestSourceRelease reads the latest release of source: its changelog
+// entry at the highest version tag, or its latest GitHub release.
+func latestSourceRelease(cfg *config.Config, sources aggregateConfig, source aggregateSource) (*sourceRelease, error) {
+	sourceCfg := *cfg
+	if source.TagPattern != "" {
+		sourceCfg.Detection.TagPattern = source.TagPattern
+	}
+
+	changelogFile := source.Changelog
+	if changelogFile == "" {
+		changelogFile = sources.Changelog
+	}
+
+	switch {
+	case source.GitHub != "":
+		owner, repo, ok := strings.Cut(source.GitHub, "/")
+		if !ok {
+			return nil, fmt.Errorf("github must be owner/repo, got %q", source.GitHub)
+		}
+
+		release, err := github.NewClient().GetLatestRelease(owner, repo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the latest release: %w", err)
+		}
+
+		ver, err := git.ExtractVersion(sourceCfg.Detection.TagPattern, release.TagName)
+		if err != nil {
+			ver = release.TagName
+		}
+		return &sourceRelease{version: ver, notes: strings.TrimSpace(release.Body)}, nil
+
+	case source.URL != "":
+		client, remoteRange, err := git.NewRemoteClient(source.URL, "", "HEAD", &sourceCfg)
+		if err != nil {
+			return nil, err
+		}
+		if remoteRange.From == "" {
+			return nil, fmt.Errorf("no version tag found")
+		}
+		return changelogRelease(&sourceCfg, client, remoteRange.From, remoteRange.FromHash, changelogFile)
+
+	case source.Path != "":
+		client, err := git.NewClient(source.Path, &sourceCfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", source.Path, err)
+		}
+		tag, err := client.GetLatestTag()
+		if err != nil {
+			return nil, err
+		}
+		if tag == "" {
+			return nil, fmt.Errorf("no version tag found")
+		}
+		return changelogRelease(&sourceCfg, client, tag, tag, changelogFile)
+
+	default:
+		return nil, fmt.Errorf("one of path, url or github is required")
+	}
+}
+
+// changelogRelease reads the changelog entry of tag from the changelog file
+// as of rev, its header showing the version as the scheme of cfg formats it.
+func changelogRelease(cfg *config.Config, client *git.Client, tag, rev, changelogFile string) (*sourceRelease, error) {
+	ver, err := client.ExtractVersionFromTag(tag)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := client.ReadFile(rev, changelogFile)
+	if err != nil {
+		return nil, err
+	}
+
+	notes, ok := changelog.Entry(string(content), changelogVersion(cfg, ver))
+	if !ok {
+		return nil, fmt.Errorf("%s has no entry for %s", changelogFile, ver)
+	}
+
+	return &sourceRelease{version: ver, notes: notes}, nil
+}
+
+// demoteHeadings nests the headings of release notes one level deeper, below
+// the heading of their repository.
+func demoteHeadings(notes string) string {
+	lines := strings.Split(notes, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "#") {
+			lines[i] = "#" + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
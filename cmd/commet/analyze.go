@@ -0,0 +1,203 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yendefrr/commet/internal/changelog"
+	"github.com/yendefrr/commet/internal/config"
+	"github.com/yendefrr/commet/internal/git"
+	"github.com/yendefrr/commet/internal/github"
+	"github.com/yendefrr/commet/internal/parser"
+	"github.com/yendefrr/commet/internal/version"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	githubCompare string
+	remoteURL     string
+)
+
+var analyzeCmd = &cobra.Command{
+	Use:   "analyze",
+	Short: "Report the projected version without a local clone",
+	Long: `Fetches commits from a remote source and reports the version commet would release
+along with the release notes.
+
+--github-compare reads a compare range through the GitHub API; its base must be
+a version tag matching detection.tag_pattern. --remote clones any git URL into
+memory, only as deep as the --from tag (default: the highest version tag), and
+analyzes up to --to (default: the remote's default branch).`,
+	RunE: analyze,
+}
+
+func init() {
+	rootCmd.AddCommand(analyzeCmd)
+
+	analyzeCmd.Flags().StringVar(&githubCompare, "github-compare", "", "GitHub compare URL, e.g. https://github.com/org/repo/compare/v1.2.0...main")
+	analyzeCmd.Flags().StringVar(&remoteURL, "remote", "", "git URL to clone into memory, e.g. https://github.com/org/repo.git")
+}
+
+// projection is the release commet would cut for a remote commit range.
+type projection struct {
+	currentVersion string
+	nextVersion    string
+	bumpType       config.BumpType
+	commits        int
+	validCommits   int
+	parsed         []*parser.Commit
+}
+
+func analyze(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if remoteURL != "" {
+		return analyzeRemote(cfg)
+	}
+
+	if githubCompare == "" {
+		return fmt.Errorf("--github-compare or --remote is required")
+	}
+
+	comparison, err := github.ParseCompareURL(githubCompare)
+	if err != nil {
+		return err
+	}
+
+	if verbose {
+		color.Cyan("[GITHUB] Comparing %s/%s %s...%s", comparison.Owner, comparison.Repo, comparison.Base, comparison.Head)
+	}
+
+	result, err := projectComparison(cfg, github.NewClient(), comparison)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	color.Green("Repository:      %s/%s", comparison.Owner, comparison.Repo)
+	color.Green("Range:           %s...%s", comparison.Base, comparison.Head)
+
+	return printProjection(cfg, result)
+}
+
+// analyzeRemote projects the release of a repository cloned into memory.
+func analyzeRemote(cfg *config.Config) error {
+	if verbose {
+		color.Cyan("[GIT] Cloning %s into memory", remoteURL)
+	}
+
+	gitClient, remoteRange, err := git.NewRemoteClient(remoteURL, fromRef, toRef, cfg)
+	if err != nil {
+		return err
+	}
+
+	currentVersion := cfg.Version.Initial
+	base := "the first commit"
+	if remoteRange.From != "" {
+		currentVersion, err = gitClient.ExtractVersionFromTag(remoteRange.From)
+		if err != nil {
+			return fmt.Errorf("cannot determine current version from tag %s: %w", remoteRange.From, err)
+		}
+		base = remoteRange.From
+	}
+
+	commits, err := gitClient.Log(remoteRange.FromHash, "HEAD")
+	if err != nil {
+		return fmt.Errorf("failed to get commits: %w", err)
+	}
+
+	result, err := project(cfg, currentVersion, commits)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	color.Green("Repository:      %s", remoteURL)
+	color.Green("Range:           %s...%s", base, remoteRange.To)
+
+	return printProjection(cfg, result)
+}
+
+// printProjection reports the projected release and its notes.
+func printProjection(cfg *config.Config, result *projection) error {
+	color.Green("Commits:         %d (%d valid)", result.commits, result.validCommits)
+	color.Green("Current version: %s", result.currentVersion)
+	if result.bumpType == config.BumpNone {
+		color.Green("No version bump needed")
+		return nil
+	}
+	color.Green("Next version:    %s", result.nextVersion)
+	color.Green("Bump type:       %s", strings.ToUpper(string(result.bumpType)))
+
+	notes, err := changelog.NewGenerator(cfg.Changelog.File, cfg.Changelog).Render(result.nextVersion, result.parsed)
+	if err != nil {
+		return fmt.Errorf("failed to render notes: %w", err)
+	}
+
+	fmt.Println()
+	fmt.Fprint(color.Output, notes)
+
+	return nil
+}
+
+// projectComparison fetches the commits of a GitHub compare range and
+// calculates the version that would follow its base tag.
+func projectComparison(cfg *config.Config, client *github.Client, comparison *github.Comparison) (*projection, error) {
+	currentVersion, err := git.ExtractVersion(cfg.Detection.TagPattern, comparison.Base)
+	if err != nil {
+		return nil, fmt.Errorf("cannot determine current version from base ref %s: %w", comparison.Base, err)
+	}
+
+	remoteCommits, err := client.Compare(comparison)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch commits: %w", err)
+	}
+
+	commits := make([]*git.CommitInfo, 0, len(remoteCommits))
+	for _, c := range remoteCommits {
+		if cfg.Detection.ExcludeMerges && c.Parents > 1 {
+			continue
+		}
+
+		message, body := git.SplitMessage(c.Message)
+		commits = append(commits, &git.CommitInfo{
+			Hash:     git.Abbrev(c.SHA, cfg.Changelog.HashLength),
+			FullHash: c.SHA,
+			Message:  message,
+			Body:     body,
+			Author:   c.Author,
+			Date:     c.Date.Format("2006-01-02"),
+		})
+	}
+
+	if verbose {
+		color.Cyan("[GITHUB] Found %d commits", len(commits))
+	}
+
+	return project(cfg, currentVersion, commits)
+}
+
+// project calculates the release that would follow currentVersion.
+func project(cfg *config.Config, currentVersion string, commits []*git.CommitInfo) (*projection, error) {
+	parsedCommits := parseReleaseCommits(cfg, commits)
+
+	calculator := version.NewCalculator(cfg)
+	newVersion, bumpType, err := calculator.Calculate(currentVersion, parsedCommits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate version: %w", err)
+	}
+
+	return &projection{
+		currentVersion: currentVersion,
+		nextVersion:    newVersion,
+		bumpType:       bumpType,
+		commits:        len(commits),
+		validCommits:   len(parsedCommits),
+		parsed:         parsedCommits,
+	}, nil
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yendefrr/commet/internal/config"
+	"github.com/yendefrr/commet/internal/git"
+	"github.com/yendefrr/commet/internal/parser"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	annotateType        string
+	annotateScope       string
+	annotateDescription string
+	annotateBreaking    bool
+	annotateBump        string
+	annotateRemove      bool
+)
+
+var annotateCmd = &cobra.Command{
+	Use:   "annotate <commit>",
+	Short: "Override how an already pushed commit is released",
+	Long: `Attaches overrides to a commit as a git note in refs/notes/commet, for
+commits that cannot be reworded any more. The analyzer merges them before the
+bump is calculated and the changelog is written, e.g.
+
+  commet annotate 1a2b3c4 --breaking
+  commet annotate 1a2b3c4 --type Fix --description "handle empty responses"
+
+Without flags the current note is printed. Push the notes to share them:
+git push origin refs/notes/commet`,
+	Args: cobra.ExactArgs(1),
+	RunE: annotateCommit,
+}
+
+func init() {
+	rootCmd.AddCommand(annotateCmd)
+
+	annotateCmd.Flags().StringVar(&annotateType, "type", "", "reclassify the commit as this type")
+	annotateCmd.Flags().StringVar(&annotateScope, "scope", "", "replace the scope")
+	annotateCmd.Flags().StringVar(&annotateDescription, "description", "", "replace the description in the changelog")
+	annotateCmd.Flags().BoolVar(&annotateBreaking, "breaking", false, "mark the commit as breaking; --breaking=false clears it")
+	annotateCmd.Flags().StringVar(&annotateBump, "bump", "", "override the bump: none, build, patch, minor, major or a level from bump_levels")
+	annotateCmd.Flags().BoolVar(&annotateRemove, "remove", false, "remove the commit's note")
+}
+
+func annotateCommit(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	gitClient, err := git.NewClient(".", cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize git: %w", err)
+	}
+
+	notes, err := gitClient.Notes()
+	if err != nil {
+		return err
+	}
+
+	hash, err := gitClient.ResolveCommit(args[0])
+	if err != nil {
+		return err
+	}
+	short := git.Abbrev(hash, cfg.Changelog.HashLength)
+
+	if annotateRemove {
+		if dryRun {
+			color.Yellow("[DRY RUN] Would remove the note of %s", short)
+			return nil
+		}
+		if _, err := gitClient.SetNote(hash, ""); err != nil {
+			return err
+		}
+		color.Green("✓ Removed the note of %s", short)
+		return nil
+	}
+
+	var overrides [][2]string
+	for _, flag := range []struct{ name, key, value string }{
+		{"type", "Type", annotateType},
+		{"scope", "Scope", annotateScope},
+		{"description", "Description", annotateDescription},
+		{"breaking", "Breaking", strconv.FormatBool(annotateBreaking)},
+		{"bump", "Bump", string(cfg.ResolveBump(config.BumpType(annotateBump)))},
+	} {
+		if cmd.Flags().Changed(flag.name) {
+			overrides = append(overrides, [2]string{flag.key, flag.value})
+		}
+	}
+
+	if len(overrides) == 0 {
+		if note := notes[hash]; note != "" {
+			fmt.Print(note)
+		} else {
+			color.Yellow("%s has no commet note", short)
+		}
+		return nil
+	}
+
+	note := mergeNote(notes[hash], overrides)
+	if err := (&parser.Commit{}).Annotate(note); err != nil {
+		return err
+	}
+
+	if dryRun {
+		color.Yellow("[DRY RUN] Would annotate %s:", short)
+		fmt.Print(note)
+		return nil
+	}
+
+	if _, err := gitClient.SetNote(hash, note); err != nil {
+		return err
+	}
+
+	color.Green("✓ Annotated %s:", short)
+	fmt.Print(note)
+	color.Cyan("Share it with: git push origin %s", git.NotesRef)
+
+	return nil
+}
+
+// mergeNote sets each key of overrides in note, keeping its other lines.
+func mergeNote(note string, overrides [][2]string) string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(note), "\n") {
+		if line == "" {
+			continue
+		}
+		key, _, _ := strings.Cut(line, ":")
+		replaced := false
+		for _, override := range overrides {
+			if strings.EqualFold(strings.TrimSpace(key), override[0]) {
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			lines = append(lines, line)
+		}
+	}
+
+	for _, override := range overrides {
+		lines = append(lines, override[0]+": "+override[1])
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
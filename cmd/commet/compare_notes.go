@@ -0,0 +1,242 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/yendefrr/commet/internal/changelog"
+	"github.com/yendefrr/commet/internal/config"
+	"github.com/yendefrr/commet/internal/git"
+	"github.com/yendefrr/commet/internal/parser"
+	"github.com/yendefrr/commet/internal/version"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var compareSideBySide bool
+
+// compareColumnWidth is the width of each column in side-by-side mode.
+const compareColumnWidth = 60
+
+var compareNotesCmd = &cobra.Command{
+	Use:   "compare-notes [older-tag] [newer-tag]",
+	Short: "Compare the notes of two releases",
+	Long: `Summarizes how two releases differ: commits per type and scopes that appeared
+or disappeared. Without arguments the two latest releases are compared.
+With --side-by-side the rendered notes are printed next to each other.`,
+	Args: cobra.RangeArgs(0, 2),
+	RunE: compareNotes,
+}
+
+func init() {
+	rootCmd.AddCommand(compareNotesCmd)
+
+	compareNotesCmd.Flags().BoolVar(&compareSideBySide, "side-by-side", false, "print the rendered notes next to each other")
+}
+
+// releaseNotes holds the commits that went into a tagged release.
+type releaseNotes struct {
+	tag     string
+	version string
+	commits []*parser.Commit
+}
+
+func compareNotes(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	gitClient, err := git.NewClient(".", cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize git: %w", err)
+	}
+
+	tags, err := gitClient.Tags()
+	if err != nil {
+		return err
+	}
+	tags = sortVersionTags(cfg, tags)
+
+	var older, newer string
+	switch len(args) {
+	case 0:
+		if len(tags) < 2 {
+			return fmt.Errorf("need at least two release tags to compare, found %d", len(tags))
+		}
+		older, newer = tags[1], tags[0]
+	case 1:
+		return fmt.Errorf("pass both tags or none")
+	default:
+		older, newer = args[0], args[1]
+	}
+
+	olderNotes, err := loadReleaseNotes(cfg, gitClient, tags, older)
+	if err != nil {
+		return err
+	}
+
+	newerNotes, err := loadReleaseNotes(cfg, gitClient, tags, newer)
+	if err != nil {
+		return err
+	}
+
+	if compareSideBySide {
+		return printNotesSideBySide(cfg, olderNotes, newerNotes)
+	}
+
+	printNotesSummary(olderNotes, newerNotes)
+	return nil
+}
+
+// sortVersionTags returns the tags that carry a version, highest version first.
+func sortVersionTags(cfg *config.Config, tags []string) []string {
+	versions := make(map[string]string, len(tags))
+	var sorted []string
+	for _, tag := range tags {
+		v, err := git.ExtractVersion(cfg.Detection.TagPattern, tag)
+		if err != nil {
+			continue
+		}
+		versions[tag] = v
+		sorted = append(sorted, tag)
+	}
+
+	scheme := version.SchemeFor(cfg)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		result, err := scheme.Compare(versions[sorted[i]], versions[sorted[j]])
+		return err == nil && result > 0
+	})
+
+	return sorted
+}
+
+// loadReleaseNotes collects the commits between tag and the release before it.
+func loadReleaseNotes(cfg *config.Config, gitClient *git.Client, tags []string, tag string) (*releaseNotes, error) {
+	ver, err := git.ExtractVersion(cfg.Detection.TagPattern, tag)
+	if err != nil {
+		return nil, fmt.Errorf("tag %s does not match the tag pattern: %w", tag, err)
+	}
+
+	previous := ""
+	for i, t := range tags {
+		if t == tag && i+1 < len(tags) {
+			previous = tags[i+1]
+			break
+		}
+	}
+
+	commits, err := gitClient.Log(previous, tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commits of %s: %w", tag, err)
+	}
+
+	return &releaseNotes{
+		tag:     tag,
+		version: ver,
+		commits: parseReleaseCommits(cfg, commits),
+	}, nil
+}
+
+// printNotesSummary prints commit counts per type and scope changes between two releases.
+func printNotesSummary(older, newer *releaseNotes) {
+	olderTypes, olderScopes := countNotes(older.commits)
+	newerTypes, newerScopes := countNotes(newer.commits)
+
+	color.Cyan("Comparing %s (%d commits) with %s (%d commits)", older.tag, len(older.commits), newer.tag, len(newer.commits))
+	fmt.Println()
+
+	types := make(map[string]bool)
+	for t := range olderTypes {
+		types[t] = true
+	}
+	for t := range newerTypes {
+		types[t] = true
+	}
+
+	var names []string
+	for t := range types {
+		names = append(names, t)
+	}
+	sort.Strings(names)
+
+	writer := newTable()
+	fmt.Fprintf(writer, "TYPE\t%s\t%s\tCHANGE\n", older.tag, newer.tag)
+	for _, t := range names {
+		fmt.Fprintf(writer, "%s\t%d\t%d\t%+d\n", t, olderTypes[t], newerTypes[t], newerTypes[t]-olderTypes[t])
+	}
+	writer.Flush()
+	fmt.Println()
+
+	if added := missingScopes(newerScopes, olderScopes); len(added) > 0 {
+		color.Green("New scopes:     %s", strings.Join(added, ", "))
+	}
+	if dropped := missingScopes(olderScopes, newerScopes); len(dropped) > 0 {
+		color.Yellow("Dropped scopes: %s", strings.Join(dropped, ", "))
+	}
+}
+
+// countNotes returns the number of commits per type and the set of scopes.
+func countNotes(commits []*parser.Commit) (map[string]int, map[string]bool) {
+	types := make(map[string]int)
+	scopes := make(map[string]bool)
+	for _, commit := range commits {
+		types[commit.Type]++
+		for _, scope := range strings.Split(commit.Scope, ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				scopes[scope] = true
+			}
+		}
+	}
+	return types, scopes
+}
+
+// missingScopes returns the sorted scopes of a that are not in b.
+func missingScopes(a, b map[string]bool) []string {
+	var missing []string
+	for scope := range a {
+		if !b[scope] {
+			missing = append(missing, scope)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// printNotesSideBySide renders both releases' changelog entries in two columns.
+func printNotesSideBySide(cfg *config.Config, older, newer *releaseNotes) error {
+	generator := changelog.NewGenerator(cfg.Changelog.File, cfg.Changelog)
+
+	left, err := generator.Render(older.version, older.commits)
+	if err != nil {
+		return fmt.Errorf("failed to render notes of %s: %w", older.tag, err)
+	}
+
+	right, err := generator.Render(newer.version, newer.commits)
+	if err != nil {
+		return fmt.Errorf("failed to render notes of %s: %w", newer.tag, err)
+	}
+
+	leftLines := strings.Split(strings.TrimRight(left, "\n"), "\n")
+	rightLines := strings.Split(strings.TrimRight(right, "\n"), "\n")
+
+	rows := len(leftLines)
+	if len(rightLines) > rows {
+		rows = len(rightLines)
+	}
+
+	for i := 0; i < rows; i++ {
+		var l, r string
+		if i < len(leftLines) {
+			l = truncate(leftLines[i], compareColumnWidth)
+		}
+		if i < len(rightLines) {
+			r = truncate(rightLines[i], compareColumnWidth)
+		}
+		fmt.Fprintf(color.Output, "%-*s │ %s\n", compareColumnWidth, l, r)
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+
+	"github.com/yendefrr/commet/internal/config"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// commetVersion is set at build time with -ldflags "-X main.commetVersion=1.5.0".
+// Binaries installed with go install fall back to the module version.
+var commetVersion = ""
+
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Show information about the running commet",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fmt.Printf("commet %s\n", runningVersion())
+		return nil
+	},
+}
+
+var envRequireCmd = &cobra.Command{
+	Use:   "require [constraint]",
+	Short: "Fail unless the running commet satisfies a version constraint",
+	Long: `Checks the running commet version against a constraint such as ">=1.5.0 <2".
+Without an argument the required_version from the config file is used, so CI
+fails fast when its image ships an older commet than the config expects.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: requireVersion,
+}
+
+func init() {
+	rootCmd.AddCommand(envCmd)
+	envCmd.AddCommand(envRequireCmd)
+}
+
+// runningVersion returns the version of this binary, or "dev" when unknown.
+func runningVersion() string {
+	if commetVersion != "" {
+		return strings.TrimPrefix(commetVersion, "v")
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return strings.TrimPrefix(info.Main.Version, "v")
+	}
+
+	return "dev"
+}
+
+func requireVersion(cmd *cobra.Command, args []string) error {
+	constraint := ""
+	if len(args) == 1 {
+		constraint = args[0]
+	} else {
+		cfg, err := config.Load(cfgFile)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		constraint = cfg.RequiredVersion
+	}
+
+	if constraint == "" {
+		return fmt.Errorf("no constraint given and required_version is not set")
+	}
+
+	constraints, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return fmt.Errorf("invalid constraint %q: %w", constraint, err)
+	}
+
+	current := runningVersion()
+	if current == "dev" {
+		color.Yellow("[WARN] Development build, cannot check against %s", constraint)
+		return nil
+	}
+
+	ver, err := semver.NewVersion(current)
+	if err != nil {
+		return fmt.Errorf("failed to parse commet version %s: %w", current, err)
+	}
+
+	if ok, reasons := constraints.Validate(ver); !ok {
+		for _, reason := range reasons {
+			color.Yellow("  %s", reason)
+		}
+		return fmt.Errorf("commet %s does not satisfy %s, upgrade commet in this environment", current, constraint)
+	}
+
+	color.Green("✓ commet %s satisfies %s", current, constraint)
+	return nil
+}
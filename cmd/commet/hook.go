@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yendefrr/commet/internal/config"
+	"github.com/yendefrr/commet/internal/git"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// hookMarker identifies the hooks written by "commet hook install", which it
+// may overwrite.
+const hookMarker = "# Installed by commet hook install"
+
+var hookForce bool
+
+var hookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: "Manage the git hooks of commet",
+}
+
+var hookInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install a commit-msg hook that lints commit messages",
+	Long: `Installs a commit-msg git hook that runs "commet lint" on the message being
+written, so commits breaking the commit conventions are rejected before they
+are made. The hook goes to core.hooksPath when set, .git/hooks otherwise.
+
+A commit-msg hook not installed by commet is only replaced with --force.
+"git commit --no-verify" skips the hook.`,
+	Args: cobra.NoArgs,
+	RunE: installHook,
+}
+
+func init() {
+	rootCmd.AddCommand(hookCmd)
+	hookCmd.AddCommand(hookInstallCmd)
+
+	hookInstallCmd.Flags().BoolVar(&hookForce, "force", false, "replace a commit-msg hook not installed by commet")
+}
+
+func installHook(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if !git.IsGitRepository(".") {
+		return fmt.Errorf("not a git repository")
+	}
+
+	gitClient, err := git.NewClient(".", cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize git: %w", err)
+	}
+
+	hooksDir, err := gitClient.HooksDir()
+	if err != nil {
+		return err
+	}
+	hookFile := filepath.Join(hooksDir, "commit-msg")
+
+	if existing, err := os.ReadFile(hookFile); err == nil && !strings.Contains(string(existing), hookMarker) && !hookForce {
+		return fmt.Errorf("%s exists and was not installed by commet: use --force to replace it", hookFile)
+	}
+
+	if dryRun {
+		color.Yellow("[DRY RUN] Would write %s:", hookFile)
+		fmt.Print(commitMsgHook(cfgFile))
+		return nil
+	}
+
+	if err := os.MkdirAll(hooksDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", hooksDir, err)
+	}
+	if err := os.WriteFile(hookFile, []byte(commitMsgHook(cfgFile)), 0o755); err != nil {
+		return fmt.Errorf("failed to write %s: %w", hookFile, err)
+	}
+	// WriteFile only applies the mode on creation
+	if err := os.Chmod(hookFile, 0o755); err != nil {
+		return fmt.Errorf("failed to make %s executable: %w", hookFile, err)
+	}
+
+	color.Green("✓ Installed commit-msg hook: %s", hookFile)
+	return nil
+}
+
+// commitMsgHook is the commit-msg hook script, linting the message file git
+// passes it with the config file cfgFile, if set. Without commet on the PATH
+// the hook lets the commit through.
+func commitMsgHook(cfgFile string) string {
+	command := "commet lint"
+	if cfgFile != "" {
+		command += " --config '" + strings.ReplaceAll(cfgFile, "'", `'\''`) + "'"
+	}
+
+	return `#!/bin/sh
+` + hookMarker + `: rejects commit messages that break the
+# commit conventions. Skip it with "git commit --no-verify".
+if ! command -v commet >/dev/null 2>&1; then
+	echo "commet not found, commit message not linted" >&2
+	exit 0
+fi
+exec ` + command + ` --message - < "$1"
+`
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yendefrr/commet/internal/config"
+	"github.com/yendefrr/commet/internal/git"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var lintMessage string
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check commit messages against the configured conventions",
+	Long: `Checks the commits since the latest tag (or --from/--to) against the commit
+patterns, the allowed types and scopes and the subject length of [lint], and
+fails if any of them breaks a rule. Intended as a CI gate.
+
+With --message only that message is checked; "--message -" reads it from
+stdin, skipping the "#" comment lines git adds to commit messages.`,
+	Args: cobra.NoArgs,
+	// A failing lint is not a usage error; keep commit-msg hook output short
+	SilenceUsage: true,
+	RunE:         lint,
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+
+	lintCmd.Flags().StringVarP(&lintMessage, "message", "m", "", `check this commit message instead of a commit range, "-" for stdin`)
+}
+
+func lint(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if cmd.Flags().Changed("message") {
+		message := lintMessage
+		if message == "-" {
+			input, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return fmt.Errorf("failed to read the message: %w", err)
+			}
+			message = stripComments(string(input))
+		}
+
+		subject, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+		if problems := lintCommit(cfg, message); len(problems) > 0 {
+			color.Red("✗ %s", subject)
+			for _, problem := range problems {
+				color.Red("    %s", problem)
+			}
+			return fmt.Errorf("the commit message breaks the commit conventions")
+		}
+		color.Green("✓ %s", subject)
+		return nil
+	}
+
+	if !git.IsGitRepository(".") {
+		return fmt.Errorf("not a git repository")
+	}
+
+	gitClient, err := git.NewClient(".", cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize git: %w", err)
+	}
+
+	commits, err := gitClient.GetCommits(fromRef, toRef)
+	if err != nil {
+		return fmt.Errorf("failed to get commits: %w", err)
+	}
+	if len(commits) == 0 {
+		color.Yellow("No commits to lint")
+		return nil
+	}
+
+	failed := 0
+	for _, c := range commits {
+		problems := lintCommit(cfg, c.FullMessage())
+		if len(problems) == 0 {
+			color.Green("✓ %s %s", c.Hash, c.Message)
+			continue
+		}
+
+		failed++
+		color.Red("✗ %s %s", c.Hash, c.Message)
+		for _, problem := range problems {
+			color.Red("    %s", problem)
+		}
+	}
+
+	fmt.Println()
+	if failed > 0 {
+		return fmt.Errorf("%d of %d commit messages break the commit conventions", failed, len(commits))
+	}
+
+	color.Green("All %d commit messages follow the commit conventions", len(commits))
+	return nil
+}
+
+// lintCommit returns the rules of cfg that message breaks, none when it
+// follows them. Reverts made by git and the commits commet writes itself
+// always pass the type and scope rules.
+func lintCommit(cfg *config.Config, message string) []string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	if subject == "" {
+		return []string{"empty message"}
+	}
+
+	var problems []string
+	if length := utf8.RuneCountInString(subject); length > cfg.Lint.MaxSubjectLength {
+		problems = append(problems, fmt.Sprintf("subject is %d characters long, more than %d", length, cfg.Lint.MaxSubjectLength))
+	}
+
+	commit, err := cfg.CommitParser().Parse(message)
+	if err != nil || !commit.IsValidCommit() {
+		return append(problems, `subject matches no commit pattern, e.g. "Fix(scope): description"`)
+	}
+	if commit.Reverts != "" || ownCommitType(cfg, commit.Type) {
+		return problems
+	}
+
+	if !allowedType(cfg, commit.Type) {
+		problems = append(problems, fmt.Sprintf("type %q is not allowed", commit.Type))
+	}
+
+	if len(cfg.Lint.Scopes) > 0 {
+		for _, scope := range strings.Split(commit.Scope, ",") {
+			if scope = strings.TrimSpace(scope); scope != "" && !containsString(cfg.Lint.Scopes, scope) {
+				problems = append(problems, fmt.Sprintf("scope %q is not one of %s", scope, strings.Join(cfg.Lint.Scopes, ", ")))
+			}
+		}
+	}
+
+	if strings.TrimSpace(commit.Description) == "" {
+		problems = append(problems, "description is empty")
+	}
+
+	if bumps := commit.Footer("Version-Bump"); len(bumps) > 0 && commit.Bump == "" {
+		problems = append(problems, fmt.Sprintf("Version-Bump %q is not one of none, build, patch, minor and major", bumps[len(bumps)-1]))
+	}
+
+	return problems
+}
+
+// allowedType reports whether commitType is one of lint.types or, without
+// them, has a bump rule.
+func allowedType(cfg *config.Config, commitType string) bool {
+	if len(cfg.Lint.Types) == 0 {
+		return cfg.HasBumpRule(commitType)
+	}
+	for _, allowed := range cfg.Lint.Types {
+		if allowed == commitType || cfg.CaseInsensitiveTypes && strings.EqualFold(allowed, commitType) {
+			return true
+		}
+	}
+	return false
+}
+
+// ownCommitType reports whether commitType is the type of a commit message
+// commet writes, e.g. Conf for "Conf: bump version to {version}".
+func ownCommitType(cfg *config.Config, commitType string) bool {
+	for _, message := range []string{cfg.Git.CommitMessage, cfg.Git.PostReleaseCommitMessage, cfg.Snapshot.CommitMessage} {
+		if message == "" {
+			continue
+		}
+		if own, err := cfg.CommitParser().Parse(message); err == nil && own.Type == commitType {
+			return true
+		}
+	}
+	return false
+}
+
+// stripComments drops the "#" comment lines of a commit message and
+// everything below the scissors line of "git commit --verbose".
+func stripComments(message string) string {
+	var lines []string
+	for _, line := range strings.Split(message, "\n") {
+		if strings.HasPrefix(line, "# ------------------------ >8 ------------------------") {
+			break
+		}
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yendefrr/commet/internal/config"
+)
+
+func TestLintCommit(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Lint.Scopes = []string{"api", "cli"}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{"Feature(api): add export", ""},
+		{"Fix(api,cli): handle nil", ""},
+		{"U-1234: Fix crash on start", ""},
+		{"Conf: bump version to 1.2.0", ""},
+		{"Revert \"Feature(web): add export\"\n\nThis reverts commit abc1234.", ""},
+		{"", "empty message"},
+		{"wip", "matches no commit pattern"},
+		{"Chore: tidy up", `type "Chore" is not allowed`},
+		{"Fix(web): handle nil", `scope "web" is not one of api, cli`},
+		{"Fix: " + strings.Repeat("a", 80), "more than 72"},
+		{"Fix: handle nil\n\nVersion-Bump: minor", ""},
+		{"Fix: handle nil\n\nVersion-Bump: huge", `Version-Bump "huge" is not one of`},
+	}
+
+	for _, tt := range tests {
+		problems := strings.Join(lintCommit(cfg, tt.message), "; ")
+		if tt.want == "" && problems != "" {
+			t.Errorf("lintCommit(%q) = %q, want no problems", tt.message, problems)
+		}
+		if tt.want != "" && !strings.Contains(problems, tt.want) {
+			t.Errorf("lintCommit(%q) = %q, want %q", tt.message, problems, tt.want)
+		}
+	}
+}
+
+func TestStripComments(t *testing.T) {
+	message := "Fix: handle nil\n\n# Please enter the commit message\n# ------------------------ >8 ------------------------\ndiff --git a/x b/x\n"
+	if got, want := strings.TrimSpace(stripComments(message)), "Fix: handle nil"; got != want {
+		t.Errorf("stripComments() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/yendefrr/commet/internal/config"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var lockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Pin the remote config sources of extends in .commet.lock",
+	Long: `Downloads the HTTPS sources listed in extends and records their SHA-256 in
+.commet.lock next to the config file. Releases refuse to run when a remote
+source is not pinned or its content changed, so shared presets cannot change
+release behavior without a reviewed lock update.`,
+	Args: cobra.NoArgs,
+	RunE: lockSources,
+}
+
+func init() {
+	rootCmd.AddCommand(lockCmd)
+}
+
+func lockSources(cmd *cobra.Command, args []string) error {
+	configPath := cfgFile
+	if configPath == "" {
+		configPath = ".commet.toml"
+	}
+
+	lock, err := config.ResolveLock(configPath)
+	if err != nil {
+		return err
+	}
+	if len(lock.Sources) == 0 {
+		color.Yellow("[WARN] %s extends no remote sources, nothing to lock", configPath)
+		return nil
+	}
+
+	lockPath := config.LockPath(configPath)
+	if dryRun {
+		color.Yellow("[DRY RUN] Would write %s:", lockPath)
+		for _, source := range lock.Sources {
+			fmt.Printf("  %s  %s\n", source.SHA256, source.URL)
+		}
+		return nil
+	}
+
+	if err := lock.Save(lockPath); err != nil {
+		return err
+	}
+	for _, source := range lock.Sources {
+		color.Green("✓ Locked %s (sha256 %s)", source.URL, source.SHA256[:12])
+	}
+	return nil
+}
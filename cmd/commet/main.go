@@ -1,14 +1,24 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/yendefrr/commet/internal/changelog"
+	"github.com/yendefrr/commet/internal/checklist"
+	"github.com/yendefrr/commet/internal/clipboard"
 	"github.com/yendefrr/commet/internal/config"
+	"github.com/yendefrr/commet/internal/diff"
 	"github.com/yendefrr/commet/internal/git"
+	"github.com/yendefrr/commet/internal/github"
+	"github.com/yendefrr/commet/internal/notify"
 	"github.com/yendefrr/commet/internal/parser"
+	"github.com/yendefrr/commet/internal/policy"
 	"github.com/yendefrr/commet/internal/updater"
 	"github.com/yendefrr/commet/internal/version"
 
@@ -23,8 +33,22 @@ var (
 	fromRef string
 	toRef   string
 
+	noRollback bool
+	stampFile  string
+	prerelease string
+	forceBump  string
+	allowMajor bool
+	allowMax   bool
+	skipChecks []string
+
+	interactive bool
+
 	createTag      bool
 	commitMessage  string
+
+	changelogStdout bool
+	changelogCopy   bool
+	changelogBoards []string
 )
 
 var rootCmd = &cobra.Command{
@@ -67,11 +91,24 @@ func init() {
 	commitCmd.Flags().BoolVar(&createTag, "tag", false, "also create a git tag for the version")
 	commitCmd.Flags().StringVarP(&commitMessage, "message", "m", "", "custom commit message (overrides config)")
 
+	changelogCmd.Flags().BoolVar(&changelogStdout, "stdout", false, "print the entry to stdout instead of writing the changelog file")
+	changelogCmd.Flags().BoolVar(&changelogCopy, "copy", false, "copy the entry to the system clipboard")
+	changelogCmd.Flags().StringSliceVar(&changelogBoards, "board", nil, "only include commits referencing these board IDs")
+
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is .commet.toml)")
 	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "show what would be done without making changes")
 	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "verbose output")
 	rootCmd.PersistentFlags().StringVar(&fromRef, "from", "", "start ref for commit range")
 	rootCmd.PersistentFlags().StringVar(&toRef, "to", "HEAD", "end ref for commit range")
+
+	rootCmd.Flags().BoolVar(&noRollback, "no-rollback", false, "keep partially updated files when a later update fails")
+	rootCmd.Flags().StringVar(&forceBump, "bump", "", "skip commit analysis and force a major, minor or patch bump")
+	rootCmd.Flags().BoolVar(&allowMajor, "allow-major", false, "confirm a major release when policy.require_confirmation_for_major is set")
+	rootCmd.Flags().BoolVar(&allowMax, "allow-max", false, "release a version beyond version.max, e.g. leave the 1.x line deliberately")
+	rootCmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "with --dry-run: reclassify or exclude commits for the next release")
+	rootCmd.Flags().StringSliceVar(&skipChecks, "skip-checks", nil, "release even though these [checklist] items fail; they are not run")
+	rootCmd.Flags().StringVar(&prerelease, "prerelease", "", "release as a pre-release with this identifier (alpha, beta, rc)")
+	rootCmd.Flags().StringVar(&stampFile, "stamp-file", "", "write Bazel workspace status lines (STABLE_VERSION ...) to this file, - for stdout (other output goes to stderr)")
 }
 
 func initConfig(cmd *cobra.Command, args []string) error {
@@ -121,11 +158,12 @@ func commitVersion(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to initialize git: %w", err)
 	}
+	expandRepoVars(cfg, gitClient)
 
 	currentVersion := ""
 	for _, versionFile := range cfg.GetVersionFiles() {
 		if fileExists(versionFile.File) {
-			fileUpdater, err := updater.New(versionFile.File)
+			fileUpdater, err := newFileUpdater(versionFile)
 			if err == nil {
 				version, err := fileUpdater.GetVersion(versionFile.Key)
 				if err == nil && version != "" {
@@ -155,6 +193,11 @@ func commitVersion(cmd *cobra.Command, args []string) error {
 		if fileExists(versionFile.File) {
 			filesToCommit = append(filesToCommit, versionFile.File)
 		}
+		if versionFile.SyncLockfile {
+			if lockFile, err := updater.CargoLockFile(versionFile.File); err == nil {
+				filesToCommit = append(filesToCommit, lockFile)
+			}
+		}
 	}
 
 	if len(filesToCommit) == 0 {
@@ -177,7 +220,10 @@ func commitVersion(cmd *cobra.Command, args []string) error {
 		if tagFormat == "" {
 			tagFormat = "v{version}"
 		}
-		tagName := strings.ReplaceAll(tagFormat, "{version}", currentVersion)
+		tagName, err := version.Expand(tagFormat, currentVersion)
+		if err != nil {
+			return fmt.Errorf("failed to format tag: %w", err)
+		}
 
 		tagMsg := cfg.Git.TagMessage
 		if tagMsg == "" {
@@ -190,6 +236,12 @@ func commitVersion(cmd *cobra.Command, args []string) error {
 		}
 
 		color.Green("✓ Created tag: %s", tagName)
+
+		if cfg.Git.AliasTags {
+			if err := moveAliasTags(gitClient, tagFormat, currentVersion); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
@@ -209,6 +261,8 @@ func generateChangelog(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to initialize git: %w", err)
 	}
+	expandRepoVars(cfg, gitClient)
+	dateChangelog(cfg, gitClient)
 
 	currentVersion, err := detectVersion(gitClient, cfg)
 	if err != nil {
@@ -235,7 +289,7 @@ func generateChangelog(cmd *cobra.Command, args []string) error {
 
 	parsedCommits := make([]*parser.Commit, 0, len(commits))
 	for _, c := range commits {
-		parsed, err := parser.Parse(c.Message)
+		parsed, err := cfg.CommitParser().Parse(c.FullMessage())
 		if err != nil {
 			if verbose {
 				color.Yellow("[WARN] Failed to parse: %s", c.Message)
@@ -243,28 +297,69 @@ func generateChangelog(cmd *cobra.Command, args []string) error {
 			continue
 		}
 
+		if parsed.Excluded {
+			if verbose {
+				color.Yellow("[SKIP] Excluded from the changelog: %s", c.Message)
+			}
+			continue
+		}
+
 		parsed.Hash = c.Hash
+		parsed.FullHash = c.FullHash
+		parsed.Author = c.Author
 		parsedCommits = append(parsedCommits, parsed)
 	}
 
+	parsedCommits = changelog.FilterBoards(parsedCommits, changelogBoards)
+
 	if len(parsedCommits) == 0 {
 		color.Yellow("No valid commits found")
 		return nil
 	}
 
-	changelogFile := cfg.Changelog.File
-	if changelogFile == "" {
-		changelogFile = "CHANGELOG.md"
+	notes, err := changelog.ReadReleaseNotes(cfg.Changelog.ReleaseNotesFile)
+	if err != nil {
+		return err
+	}
+	if notes == "" {
+		if err := changelog.Summarize(&cfg.Changelog, currentVersion, parsedCommits); err != nil {
+			return err
+		}
+	}
+
+	if changelogStdout || changelogCopy {
+		entry, err := changelogEntry(cfg.Changelog.Targets()[0], currentVersion, notes, parsedCommits)
+		if err != nil {
+			return fmt.Errorf("failed to render changelog: %w", err)
+		}
+
+		if changelogStdout {
+			fmt.Print(entry)
+		}
+
+		if changelogCopy {
+			if err := clipboard.Copy(entry); err != nil {
+				return fmt.Errorf("failed to copy changelog: %w", err)
+			}
+			if !changelogStdout {
+				color.Green("✓ Copied changelog entry to clipboard")
+			}
+		}
+
+		if changelogStdout {
+			return nil
+		}
 	}
 
-	generator := changelog.NewGenerator(changelogFile)
-	if err := generator.Generate(currentVersion, parsedCommits); err != nil {
-		return fmt.Errorf("failed to generate changelog: %w", err)
+	backup := updater.NewBackup()
+	if _, err := writeChangelogs(cfg, backup, currentVersion, parsedCommits); err != nil {
+		return rollback(backup, err)
 	}
 
-	color.Green("✓ Changelog updated: %s", changelogFile)
-	fmt.Println()
-	color.Cyan("Added %d commits grouped by type", len(parsedCommits))
+	if notes == "" {
+		fmt.Println()
+		color.Cyan("Added %d commits grouped by type", len(parsedCommits))
+	}
 
 	return nil
 }
@@ -276,6 +371,19 @@ func run(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
+	if prerelease != "" {
+		cfg.Version.Prerelease = prerelease
+		if err := cfg.Validate(); err != nil {
+			return fmt.Errorf("invalid --prerelease: %w", err)
+		}
+	}
+
+	switch cfg.ResolveBump(config.BumpType(forceBump)) {
+	case "", config.BumpMajor, config.BumpMinor, config.BumpPatch:
+	default:
+		return fmt.Errorf("invalid --bump %q: must be 'major', 'minor', 'patch' or a bump_levels level for one of them", forceBump)
+	}
+
 	if verbose {
 		color.Cyan("[CONFIG] Loaded configuration")
 		if cfgFile != "" {
@@ -293,157 +401,706 @@ func run(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to initialize git: %w", err)
 	}
+	expandRepoVars(cfg, gitClient)
+	dateChangelog(cfg, gitClient)
 
-	// Detect current version
-	currentVersion, err := detectVersion(gitClient, cfg)
+	stamp, restore := stampOutputs()
+	defer restore()
+
+	r := &release{cfg: cfg, gitClient: gitClient, stamp: stamp}
+	if err := newPipeline(cfg.Pipeline).Run(r); err != nil {
+		return err
+	}
+	if dryRun || r.done {
+		return nil
+	}
+
+	if err := clearSession(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(color.Output)
+	color.Green("Version updated: %s → %s", r.currentVersion, r.newVersion)
+
+	return nil
+}
+
+// commitAndTag commits the updated files, tags the release and pushes both
+// as configured by git.auto_commit, git.auto_tag and git.auto_push.
+func commitAndTag(cfg *config.Config, gitClient *git.Client, updatedFiles []string, ver string) error {
+	if err := commitRelease(cfg, gitClient, updatedFiles, ver); err != nil {
+		return err
+	}
+	if err := tagRelease(cfg, gitClient, ver); err != nil {
+		return err
+	}
+	return pushRelease(cfg, gitClient, ver)
+}
+
+// commitRelease commits the updated files when git.auto_commit is set.
+func commitRelease(cfg *config.Config, gitClient *git.Client, updatedFiles []string, ver string) error {
+	if cfg.Git.AutoCommit && len(updatedFiles) > 0 {
+		commitMsg := strings.ReplaceAll(cfg.Git.CommitMessage, "{version}", ver)
+		if err := gitClient.CreateCommit(updatedFiles, commitMsg); err != nil {
+			return fmt.Errorf("failed to create commit: %w", err)
+		}
+		color.Green("✓ Created commit: %s", commitMsg)
+	}
+
+	return nil
+}
+
+// tagRelease tags the release and moves the alias tags when git.auto_tag is set.
+func tagRelease(cfg *config.Config, gitClient *git.Client, ver string) error {
+	if cfg.Git.AutoTag {
+		tagName, err := releaseTag(cfg, ver)
+		if err != nil {
+			return err
+		}
+		tagMsg := strings.ReplaceAll(cfg.Git.TagMessage, "{version}", ver)
+		if err := gitClient.CreateTag(tagName, tagMsg); err != nil {
+			return fmt.Errorf("failed to create tag: %w", err)
+		}
+		color.Green("✓ Created tag: %s", tagName)
+
+		if cfg.Git.AliasTags {
+			if err := moveAliasTags(gitClient, cfg.Git.TagFormat, ver); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// pushRelease pushes what commitRelease and tagRelease made to
+// git.push_remote when git.auto_push is set: the commits to git.push_branch
+// and the release tag, forcing the alias tags it moved.
+func pushRelease(cfg *config.Config, gitClient *git.Client, ver string) error {
+	if !cfg.Git.AutoPush {
+		return nil
+	}
+	remote := cfg.Git.PushRemote
+
+	if cfg.Git.AutoCommit {
+		branch := cfg.Git.PushBranch
+		if branch == "" {
+			var err error
+			if branch, err = gitClient.CurrentBranch(); err != nil {
+				return err
+			}
+		}
+		if err := pushReleaseBranch(gitClient, remote, branch); err != nil {
+			return err
+		}
+		color.Green("✓ Pushed %s to %s", branch, remote)
+	}
+
+	if cfg.Git.AutoTag {
+		return pushReleaseTags(cfg, gitClient, remote, ver)
+	}
+
+	return nil
+}
+
+// pushAttempts is how often pushReleaseBranch replays the release on a
+// remote branch that keeps moving ahead before giving up.
+const pushAttempts = 3
+
+// pushReleaseBranch pushes HEAD to branch of remote. When the remote moved
+// ahead, the release commits are replayed on top of it if they apply
+// cleanly, moving the release tags along, and pushed again with a lease.
+// Otherwise it stops before any tag is pushed.
+func pushReleaseBranch(gitClient *git.Client, remote, branch string) error {
+	replayed, err := gitClient.PushHeadReplaying(remote, branch, pushAttempts)
+	if errors.Is(err, git.ErrRebaseConflict) {
+		return fmt.Errorf("%w: the release commit and tags exist only locally; rebase them onto %s/%s by hand, move the tags and push with \"git push --follow-tags\"", err, remote, branch)
+	}
+	if errors.Is(err, git.ErrRemoteAhead) {
+		return fmt.Errorf("%w: the release commit and tags exist only locally; pull, move the tags and push with \"git push --follow-tags\"", err)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to detect current version: %w", err)
+		return err
 	}
 
-	if verbose {
-		color.Cyan("[VERSION] Current: %s", currentVersion)
+	if replayed > 0 {
+		color.Green("✓ Replayed the release on %s/%s (%d commits)", remote, branch, replayed)
+		color.Yellow("[WARN] The commits pushed meanwhile are part of the release tag but not of its changelog")
 	}
+	return nil
+}
 
-	// Get commits
-	commits, err := gitClient.GetCommits(fromRef, toRef)
+// pushReleaseTags pushes the release tag of ver to remote, forcing the alias
+// tags moved with it.
+func pushReleaseTags(cfg *config.Config, gitClient *git.Client, remote, ver string) error {
+	tagName, err := releaseTag(cfg, ver)
 	if err != nil {
-		return fmt.Errorf("failed to get commits: %w", err)
+		return err
+	}
+	if err := gitClient.Push(remote, false, "refs/tags/"+tagName); err != nil {
+		return err
 	}
+	color.Green("✓ Pushed tag: %s", tagName)
 
-	if len(commits) == 0 {
-		color.Yellow("No commits found since %s", currentVersion)
+	if !cfg.Git.AliasTags {
 		return nil
 	}
 
-	if verbose {
-		color.Cyan("[GIT] Found %d commits", len(commits))
+	var refs []string
+	for _, alias := range version.Aliases(ver) {
+		aliasTag, err := version.Expand(cfg.Git.TagFormat, alias)
+		if err != nil {
+			return fmt.Errorf("failed to format tag: %w", err)
+		}
+		refs = append(refs, "refs/tags/"+aliasTag)
 	}
+	if len(refs) == 0 {
+		return nil
+	}
+	return gitClient.Push(remote, true, refs...)
+}
 
-	// Parse commits
-	parsedCommits := make([]*parser.Commit, 0, len(commits))
-	for _, c := range commits {
-		parsed, err := parser.Parse(c.Message)
+// moveAliasTags points the floating major and minor tags of ver (v1, v1.4)
+// at the release commit.
+func moveAliasTags(gitClient *git.Client, tagFormat, ver string) error {
+	for _, alias := range version.Aliases(ver) {
+		tagName, err := version.Expand(tagFormat, alias)
 		if err != nil {
-			if verbose {
-				color.Yellow("[WARN] Failed to parse: %s", c.Message)
-			}
-			continue
+			return fmt.Errorf("failed to format tag: %w", err)
 		}
 
-		if !parsed.IsValidCommit() {
-			if verbose {
-				color.Yellow("[WARN] Invalid commit format: %s", c.Message)
+		moved, err := gitClient.MoveTag(tagName)
+		if err != nil {
+			return err
+		}
+		if moved {
+			color.Green("✓ Moved tag: %s", tagName)
+		} else {
+			color.Green("✓ Created tag: %s", tagName)
+		}
+	}
+
+	return nil
+}
+
+// expandRepoVars fills {owner}, {repo}, {default_branch} and {branch} in the
+// configured templates. Owner and name come from the origin remote; values
+// that cannot be determined are left as placeholders.
+func expandRepoVars(cfg *config.Config, gitClient *git.Client) {
+	vars := make(map[string]string)
+
+	if remote, err := gitClient.RemoteURL("origin"); err == nil {
+		if owner, repo, err := github.ParseRepoURL(remote); err == nil {
+			vars["owner"], vars["repo"] = owner, repo
+		}
+	}
+	if branch, err := gitClient.DefaultBranch(); err == nil {
+		vars["default_branch"] = branch
+	}
+	if branch, err := gitClient.CurrentBranch(); err == nil {
+		vars["branch"] = branch
+	}
+
+	if verbose {
+		for _, name := range []string{"owner", "repo", "default_branch", "branch"} {
+			if value, ok := vars[name]; ok {
+				color.Cyan("[TEMPLATE] {%s} = %s", name, value)
 			}
+		}
+	}
+
+	cfg.ExpandVars(vars)
+}
+
+// dateChangelog dates changelog entries with the tag or commit being
+// released, --to, when changelog.date is "commit". A date in the future, from
+// a skewed clock, is replaced by the current time.
+func dateChangelog(cfg *config.Config, gitClient *git.Client) {
+	if cfg.Changelog.Date != "commit" {
+		return
+	}
+
+	released, err := gitClient.ReleaseDate(toRef)
+	if err != nil {
+		color.Yellow("[WARN] Dating the changelog entry today: %v", err)
+		return
+	}
+
+	if now := time.Now(); released.After(now) {
+		color.Yellow("[WARN] %s is dated %s, in the future; dating the changelog entry today", toRef, released.Format("2006-01-02"))
+		released = now
+	}
+
+	if verbose {
+		color.Cyan("[CHANGELOG] Dating entries %s from %s", released.Format("2006-01-02"), toRef)
+	}
+	cfg.Changelog.ReleaseDate = released
+}
+
+// changelogVersion is ver as changelog headers show it, formatted by the
+// version scheme, e.g. v1.2.3 with version.format "v-prefix".
+func changelogVersion(cfg *config.Config, ver string) string {
+	scheme := version.SchemeFor(cfg)
+	canonical, err := scheme.Parse(ver)
+	if err != nil {
+		return ver
+	}
+	return scheme.Format(canonical)
+}
+
+// releaseTag is the tag name of ver, from git.tag_format.
+func releaseTag(cfg *config.Config, ver string) (string, error) {
+	tag, err := version.Expand(cfg.Git.TagFormat, ver)
+	if err != nil {
+		return "", fmt.Errorf("failed to format tag: %w", err)
+	}
+	return tag, nil
+}
+
+// notifyRelease tells every configured channel about the release. The release
+// is already done at this point, so failures are reported as warnings.
+func notifyRelease(cfg *config.Config, ver string, commits []*parser.Commit) {
+	if len(cfg.Notifications) == 0 {
+		return
+	}
+
+	project := "."
+	if wd, err := os.Getwd(); err == nil {
+		project = filepath.Base(wd)
+	}
+
+	tag, err := releaseTag(cfg, ver)
+	if err != nil {
+		color.Yellow("[WARN] %v", err)
+	}
+
+	release := &notify.Release{
+		Project: project,
+		Version: ver,
+		Tag:     tag,
+		Commits: commits,
+	}
+
+	// One summary for every channel
+	if err := changelog.Summarize(&cfg.Changelog, ver, commits); err != nil {
+		color.Yellow("[WARN] Failed to notify: %v", err)
+		return
+	}
+
+	notifier := notify.NewNotifier(cfg.Changelog)
+	for _, channel := range cfg.Notifications {
+		if err := notifier.Send(channel, release); err != nil {
+			color.Yellow("[WARN] Failed to notify %s: %v", channel.Channel, err)
 			continue
 		}
+		color.Green("✓ Notified %s", channel.Channel)
+	}
+}
 
-		parsed.Hash = c.Hash
-		parsedCommits = append(parsedCommits, parsed)
+// nextDevelopmentVersion returns the version to open after released, with the
+// commit message to use for it, or an empty string when neither snapshots nor
+// git.post_release_version are configured.
+func nextDevelopmentVersion(cfg *config.Config, calculator *version.Calculator, released string) (string, string, error) {
+	switch {
+	case cfg.Snapshot.Enabled:
+		devVersion, err := calculator.NextSnapshot(released)
+		return devVersion, cfg.Snapshot.CommitMessage, err
+	case cfg.Git.PostReleaseVersion != "":
+		devVersion, err := calculator.PostReleaseVersion(released, cfg.Git.PostReleaseVersion)
+		return devVersion, cfg.Git.PostReleaseCommitMessage, err
+	}
 
-		if verbose {
-			bump := cfg.GetBumpType(parsed.Type)
-			forceMark := ""
-			if parsed.ForceMajor {
-				forceMark = " [FORCE MAJOR]"
-			}
-			fmt.Printf("  %s → %s%s\n", truncate(c.Message, 60), bump, forceMark)
+	return "", "", nil
+}
+
+// openDevelopmentCycle writes the next development version after a release,
+// committing it separately when auto_commit is enabled so the release commit stays clean.
+func openDevelopmentCycle(cfg *config.Config, gitClient *git.Client, versionFiles []config.VersionConfig, devVersion, commitMessage string) error {
+	backup := updater.NewBackup()
+	updatedFiles, err := updateVersionFiles(versionFiles, backup, devVersion)
+	if err != nil {
+		return rollback(backup, fmt.Errorf("failed to write development version: %w", err))
+	}
+
+	if cfg.Git.AutoCommit && len(updatedFiles) > 0 {
+		commitMsg := strings.ReplaceAll(commitMessage, "{version}", devVersion)
+		if err := gitClient.CreateCommit(updatedFiles, commitMsg); err != nil {
+			return fmt.Errorf("failed to create commit: %w", err)
 		}
+		color.Green("✓ Created commit: %s", commitMsg)
 	}
 
-	if len(parsedCommits) == 0 {
-		color.Yellow("No valid commits found")
+	color.Green("Next development version: %s", devVersion)
+
+	return nil
+}
+
+// applyBranchChannel releases on the channel of the current branch: the
+// pre-release identifier of its [[branches]] entry, or a stable version.
+// --prerelease overrides the channel. On maintenance branches the version
+// line, e.g. "1.2.x", is returned and tag detection is limited to it.
+func applyBranchChannel(cfg *config.Config, gitClient *git.Client) (string, error) {
+	branch, err := gitClient.CurrentBranch()
+	if err != nil {
+		if prerelease != "" {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to determine branch for release channel: %w", err)
+	}
+
+	channel, ok := cfg.Channel(branch)
+	if !ok {
+		if prerelease != "" {
+			return "", nil
+		}
+		return "", fmt.Errorf("branch %s is not a release branch: add it to [[branches]] or pass --prerelease", branch)
+	}
+
+	if prerelease == "" {
+		cfg.Version.Prerelease = channel.Prerelease
+	}
+
+	if verbose {
+		if cfg.Version.Prerelease == "" {
+			color.Cyan("[BRANCH] %s releases stable versions", branch)
+		} else {
+			color.Cyan("[BRANCH] %s releases %s pre-releases", branch, cfg.Version.Prerelease)
+		}
+	}
+
+	if !channel.Maintenance {
+		return "", nil
+	}
+
+	line := path.Base(branch)
+	if !version.IsLine(line) {
+		return "", fmt.Errorf("maintenance branch %s does not end in a version line such as 1.2.x", branch)
+	}
+
+	if err := gitClient.RestrictToLine(toRef, line); err != nil {
+		return "", err
+	}
+
+	if verbose {
+		color.Cyan("[BRANCH] %s is a maintenance branch for %s", branch, line)
+	}
+
+	return line, nil
+}
+
+// enforceBoardPolicy fails when the current branch requires board IDs and a
+// releasable commit lacks one or references an unknown board.
+func enforceBoardPolicy(cfg *config.Config, gitClient *git.Client, calculator *version.Calculator, commits []*parser.Commit) error {
+	if len(cfg.Policy.RequireBoardBranches) == 0 {
 		return nil
 	}
 
-	// Calculate new version
-	calculator := version.NewCalculator(cfg)
-	newVersion, bumpType, err := calculator.Calculate(currentVersion, parsedCommits)
+	checker := policy.NewChecker(cfg.Policy)
+
+	branch, err := gitClient.CurrentBranch()
 	if err != nil {
-		return fmt.Errorf("failed to calculate version: %w", err)
+		return fmt.Errorf("failed to determine branch for board policy: %w", err)
 	}
 
-	if bumpType == config.BumpNone {
-		color.Green("No version bump needed (current: %s)", currentVersion)
+	if !checker.Applies(branch) {
 		return nil
 	}
 
-	// Display results
-	fmt.Println()
-	color.Green("Current version: %s", currentVersion)
-	color.Green("Next version:    %s", newVersion)
-	color.Green("Bump type:       %s", strings.ToUpper(string(bumpType)))
-	fmt.Println()
+	var releasable []*parser.Commit
+	for _, commit := range commits {
+		if calculator.DetermineBump([]*parser.Commit{commit}) != config.BumpNone {
+			releasable = append(releasable, commit)
+		}
+	}
+
+	violations := checker.CheckBoards(releasable)
+	if len(violations) == 0 {
+		if verbose {
+			color.Cyan("[POLICY] All %d releasable commits on %s reference a board", len(releasable), branch)
+		}
+		return nil
+	}
+
+	color.Red("✗ Board policy for branch %s violated:", branch)
+	for _, violation := range violations {
+		fmt.Fprintf(color.Output, "  %s %s: %s\n", violation.Commit.Hash, truncate(violation.Commit.Message, 60), violation.Reason)
+	}
+
+	return fmt.Errorf("%d commit(s) violate the board policy", len(violations))
+}
+
+// exitMajorNotConfirmed is the exit code of a major release stopped by
+// policy.require_confirmation_for_major.
+const exitMajorNotConfirmed = 3
+
+// exitError makes main exit with code instead of 1.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+// runChecklist runs the [checklist] gates in parallel, prints a summary table
+// and fails when any check that was not skipped with --skip-checks fails.
+func runChecklist(cfg *config.Config, newVersion string) error {
+	if len(cfg.Checklist) == 0 {
+		return nil
+	}
+
+	for _, name := range skipChecks {
+		if _, ok := cfg.Checklist[name]; !ok {
+			return fmt.Errorf("invalid --skip-checks: no check named %q in [checklist]", name)
+		}
+	}
 
 	if dryRun {
-		color.Yellow("Files to update:")
-		for _, versionFile := range cfg.GetVersionFiles() {
-			color.Yellow("  - %s (%s)", versionFile.File, versionFile.Key)
+		color.Yellow("[DRY RUN] Would run %d checks before releasing", len(cfg.Checklist)-len(skipChecks))
+		return nil
+	}
+
+	stop := phases.Start("checklist")
+	results := checklist.Run(cfg.Checklist, skipChecks, []string{"COMMET_VERSION=" + newVersion})
+	stop()
+
+	var failed []string
+	writer := newTable()
+	fmt.Fprintln(writer, "CHECK\tSTATUS\tTIME")
+	for _, result := range results {
+		status := "ok"
+		switch {
+		case result.Skipped:
+			status = "skipped"
+		case result.Err != nil:
+			status = "FAILED"
+			failed = append(failed, result.Name)
 		}
-		fmt.Println()
-		color.Yellow("No changes made (dry run mode)")
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", result.Name, status, result.Duration.Round(time.Millisecond))
+	}
+	writer.Flush()
+	fmt.Fprintln(color.Output)
+
+	if len(failed) == 0 {
+		color.Green("✓ All checks passed")
 		return nil
 	}
 
-	// Update version files
-	updatedFiles := []string{}
-	for _, versionFile := range cfg.GetVersionFiles() {
-		filePath := versionFile.File
-		if !fileExists(filePath) {
-			color.Yellow("[WARN] File not found: %s", filePath)
+	for _, result := range results {
+		if result.Passed() {
 			continue
 		}
+		color.Red("✗ %s failed: %v", result.Name, result.Err)
+		if output := strings.TrimSpace(result.Output); output != "" {
+			fmt.Fprintln(color.Output, output)
+		}
+		fmt.Fprintln(color.Output)
+	}
 
-		fileUpdater, err := updater.New(filePath)
-		if err != nil {
-			return fmt.Errorf("failed to create updater for %s: %w", filePath, err)
+	return fmt.Errorf("release blocked by failing checks: %s (pass --skip-checks to override)", strings.Join(failed, ", "))
+}
+
+// confirmMajor enforces policy.require_confirmation_for_major: the commits
+// that force the major are listed and the release only proceeds with
+// --allow-major or a "yes" at the prompt when stdin is a terminal outside CI.
+func confirmMajor(cfg *config.Config, calculator *version.Calculator, commits []*parser.Commit, newVersion string) error {
+	if !cfg.Policy.RequireConfirmationForMajor || allowMajor {
+		return nil
+	}
+
+	if calculator.DetermineBump(commits) != config.BumpMajor && calculator.RollupBump(commits) == config.BumpMajor {
+		color.Yellow("Major release %s requires confirmation. It rolls up %d or more minor-level commits (rollup.minor_threshold).", newVersion, cfg.Rollup.MinorThreshold)
+	} else {
+		color.Yellow("Major release %s requires confirmation. Commits forcing it:", newVersion)
+		for _, commit := range commits {
+			if calculator.DetermineBump([]*parser.Commit{commit}) == config.BumpMajor {
+				fmt.Fprintf(color.Output, "  %s %s\n", commit.Hash, truncate(commit.Message, 60))
+			}
 		}
+	}
+
+	if dryRun {
+		color.Yellow("[DRY RUN] Would need --allow-major or confirmation")
+		return nil
+	}
 
-		if err := fileUpdater.SetVersion(versionFile.Key, newVersion); err != nil {
-			return fmt.Errorf("failed to update %s: %w", filePath, err)
+	if stat, err := os.Stdin.Stat(); err == nil && stat.Mode()&os.ModeCharDevice != 0 && os.Getenv("CI") == "" {
+		fmt.Fprintf(color.Output, "Release %s? (y/N): ", newVersion)
+
+		var response string
+		fmt.Scanln(&response)
+		response = strings.ToLower(strings.TrimSpace(response))
+
+		if response == "y" || response == "yes" {
+			return nil
 		}
+	}
 
-		color.Green("✓ Updated %s", filePath)
-		updatedFiles = append(updatedFiles, filePath)
+	return &exitError{
+		code: exitMajorNotConfirmed,
+		err:  fmt.Errorf("major release %s not confirmed: rerun with --allow-major or fix the commits above", newVersion),
 	}
+}
 
-	// Generate changelog if enabled
-	if cfg.Changelog.Enabled {
-		changelogFile := cfg.Changelog.File
-		if changelogFile == "" {
-			changelogFile = "CHANGELOG.md"
+// checkVersionMax stops a release that leaves the version.max line, e.g. 2.0.0
+// with max = "1.x", unless --allow-max is set.
+func checkVersionMax(cfg *config.Config, currentVersion, newVersion string, bumpType config.BumpType) error {
+	max := cfg.Version.Max
+	if max == "" || bumpType == config.BumpNone || version.InLine(newVersion, max) {
+		return nil
+	}
+
+	if allowMax {
+		color.Yellow("[WARN] %s exceeds version.max = %q, released because of --allow-max", newVersion, max)
+		return nil
+	}
+
+	return fmt.Errorf("%s exceeds version.max = %q: a %s bump from %s would leave the %s line. "+
+		"Rerun with --allow-max to cross the boundary deliberately, or raise version.max", newVersion, max, bumpType, currentVersion, max)
+}
+
+// parseReleaseCommits parses commit messages, dropping the ones without a
+// recognizable type. In verbose mode it prints each commit's bump.
+func parseReleaseCommits(cfg *config.Config, commits []*git.CommitInfo) []*parser.Commit {
+	parsedCommits, _ := parseCommits(cfg, commits)
+	return parsedCommits
+}
+
+// parseStrictCommits is parseReleaseCommits failing with detection.strict
+// when a commit has no recognizable type, listing the malformed commits.
+func parseStrictCommits(cfg *config.Config, commits []*git.CommitInfo) ([]*parser.Commit, error) {
+	parsedCommits, malformed := parseCommits(cfg, commits)
+	if !cfg.Detection.Strict || len(malformed) == 0 {
+		return parsedCommits, nil
+	}
+
+	for _, c := range malformed {
+		color.Red("✗ %s %s", c.Hash, c.Message)
+	}
+	return nil, fmt.Errorf("the commits above match no commit pattern (detection.strict): reword them, or fix pushed ones with \"commet annotate\"")
+}
+
+// parseCommits is parseReleaseCommits also returning the commits dropped for
+// having no recognizable type.
+func parseCommits(cfg *config.Config, commits []*git.CommitInfo) ([]*parser.Commit, []*git.CommitInfo) {
+	session, err := loadSession()
+	if err != nil {
+		color.Yellow("[WARN] Ignoring reclassifications: %v", err)
+	}
+
+	var malformed []*git.CommitInfo
+	parsedCommits := make([]*parser.Commit, 0, len(commits))
+	for _, c := range commits {
+		parsed, err := cfg.CommitParser().Parse(c.FullMessage())
+		if err != nil {
+			if verbose {
+				color.Yellow("[WARN] Failed to parse: %s", c.Message)
+			}
+			malformed = append(malformed, c)
+			continue
 		}
 
-		generator := changelog.NewGenerator(changelogFile)
-		if err := generator.Generate(newVersion, parsedCommits); err != nil {
-			return fmt.Errorf("failed to generate changelog: %w", err)
+		if overrides := commitOverrides(c, session); overrides != "" {
+			annotated := *parsed
+			if err := annotated.Annotate(overrides); err != nil {
+				color.Yellow("[WARN] Ignoring note on %s: %v", c.Hash, err)
+			} else {
+				parsed = &annotated
+				if verbose {
+					color.Cyan("[NOTE] %s: %s", c.Hash, strings.ReplaceAll(overrides, "\n", ", "))
+				}
+			}
 		}
 
-		color.Green("✓ Updated changelog: %s", changelogFile)
-		updatedFiles = append(updatedFiles, changelogFile)
-	}
+		if parsed.Excluded {
+			if verbose {
+				color.Yellow("[SKIP] Excluded from this release: %s", c.Message)
+			}
+			continue
+		}
 
-	// Git operations
-	if cfg.Git.AutoCommit && len(updatedFiles) > 0 {
-		commitMsg := strings.ReplaceAll(cfg.Git.CommitMessage, "{version}", newVersion)
-		if err := gitClient.CreateCommit(updatedFiles, commitMsg); err != nil {
-			return fmt.Errorf("failed to create commit: %w", err)
+		if !parsed.IsValidCommit() {
+			if verbose {
+				color.Yellow("[WARN] Invalid commit format: %s", c.Message)
+			}
+			malformed = append(malformed, c)
+			continue
+		}
+
+		parsed.Hash = c.Hash
+		parsed.FullHash = c.FullHash
+		parsed.Author = c.Author
+		parsed.Files = c.Files
+		parsedCommits = append(parsedCommits, parsed)
+
+		if verbose {
+			bump := cfg.CommitBump(parsed.Type, parsed.Scope, parsed.Boards, parsed.Files, parsed.Bump)
+			forceMark := ""
+			if parsed.ForceMajor {
+				forceMark = " [FORCE MAJOR]"
+			}
+			fmt.Fprintf(color.Output, "  %s → %s%s\n", truncate(c.Message, 60), bump, forceMark)
 		}
-		color.Green("✓ Created commit: %s", commitMsg)
 	}
 
-	if cfg.Git.AutoTag {
-		tagName := strings.ReplaceAll(cfg.Git.TagFormat, "{version}", newVersion)
-		tagMsg := strings.ReplaceAll(cfg.Git.TagMessage, "{version}", newVersion)
-		if err := gitClient.CreateTag(tagName, tagMsg); err != nil {
-			return fmt.Errorf("failed to create tag: %w", err)
+	return cancelReverts(commits, parsedCommits), malformed
+}
+
+// cancelReverts drops reverts of commits in the same range together with the
+// commits they undo, so a feature added and reverted before a release neither
+// bumps the version nor shows up in the changelog. A revert of a revert brings
+// the original commit back. Reverts of released commits are kept.
+func cancelReverts(commits []*git.CommitInfo, parsedCommits []*parser.Commit) []*parser.Commit {
+	active := make(map[string]bool, len(parsedCommits))
+	for _, parsed := range parsedCommits {
+		active[parsed.FullHash] = true
+	}
+
+	// Oldest first, so a revert of a revert sees the first revert applied
+	targets := make(map[string]string)
+	for i := len(parsedCommits) - 1; i >= 0; i-- {
+		parsed := parsedCommits[i]
+		if parsed.Reverts == "" {
+			continue
+		}
+
+		target := ""
+		for _, c := range commits {
+			if parsed.RevertsHash != "" && strings.HasPrefix(c.FullHash, parsed.RevertsHash) ||
+				parsed.RevertsHash == "" && c.Message == parsed.Reverts {
+				target = c.FullHash
+				break
+			}
+		}
+		if target == "" {
+			continue
+		}
+
+		targets[parsed.FullHash] = target
+		active[parsed.FullHash] = false
+		if active[target] {
+			active[target] = false
+		} else if original, ok := targets[target]; ok {
+			active[original] = true
 		}
-		color.Green("✓ Created tag: %s", tagName)
 	}
 
-	fmt.Println()
-	color.Green("Version updated: %s → %s", currentVersion, newVersion)
+	if len(targets) == 0 {
+		return parsedCommits
+	}
 
-	return nil
+	kept := make([]*parser.Commit, 0, len(parsedCommits))
+	for _, parsed := range parsedCommits {
+		if !active[parsed.FullHash] {
+			if verbose {
+				color.Yellow("[SKIP] Reverted within this release: %s", parsed.Message)
+			}
+			continue
+		}
+		kept = append(kept, parsed)
+	}
+
+	return kept
 }
 
 func detectVersion(gitClient *git.Client, cfg *config.Config) (string, error) {
@@ -451,6 +1108,12 @@ func detectVersion(gitClient *git.Client, cfg *config.Config) (string, error) {
 		switch strategy {
 		case "git-tags":
 			tag, err := gitClient.GetLatestTag()
+			for _, dangling := range gitClient.DanglingTags() {
+				color.Yellow("[WARN] Skipping tag %s: its target is missing (rewritten history?)", dangling)
+			}
+			if err != nil {
+				color.Yellow("[WARN] Tag detection failed, trying next strategy: %v", err)
+			}
 			if err == nil && tag != "" {
 				version, err := gitClient.ExtractVersionFromTag(tag)
 				if err == nil {
@@ -461,7 +1124,7 @@ func detectVersion(gitClient *git.Client, cfg *config.Config) (string, error) {
 		case "version-file":
 			filePath := cfg.Version.File
 			if fileExists(filePath) {
-				fileUpdater, err := updater.New(filePath)
+				fileUpdater, err := newFileUpdater(cfg.Version)
 				if err == nil {
 					version, err := fileUpdater.GetVersion(cfg.Version.Key)
 					if err == nil && version != "" {
@@ -475,6 +1138,343 @@ func detectVersion(gitClient *git.Client, cfg *config.Config) (string, error) {
 	return cfg.Version.Initial, nil
 }
 
+// releaseVersionFiles returns the configured version files that apply to this
+// release, skipping files whose paths none of the commits touch.
+func releaseVersionFiles(cfg *config.Config, gitClient *git.Client, commits []*git.CommitInfo) ([]config.VersionConfig, error) {
+	var changed []string
+	loaded := false
+
+	var files []config.VersionConfig
+	for _, versionFile := range cfg.GetVersionFiles() {
+		if len(versionFile.Paths) > 0 && !loaded {
+			for _, commit := range commits {
+				commitFiles, err := gitClient.ChangedFiles(commit.Hash)
+				if err != nil {
+					return nil, err
+				}
+				changed = append(changed, commitFiles...)
+			}
+			loaded = true
+		}
+
+		if !versionFile.Touches(changed) {
+			if verbose {
+				color.Cyan("[SKIP] %s: no changes under %s", versionFile.File, strings.Join(versionFile.Paths, ", "))
+			}
+			continue
+		}
+
+		files = append(files, versionFile)
+	}
+
+	return files, nil
+}
+
+// newFileUpdater returns the updater for versionFile: the marker updater when
+// a marker is configured, otherwise the one matching the file format.
+func newFileUpdater(versionFile config.VersionConfig) (updater.Updater, error) {
+	if versionFile.Marker != "" {
+		return updater.NewMarkerUpdater(versionFile.File, versionFile.Marker), nil
+	}
+	return updater.New(versionFile.File)
+}
+
+// fileVersion is ver as written to versionFile, after its template.
+func fileVersion(versionFile config.VersionConfig, ver string) (string, error) {
+	if versionFile.Template == "" {
+		return ver, nil
+	}
+	return version.Expand(versionFile.Template, ver)
+}
+
+// updateVersionFiles writes ver to each of versionFiles, saving each one in
+// backup first. It returns the files it touched.
+func updateVersionFiles(versionFiles []config.VersionConfig, backup *updater.Backup, ver string) ([]string, error) {
+	updatedFiles := []string{}
+	for _, versionFile := range versionFiles {
+		filePath := versionFile.File
+
+		fileVer, err := fileVersion(versionFile, ver)
+		if err != nil {
+			return nil, fmt.Errorf("failed to format version for %s: %w", filePath, err)
+		}
+		if !fileExists(filePath) {
+			if !versionFile.CreateIfMissing {
+				color.Yellow("[WARN] File not found: %s", filePath)
+				continue
+			}
+
+			if err := backup.Save(filePath); err != nil {
+				return nil, err
+			}
+
+			if err := updater.Create(filePath, versionFile.Key, fileVer); err != nil {
+				return nil, fmt.Errorf("failed to create %s: %w", filePath, err)
+			}
+
+			color.Green("✓ Created %s", filePath)
+			updatedFiles = append(updatedFiles, filePath)
+			continue
+		}
+
+		fileUpdater, err := newFileUpdater(versionFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create updater for %s: %w", filePath, err)
+		}
+
+		// Dynamic versions (e.g. setup.cfg attr:) live in another file
+		if redirector, ok := fileUpdater.(updater.Redirector); ok {
+			target, err := redirector.TargetFile(versionFile.Key)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve %s: %w", filePath, err)
+			}
+			filePath = target
+		}
+
+		if err := backup.Save(filePath); err != nil {
+			return nil, err
+		}
+
+		if err := fileUpdater.SetVersion(versionFile.Key, fileVer); err != nil {
+			return nil, fmt.Errorf("failed to update %s: %w", filePath, err)
+		}
+
+		color.Green("✓ Updated %s", filePath)
+		updatedFiles = append(updatedFiles, filePath)
+
+		if versionFile.SyncLockfile {
+			lock, lockFile, err := updater.CargoLockFor(filePath)
+			if err != nil {
+				return nil, err
+			}
+
+			if err := backup.Save(lockFile); err != nil {
+				return nil, err
+			}
+
+			if err := lock.SetVersion("", fileVer); err != nil {
+				return nil, fmt.Errorf("failed to update %s: %w", lockFile, err)
+			}
+
+			color.Green("✓ Updated %s", lockFile)
+			updatedFiles = append(updatedFiles, lockFile)
+		}
+	}
+
+	return updatedFiles, nil
+}
+
+// writeReleaseChangelogs writes the enabled changelogs (CHANGELOG.md targets
+// and debian/changelog) for a release and returns the files it changed.
+func writeReleaseChangelogs(cfg *config.Config, backup *updater.Backup, ver string, commits []*parser.Commit) ([]string, error) {
+	var written []string
+
+	if cfg.Changelog.Enabled {
+		files, err := writeChangelogs(cfg, backup, ver, commits)
+		if err != nil {
+			return nil, err
+		}
+		written = append(written, files...)
+	}
+
+	if cfg.Debian.Enabled {
+		if err := backup.Save(cfg.Debian.File); err != nil {
+			return nil, err
+		}
+
+		if err := changelog.NewDebianGenerator(cfg.Debian).WithClock(changelog.ReleaseClock(cfg.Changelog)).Generate(ver, commits); err != nil {
+			return nil, fmt.Errorf("failed to generate debian changelog: %w", err)
+		}
+
+		color.Green("✓ Updated %s", cfg.Debian.File)
+		written = append(written, cfg.Debian.File)
+	}
+
+	return written, nil
+}
+
+// writeChangelogs writes the entry for version to every configured changelog
+// target, using hand-written release notes when present. It returns the files it touched.
+func writeChangelogs(cfg *config.Config, backup *updater.Backup, version string, commits []*parser.Commit) ([]string, error) {
+	notes, err := changelog.ReadReleaseNotes(cfg.Changelog.ReleaseNotesFile)
+	if err != nil {
+		return nil, err
+	}
+	if notes == "" {
+		if err := changelog.Summarize(&cfg.Changelog, version, commits); err != nil {
+			return nil, err
+		}
+	}
+
+	var written []string
+	for _, target := range cfg.Changelog.Targets() {
+		if err := backup.Save(target.File); err != nil {
+			return nil, err
+		}
+
+		generator := changelog.NewGenerator(target.File, target)
+		if notes != "" {
+			err = generator.GenerateFromNotes(version, notes)
+		} else {
+			err = generator.Generate(version, commits)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate changelog %s: %w", target.File, err)
+		}
+
+		color.Green("✓ Updated changelog: %s", target.File)
+		written = append(written, target.File)
+	}
+
+	if notes != "" {
+		archivePath := changelog.ReleaseNotesArchivePath(cfg.Changelog.ReleaseNotesArchive, version)
+		for _, path := range []string{cfg.Changelog.ReleaseNotesFile, archivePath} {
+			if path == "" {
+				continue
+			}
+			if err := backup.Save(path); err != nil {
+				return nil, err
+			}
+		}
+
+		archived, err := changelog.ArchiveReleaseNotes(cfg.Changelog.ReleaseNotesFile, cfg.Changelog.ReleaseNotesArchive, version)
+		if err != nil {
+			return nil, err
+		}
+
+		color.Green("✓ Used release notes from %s", cfg.Changelog.ReleaseNotesFile)
+		written = append(written, archived...)
+	}
+
+	return written, nil
+}
+
+// changelogEntry renders the entry for version with target, from the
+// hand-written release notes when there are any.
+func changelogEntry(target config.ChangelogConfig, version, notes string, commits []*parser.Commit) (string, error) {
+	generator := changelog.NewGenerator(target.File, target)
+	if notes != "" {
+		return generator.RenderNotes(version, notes), nil
+	}
+	return generator.Render(version, commits)
+}
+
+// printFileDiff shows the unified diff SetVersion would apply to versionFile.
+func printFileDiff(versionFile config.VersionConfig, newVersion string) {
+	filePath := versionFile.File
+
+	newVersion, err := fileVersion(versionFile, newVersion)
+	if err != nil {
+		color.Yellow("[WARN] %s: %v", filePath, err)
+		return
+	}
+	if !fileExists(filePath) {
+		if versionFile.CreateIfMissing {
+			color.Cyan("%s: would be created with version %s", filePath, newVersion)
+			fmt.Fprintln(color.Output)
+			return
+		}
+		color.Yellow("[WARN] File not found: %s", filePath)
+		return
+	}
+
+	fileUpdater, err := newFileUpdater(versionFile)
+	if err != nil {
+		color.Yellow("[WARN] %s: %v", filePath, err)
+		return
+	}
+
+	if redirector, ok := fileUpdater.(updater.Redirector); ok {
+		target, err := redirector.TargetFile(versionFile.Key)
+		if err != nil {
+			color.Yellow("[WARN] %s: %v", filePath, err)
+			return
+		}
+		filePath = target
+	}
+
+	before, err := os.ReadFile(filePath)
+	if err != nil {
+		color.Yellow("[WARN] %s: %v", filePath, err)
+		return
+	}
+
+	after, err := fileUpdater.Preview(versionFile.Key, newVersion)
+	if err != nil {
+		color.Yellow("[WARN] %s: %v", filePath, err)
+		return
+	}
+
+	printUnifiedDiff(filePath, before, after)
+
+	if versionFile.SyncLockfile {
+		lock, lockFile, err := updater.CargoLockFor(filePath)
+		if err != nil {
+			color.Yellow("[WARN] %s: %v", filePath, err)
+			return
+		}
+
+		before, err := os.ReadFile(lockFile)
+		if err != nil {
+			color.Yellow("[WARN] %s: %v", lockFile, err)
+			return
+		}
+
+		after, err := lock.Preview("", newVersion)
+		if err != nil {
+			color.Yellow("[WARN] %s: %v", lockFile, err)
+			return
+		}
+
+		printUnifiedDiff(lockFile, before, after)
+	}
+}
+
+// printUnifiedDiff prints a colored unified diff of filePath's content.
+func printUnifiedDiff(filePath string, before, after []byte) {
+	unified := diff.Unified("a/"+filePath, "b/"+filePath, string(before), string(after))
+	if unified == "" {
+		color.Cyan("%s: no changes", filePath)
+		fmt.Fprintln(color.Output)
+		return
+	}
+
+	for _, line := range strings.SplitAfter(unified, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			color.New(color.Bold).Print(line)
+		case strings.HasPrefix(line, "@@"):
+			color.New(color.FgCyan).Print(line)
+		case strings.HasPrefix(line, "+"):
+			color.New(color.FgGreen).Print(line)
+		case strings.HasPrefix(line, "-"):
+			color.New(color.FgRed).Print(line)
+		default:
+			fmt.Fprint(color.Output, line)
+		}
+	}
+	fmt.Fprintln(color.Output)
+}
+
+// rollback restores every file saved in backup unless --no-rollback was given
+// and returns cause, so the release stops before any commit or tag is made.
+func rollback(backup *updater.Backup, cause error) error {
+	if noRollback || len(backup.Files()) == 0 {
+		return cause
+	}
+
+	if err := backup.Restore(); err != nil {
+		color.Red("[ERROR] Rollback failed: %v", err)
+		return cause
+	}
+
+	for _, file := range backup.Files() {
+		color.Yellow("↺ Restored %s", file)
+	}
+
+	return cause
+}
+
 func truncate(s string, max int) string {
 	if len(s) <= max {
 		return s
@@ -488,7 +1488,14 @@ func fileExists(path string) bool {
 }
 
 func main() {
-	if err := rootCmd.Execute(); err != nil {
+	start := time.Now()
+	cmd, err := rootCmd.ExecuteC()
+	recordStats(cmd, start, err)
+	if err != nil {
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
 		os.Exit(1)
 	}
 }
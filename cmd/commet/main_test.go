@@ -0,0 +1,1117 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yendefrr/commet/commettest"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+const e2eConfig = `[version]
+file = "package.json"
+key = "version"
+
+[detection]
+strategies = ["git-tags", "version-file"]
+
+[git]
+auto_commit = true
+commit_message = "Conf: bump version to {version}"
+auto_tag = true
+tag_format = "v{version}"
+tag_message = "Release {version}"
+
+[changelog]
+enabled = true
+file = "CHANGELOG.md"
+`
+
+func TestMain(m *testing.M) {
+	commettest.Main(m)
+}
+
+func TestReleaseEndToEnd(t *testing.T) {
+	runner := commettest.Build(t)
+
+	tests := []struct {
+		name    string
+		commits []string
+		args    []string
+		version string
+	}{
+		{"feature bumps minor", []string{"Fix: handle empty input", "Feature(api): add export"}, nil, "1.3.0"},
+		{"fix bumps patch", []string{"Fix: handle empty input"}, nil, "1.2.4"},
+		{"breaking bumps major", []string{"Fix!(core): drop legacy endpoint"}, nil, "2.0.0"},
+		{"breaking change footer bumps major", []string{"Fix(api): paginate by cursor\n\nBREAKING CHANGE: the page parameter is gone"}, nil, "2.0.0"},
+		{"forced bump", []string{"Docs: typo"}, []string{"--bump", "minor"}, "1.3.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := commettest.NewReleaseRepo(t, e2eConfig)
+
+			for _, message := range tt.commits {
+				repo.Commit(message)
+			}
+
+			repo.Run(runner, tt.args...)
+
+			repo.AssertFile("package.json", `{"version": "`+tt.version+`"}`+"\n")
+			repo.AssertFileContains("CHANGELOG.md", tt.version)
+			repo.AssertTag("v" + tt.version)
+			if got, want := repo.HeadMessage(), "Conf: bump version to "+tt.version; got != want {
+				t.Errorf("HEAD = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestDryRunChangesNothing(t *testing.T) {
+	runner := commettest.Build(t)
+
+	repo := commettest.NewReleaseRepo(t, e2eConfig)
+	repo.Commit("Feature: add export")
+
+	repo.Run(runner, "--dry-run")
+
+	repo.AssertFile("package.json", `{"version": "1.2.3"}`+"\n")
+	repo.AssertNoTag("v1.3.0")
+}
+
+func TestPromote(t *testing.T) {
+	runner := commettest.Build(t)
+
+	repo := commettest.NewReleaseRepo(t, e2eConfig)
+
+	repo.Commit("Feature!: new storage format")
+	repo.WriteFile("package.json", `{"version": "2.0.0-rc.1"}`+"\n")
+	repo.Commit("Conf: bump version to 2.0.0-rc.1")
+	repo.Tag("v2.0.0-rc.1")
+	repo.Commit("Fix(storage): migrate empty buckets")
+
+	repo.Run(runner, "promote")
+
+	repo.AssertFile("package.json", `{"version": "2.0.0"}`+"\n")
+	repo.AssertTag("v2.0.0")
+	repo.AssertFileContains("CHANGELOG.md", "new storage format")
+	repo.AssertFileContains("CHANGELOG.md", "migrate empty buckets")
+}
+
+func TestMajorRequiresConfirmation(t *testing.T) {
+	runner := commettest.Build(t)
+
+	repo := commettest.NewReleaseRepo(t, e2eConfig+"\n[policy]\nrequire_confirmation_for_major = true\n")
+	repo.Commit("Fix!(core): drop legacy endpoint")
+
+	output := repo.RunError(runner)
+	if !strings.Contains(output, "Fix!(core): drop legacy endpoint") {
+		t.Errorf("output does not name the commit forcing the major:\n%s", output)
+	}
+	repo.AssertNoTag("v2.0.0")
+
+	repo.Run(runner, "--allow-major")
+	repo.AssertTag("v2.0.0")
+}
+
+func TestBranchChannels(t *testing.T) {
+	runner := commettest.Build(t)
+
+	repo := commettest.NewRepo(t)
+	repo.WriteFile(".commet.toml", e2eConfig+`
+[[branches]]
+branch = "develop"
+prerelease = "beta"
+
+[[branches]]
+branch = "main"
+`)
+	repo.WriteFile("package.json", `{"version": "1.3.0"}`+"\n")
+	repo.Commit("Conf: initial")
+	repo.Tag("v1.3.0")
+
+	repo.Checkout("develop")
+	repo.Commit("Feature: add export")
+	repo.Run(runner)
+	repo.AssertTag("v1.4.0-beta.1")
+
+	repo.Commit("Fix: handle empty export")
+	repo.Run(runner)
+	repo.AssertTag("v1.4.0-beta.2")
+
+	repo.Checkout("main")
+	repo.Commit("Fix: trim export names")
+	repo.Run(runner)
+	repo.AssertTag("v1.4.0")
+	repo.AssertFile("package.json", `{"version": "1.4.0"}`+"\n")
+
+	repo.Checkout("feature/import")
+	repo.Commit("Feature: add import")
+	if out := repo.RunError(runner); !strings.Contains(out, "feature/import is not a release branch") {
+		t.Errorf("unexpected output on an unmapped branch:\n%s", out)
+	}
+}
+
+func TestMaintenanceBranch(t *testing.T) {
+	runner := commettest.Build(t)
+
+	repo := commettest.NewRepo(t)
+	repo.WriteFile(".commet.toml", e2eConfig+`
+[[branches]]
+branch = "main"
+
+[[branches]]
+branch = "release/*"
+maintenance = true
+`)
+	repo.WriteFile("package.json", `{"version": "1.2.7"}`+"\n")
+	repo.Commit("Conf: initial")
+	repo.Tag("v1.2.7")
+
+	repo.Checkout("release/1.2.x")
+	repo.Checkout("main")
+	repo.Commit("Breaking: drop legacy API")
+	repo.Run(runner)
+	repo.AssertTag("v2.0.0")
+
+	repo.Checkout("release/1.2.x")
+	repo.Commit("Fix: backport timeout fix")
+	repo.Run(runner)
+	repo.AssertTag("v1.2.8")
+	repo.AssertFile("package.json", `{"version": "1.2.8"}`+"\n")
+
+	repo.Commit("Feature: backport export")
+	if out := repo.RunError(runner); !strings.Contains(out, "outside the 1.2.x line") {
+		t.Errorf("unexpected output for a feature on a maintenance branch:\n%s", out)
+	}
+}
+
+func TestVersionMax(t *testing.T) {
+	runner := commettest.Build(t)
+
+	repo := commettest.NewReleaseRepo(t, strings.Replace(e2eConfig, `key = "version"`, `key = "version"
+max = "1.x"`, 1))
+
+	repo.Commit("Feature: add export")
+	repo.Run(runner)
+	repo.AssertTag("v1.3.0")
+
+	repo.Commit("Breaking: drop legacy API")
+	if out := repo.RunError(runner); !strings.Contains(out, `2.0.0 exceeds version.max = "1.x"`) {
+		t.Errorf("unexpected output for a release beyond version.max:\n%s", out)
+	}
+	repo.AssertNoTag("v2.0.0")
+
+	repo.Run(runner, "--allow-max")
+	repo.AssertTag("v2.0.0")
+}
+
+func TestAliasTags(t *testing.T) {
+	runner := commettest.Build(t)
+
+	repo := commettest.NewRepo(t)
+	repo.WriteFile(".commet.toml", strings.Replace(e2eConfig, `tag_format = "v{version}"`, `tag_format = "v{version}"
+alias_tags = true`, 1))
+	repo.WriteFile("package.json", `{"version": "1.4.1"}`+"\n")
+	repo.Commit("Conf: initial")
+	repo.Tag("v1.4.1")
+
+	repo.Commit("Fix: handle timeouts")
+	repo.Run(runner)
+	repo.AssertTag("v1.4.2")
+	repo.AssertTagAtHead("v1")
+	repo.AssertTagAtHead("v1.4")
+
+	repo.Commit("Feature: add export")
+	out := repo.Run(runner)
+	if !strings.Contains(out, "Moved tag: v1") {
+		t.Errorf("v1 was not reported as moved:\n%s", out)
+	}
+	repo.AssertTag("v1.5.0")
+	repo.AssertTagAtHead("v1")
+	repo.AssertTagAtHead("v1.5")
+	repo.AssertTag("v1.4")
+
+	repo.Commit("Fix: fix export")
+	if out := repo.Run(runner, "--prerelease", "rc"); strings.Contains(out, "Moved tag") {
+		t.Errorf("a pre-release moved the alias tags:\n%s", out)
+	}
+	repo.AssertTag("v1.5.1-rc.1")
+}
+
+func TestChangelogLocales(t *testing.T) {
+	runner := commettest.Build(t)
+
+	repo := commettest.NewReleaseRepo(t, e2eConfig+`
+[[changelog.locales]]
+locale = "zh"
+translate_command = "sed 's/add export/添加导出/'"
+
+[changelog.locales.titles]
+Feature = "新功能"
+`)
+	repo.Commit("Feature(api): add export")
+
+	repo.Run(runner)
+	repo.AssertFileContains("CHANGELOG.md", "### ✨ Features")
+	repo.AssertFileContains("CHANGELOG.md", "add export")
+	repo.AssertFileContains("CHANGELOG.zh.md", "## [1.3.0]")
+	repo.AssertFileContains("CHANGELOG.zh.md", "### ✨ 新功能")
+	repo.AssertFileContains("CHANGELOG.zh.md", "**api**: 添加导出")
+}
+
+func TestConventionalPreset(t *testing.T) {
+	runner := commettest.Build(t)
+
+	tests := []struct {
+		name    string
+		commits []string
+		version string
+	}{
+		{"feat bumps minor", []string{"fix: handle empty input", "feat(api): add export"}, "1.3.0"},
+		{"fix bumps patch", []string{"chore: update tooling", "fix(api): handle timeouts"}, "1.2.4"},
+		{"bang after scope bumps major", []string{"feat(api)!: drop v1 endpoints"}, "2.0.0"},
+		{"chores do not release", []string{"chore: update tooling", "docs: fix typo"}, "1.2.3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := commettest.NewRepo(t)
+			repo.WriteFile(".commet.toml", `preset = "conventional"
+
+`+e2eConfig)
+			repo.WriteFile("package.json", `{"version": "1.2.3"}`+"\n")
+			repo.Commit("chore: initial")
+			repo.Tag("v1.2.3")
+			for _, message := range tt.commits {
+				repo.Commit(message)
+			}
+
+			repo.Run(runner)
+			repo.AssertFile("package.json", `{"version": "`+tt.version+`"}`+"\n")
+		})
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	runner := commettest.Build(t)
+
+	repo := commettest.NewReleaseRepo(t, e2eConfig)
+	hash := repo.Commit("Fix: remove endpoint")
+	repo.Commit("Docs: describe endpoints")
+
+	repo.Run(runner, "annotate", hash, "--breaking", "--description", "remove the legacy endpoint")
+	if out := repo.Run(runner, "annotate", hash); !strings.Contains(out, "Breaking: true") {
+		t.Errorf("annotate did not print the note:\n%s", out)
+	}
+
+	repo.Run(runner)
+	repo.AssertTag("v2.0.0")
+	repo.AssertFileContains("CHANGELOG.md", "remove the legacy endpoint")
+}
+
+func TestLockedExtends(t *testing.T) {
+	runner := commettest.Build(t)
+
+	preset := filepath.Join(t.TempDir(), "preset.toml")
+	if err := os.WriteFile(preset, []byte("[bump_rules]\nDocs = \"minor\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, preset)
+	}))
+	defer server.Close()
+
+	repo := commettest.NewReleaseRepo(t, fmt.Sprintf("extends = [%q]\n\n", server.URL+"/preset.toml")+e2eConfig)
+	repo.Commit("Docs: describe endpoints")
+
+	if out := repo.RunError(runner); !strings.Contains(out, "is not pinned in") || !strings.Contains(out, "commet lock") {
+		t.Errorf("output = %q, want the unpinned source and guidance", out)
+	}
+
+	repo.Run(runner, "lock")
+	repo.AssertFileContains(".commet.lock", server.URL+"/preset.toml")
+	repo.AssertFileContains(".commet.lock", "sha256")
+	repo.Commit("Conf: lock config sources")
+
+	// The preset makes Docs commits release a minor version
+	repo.Run(runner)
+	repo.AssertTag("v1.3.0")
+
+	if err := os.WriteFile(preset, []byte("[bump_rules]\nDocs = \"major\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	repo.Commit("Docs: describe errors")
+	if out := repo.RunError(runner); !strings.Contains(out, "changed since it was locked") {
+		t.Errorf("output = %q, want the changed source refused", out)
+	}
+	repo.AssertNoTag("v2.0.0")
+}
+
+func TestReleasePR(t *testing.T) {
+	runner := commettest.Build(t)
+
+	var pulls []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&payload)
+
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/pulls"):
+			json.NewEncoder(w).Encode(pulls)
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/pulls"):
+			payload["number"] = len(pulls) + 1
+			payload["html_url"] = fmt.Sprintf("https://github.example/pull/%d", len(pulls)+1)
+			pulls = append(pulls, payload)
+			json.NewEncoder(w).Encode(payload)
+		case r.Method == http.MethodPatch && strings.HasSuffix(r.URL.Path, "/pulls/1"):
+			pulls[0]["title"], pulls[0]["body"] = payload["title"], payload["body"]
+			json.NewEncoder(w).Encode(pulls[0])
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("GITHUB_API_URL", server.URL)
+
+	remoteDir := filepath.Join(t.TempDir(), "app.git")
+	remote, err := gogit.PlainInit(remoteDir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo := commettest.NewReleaseRepo(t, e2eConfig+`
+[release_pr]
+forge = "github"
+`)
+	repo.AddRemote("origin", remoteDir)
+
+	repo.Commit("Feature: add export")
+	if out := repo.Run(runner, "release-pr"); !strings.Contains(out, "Opened release pull request #1") {
+		t.Fatalf("no pull request was opened:\n%s", out)
+	}
+	if _, err := remote.Reference(plumbing.NewBranchReferenceName("commet/release"), false); err != nil {
+		t.Fatalf("commet/release was not pushed: %v", err)
+	}
+	repo.AssertFile("package.json", `{"version": "1.2.3"}`+"\n")
+	if body := pulls[0]["body"].(string); !strings.Contains(body, "**1.3.0**") || !strings.Contains(body, "add export") {
+		t.Errorf("unexpected pull request body:\n%s", body)
+	}
+
+	if out := repo.Run(runner, "release-pr"); !strings.Contains(out, "is up to date") {
+		t.Errorf("an unchanged release updated the pull request:\n%s", out)
+	}
+
+	repo.Commit("Fix: handle nil")
+	if out := repo.Run(runner, "release-pr"); !strings.Contains(out, "Updated release pull request #1") {
+		t.Errorf("the pull request was not updated:\n%s", out)
+	}
+	if body := pulls[0]["body"].(string); !strings.Contains(body, "handle nil") {
+		t.Errorf("the new commit is missing from the pull request:\n%s", body)
+	}
+
+	// A squash merge of the release pull request
+	repo.WriteFile("package.json", `{"version": "1.3.0"}`+"\n")
+	repo.Commit("Release 1.3.0 (#1)")
+	repo.Run(runner, "release-pr")
+	repo.AssertTagAtHead("v1.3.0")
+	if _, err := remote.Reference(plumbing.NewTagReferenceName("v1.3.0"), false); err != nil {
+		t.Errorf("v1.3.0 was not pushed: %v", err)
+	}
+
+	if out := repo.Run(runner, "release-pr"); !strings.Contains(out, "No release pending") {
+		t.Errorf("a release is pending right after tagging:\n%s", out)
+	}
+}
+
+func TestReverts(t *testing.T) {
+	runner := commettest.Build(t)
+
+	repo := commettest.NewReleaseRepo(t, e2eConfig+`
+[bump_rules]
+Fix = "patch"
+Feature = "minor"
+Revert = "patch"
+`)
+
+	feature := repo.Commit("Feature: add export")
+	repo.Commit("Revert \"Feature: add export\"\n\nThis reverts commit " + feature + ".")
+	repo.Commit("Fix: handle nil")
+	repo.Run(runner)
+	repo.AssertTag("v1.2.4")
+	if changelog := repo.ReadFile("CHANGELOG.md"); strings.Contains(changelog, "add export") {
+		t.Errorf("a feature reverted before the release is in the changelog:\n%s", changelog)
+	}
+
+	// Reverting a released commit is a change of its own
+	repo.Commit("Revert \"Fix: handle nil\"")
+	repo.Run(runner)
+	repo.AssertTag("v1.2.5")
+	repo.AssertFileContains("CHANGELOG.md", "Reverts")
+}
+
+func TestTypeAliases(t *testing.T) {
+	runner := commettest.Build(t)
+
+	repo := commettest.NewReleaseRepo(t, "case_insensitive_types = true\n"+e2eConfig+`
+[type_aliases]
+bugfix = "Fix"
+`)
+
+	repo.Commit("BUGFIX: handle nil")
+	repo.Run(runner)
+	repo.AssertTag("v1.2.4")
+	repo.AssertFileContains("CHANGELOG.md", "Bug Fixes")
+
+	repo.Commit("FEATURE: add export")
+	repo.Run(runner)
+	repo.AssertTag("v1.3.0")
+}
+
+func TestPipelineConfig(t *testing.T) {
+	runner := commettest.Build(t)
+
+	repo := commettest.NewReleaseRepo(t, e2eConfig+`
+[pipeline]
+disable = ["changelog"]
+
+[pipeline.hooks]
+after_tag = "echo $COMMET_PREVIOUS_VERSION $COMMET_VERSION $COMMET_BUMP > released.txt"
+`)
+
+	repo.Commit("Feature: add export")
+	repo.Run(runner)
+	repo.AssertTag("v1.3.0")
+	repo.AssertFile("released.txt", "1.2.3 1.3.0 minor\n")
+	if _, err := os.Stat(filepath.Join(repo.Dir, "CHANGELOG.md")); err == nil {
+		t.Error("CHANGELOG.md written with the changelog step disabled")
+	}
+
+	repo.WriteFile(".commet.toml", e2eConfig+`
+[pipeline]
+steps = ["detect", "parse", "collect", "calculate"]
+`)
+	repo.Commit("Fix: typo")
+	if out := repo.RunError(runner); !strings.Contains(out, "pipeline.steps must start with") {
+		t.Errorf("misordered steps accepted:\n%s", out)
+	}
+}
+
+func TestLint(t *testing.T) {
+	runner := commettest.Build(t)
+
+	repo := commettest.NewReleaseRepo(t, e2eConfig+`
+[lint]
+scopes = ["api", "cli"]
+`)
+
+	repo.Commit("Feature(api): add export")
+	repo.Commit("Fix(cli): handle nil")
+	output := repo.Run(runner, "lint")
+	if !strings.Contains(output, "All 2 commit messages follow") {
+		t.Errorf("lint output = %q, want both commits to pass", output)
+	}
+
+	repo.Commit("Fix(web): handle nil")
+	repo.Commit("wip")
+	output = repo.RunError(runner, "lint")
+	for _, want := range []string{`scope "web" is not one of api, cli`, "matches no commit pattern", "2 of 4 commit messages"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("lint output = %q, want %q", output, want)
+		}
+	}
+
+	repo.Run(runner, "lint", "--message", "Docs(cli): document lint")
+	repo.RunError(runner, "lint", "--message", "Chore: tidy up")
+}
+
+func TestHookInstall(t *testing.T) {
+	runner := commettest.Build(t)
+
+	repo := commettest.NewRepo(t)
+	repo.WriteFile(".commet.toml", e2eConfig)
+	repo.Commit("Conf: initial")
+
+	repo.Run(runner, "hook", "install")
+	repo.AssertFileContains(".git/hooks/commit-msg", `exec commet lint --message - < "$1"`)
+	repo.Run(runner, "hook", "install")
+
+	repo.WriteFile(".git/hooks/commit-msg", "#!/bin/sh\nexit 0\n")
+	repo.RunError(runner, "hook", "install")
+	repo.Run(runner, "hook", "install", "--force")
+	repo.AssertFileContains(".git/hooks/commit-msg", "commet lint")
+}
+
+func TestSkipMarkers(t *testing.T) {
+	runner := commettest.Build(t)
+
+	repo := commettest.NewReleaseRepo(t, e2eConfig)
+
+	repo.Commit("Feature: reformat sources [skip version]")
+	repo.Run(runner)
+	repo.AssertNoTag("v1.3.0")
+
+	repo.Commit("Fix: handle nil")
+	repo.Run(runner)
+	repo.AssertTag("v1.2.4")
+	if strings.Contains(repo.ReadFile("CHANGELOG.md"), "reformat sources") {
+		t.Errorf("CHANGELOG.md lists the skipped commit")
+	}
+}
+
+func TestStrictParsing(t *testing.T) {
+	runner := commettest.Build(t)
+
+	repo := commettest.NewReleaseRepo(t, strings.Replace(e2eConfig, "[detection]\n", "[detection]\nstrict = true\n", 1))
+
+	repo.Commit("Feature: add export")
+	repo.Commit("wip")
+	output := repo.RunError(runner)
+	if !strings.Contains(output, "wip") || !strings.Contains(output, "match no commit pattern") {
+		t.Errorf("output = %q, want the malformed commit listed", output)
+	}
+	repo.AssertNoTag("v1.3.0")
+}
+
+func TestAutoPush(t *testing.T) {
+	runner := commettest.Build(t)
+
+	remoteDir := filepath.Join(t.TempDir(), "app.git")
+	remote, err := gogit.PlainInit(remoteDir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo := commettest.NewReleaseRepo(t, strings.Replace(e2eConfig, "[git]\n", "[git]\nauto_push = true\npush_branch = \"release\"\n", 1))
+	repo.AddRemote("origin", remoteDir)
+
+	repo.Commit("Feature: add export")
+	if out := repo.Run(runner, "--dry-run"); !strings.Contains(out, "Would push to: origin") {
+		t.Errorf("the dry run does not mention the push:\n%s", out)
+	}
+	if _, err := remote.Reference(plumbing.NewBranchReferenceName("release"), false); err == nil {
+		t.Fatal("the dry run pushed")
+	}
+
+	repo.Run(runner)
+	branch, err := remote.Reference(plumbing.NewBranchReferenceName("release"), false)
+	if err != nil {
+		t.Fatalf("release was not pushed: %v", err)
+	}
+	commit, err := remote.CommitObject(branch.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Conf: bump version to 1.3.0"; strings.TrimSpace(commit.Message) != want {
+		t.Errorf("pushed commit = %q, want %q", commit.Message, want)
+	}
+	if _, err := remote.Reference(plumbing.NewTagReferenceName("v1.3.0"), false); err != nil {
+		t.Errorf("v1.3.0 was not pushed: %v", err)
+	}
+}
+
+func TestAutoPushRemoteAhead(t *testing.T) {
+	runner := commettest.Build(t)
+
+	remoteDir := filepath.Join(t.TempDir(), "app.git")
+	remote, err := gogit.PlainInit(remoteDir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo := commettest.NewReleaseRepo(t, strings.Replace(e2eConfig, "[git]\n", "[git]\nauto_push = true\npush_branch = \"main\"\n", 1))
+	repo.AddRemote("origin", remoteDir)
+	repo.Commit("Feature: add export")
+	repo.Run(runner)
+
+	// Someone else pushes while the next release is prepared
+	pushElsewhere := func(file, content, message string) {
+		t.Helper()
+		dir := t.TempDir()
+		clone, err := gogit.PlainClone(dir, false, &gogit.CloneOptions{URL: remoteDir, ReferenceName: plumbing.NewBranchReferenceName("main")})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		worktree, err := clone.Worktree()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := worktree.Add(file); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := worktree.Commit(message, &gogit.CommitOptions{Author: &commettest.Author}); err != nil {
+			t.Fatal(err)
+		}
+		if err := clone.Push(&gogit.PushOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pushElsewhere("README.md", "# app\n", "Docs: add readme")
+	repo.Commit("Fix: handle nil")
+	if out := repo.Run(runner); !strings.Contains(out, "Replayed the release on origin/main") {
+		t.Errorf("the release was not replayed:\n%s", out)
+	}
+
+	branch, err := remote.Reference(plumbing.NewBranchReferenceName("main"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tip, err := remote.CommitObject(branch.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var history []string
+	for commit := tip; len(history) < 3; {
+		history = append(history, strings.TrimSpace(commit.Message))
+		if commit, err = commit.Parent(0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := []string{"Conf: bump version to 1.3.1", "Fix: handle nil", "Docs: add readme"}; strings.Join(history, "\n") != strings.Join(want, "\n") {
+		t.Errorf("remote main = %q, want the release replayed on top of the readme %q", history, want)
+	}
+	tagRef, err := remote.Reference(plumbing.NewTagReferenceName("v1.3.1"), false)
+	if err != nil {
+		t.Fatalf("v1.3.1 was not pushed: %v", err)
+	}
+	if tag, err := remote.TagObject(tagRef.Hash()); err != nil || tag.Target != tip.Hash {
+		t.Errorf("v1.3.1 does not tag the replayed release commit %s", tip.Hash)
+	}
+	repo.AssertFile("README.md", "# app\n")
+
+	// A change to a file the release writes too cannot be replayed
+	pushElsewhere("CHANGELOG.md", "# Changelog\n", "Docs: reset changelog")
+	repo.Commit("Fix: handle empty input")
+	if out := repo.RunError(runner); !strings.Contains(out, "CHANGELOG.md changed upstream too") || !strings.Contains(out, "git push --follow-tags") {
+		t.Errorf("output = %q, want the conflict and guidance", out)
+	}
+	if _, err := remote.Reference(plumbing.NewTagReferenceName("v1.3.2"), false); err == nil {
+		t.Error("v1.3.2 was pushed although its commit was not")
+	}
+}
+
+func TestVerifyDevelopmentVersion(t *testing.T) {
+	runner := commettest.Build(t)
+
+	tests := []struct {
+		name    string
+		config  string
+		version string
+	}{
+		{"snapshot", e2eConfig + "\n[snapshot]\nenabled = true\n", "1.4.0-SNAPSHOT"},
+		{"post release version", strings.Replace(e2eConfig, "[git]\n", "[git]\npost_release_version = \"{next_patch}-dev\"\n", 1), "1.3.1-dev"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := commettest.NewReleaseRepo(t, tt.config)
+			repo.Commit("Feature: add export")
+
+			repo.Run(runner)
+			repo.AssertTag("v1.3.0")
+			repo.AssertFile("package.json", `{"version": "`+tt.version+`"}`+"\n")
+
+			repo.Run(runner, "verify")
+
+			repo.WriteFile("package.json", `{"version": "1.3.0"}`+"\n")
+			output := repo.RunError(runner, "verify")
+			if !strings.Contains(output, "expected "+tt.version) {
+				t.Errorf("verify does not expect %s:\n%s", tt.version, output)
+			}
+		})
+	}
+}
+
+func TestVerifyPathGatedFiles(t *testing.T) {
+	runner := commettest.Build(t)
+
+	repo := commettest.NewRepo(t)
+	repo.WriteFile(".commet.toml", e2eConfig+`
+[[additional_files]]
+file = "openapi.yaml"
+key = "info.version"
+paths = ["openapi.yaml", "api/"]
+`)
+	repo.WriteFile("package.json", `{"version": "1.2.3"}`+"\n")
+	repo.WriteFile("openapi.yaml", "info:\n  version: 1.2.3\n")
+	repo.Commit("Conf: initial")
+	repo.Tag("v1.2.3")
+	repo.Commit("Feature: add export")
+
+	// The release does not touch the API, so the spec keeps its version
+	repo.Run(runner)
+	repo.AssertTag("v1.3.0")
+	repo.AssertFile("openapi.yaml", "info:\n  version: 1.2.3\n")
+	repo.Run(runner, "verify")
+
+	repo.WriteFile("openapi.yaml", "info:\n  version: 1.4.0\n")
+	if out := repo.RunError(runner, "verify"); !strings.Contains(out, "expected 1.3.0") {
+		t.Errorf("verify accepted a spec ahead of the release:\n%s", out)
+	}
+}
+
+func TestDevelopmentVersionNeedsAutoCommit(t *testing.T) {
+	runner := commettest.Build(t)
+	manual := strings.Replace(e2eConfig, "auto_commit = true", "auto_commit = false", 1)
+
+	tests := []struct {
+		name   string
+		config string
+		want   string
+	}{
+		{"snapshot", manual + "\n[snapshot]\nenabled = true\n", "snapshot.enabled needs git.auto_commit"},
+		{"post release version", strings.Replace(manual, "[git]\n", "[git]\npost_release_version = \"{next_patch}-dev\"\n", 1), "git.post_release_version needs git.auto_commit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := commettest.NewReleaseRepo(t, tt.config)
+			repo.Commit("Feature: add export")
+
+			if out := repo.RunError(runner); !strings.Contains(out, tt.want) {
+				t.Errorf("output = %q, want %q", out, tt.want)
+			}
+			repo.AssertFile("package.json", `{"version": "1.2.3"}`+"\n")
+			repo.AssertNoTag("v1.3.0")
+		})
+	}
+}
+
+func TestSummaryCommandRunsOnce(t *testing.T) {
+	runner := commettest.Build(t)
+
+	var notes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Notes string `json:"notes"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("failed to decode webhook payload: %v", err)
+		}
+		notes = append(notes, payload.Notes)
+	}))
+	defer server.Close()
+
+	runs := filepath.Join(t.TempDir(), "runs")
+	cfg := e2eConfig + fmt.Sprintf(`summary_command = "echo run >> '%s'; echo Exports arrive"
+
+[[changelog.outputs]]
+file = "RELEASES.md"
+
+[[notifications]]
+channel = "webhook"
+url = "%s"
+
+[[notifications]]
+channel = "webhook"
+url = "%s"
+`, filepath.ToSlash(runs), server.URL, server.URL)
+
+	repo := commettest.NewReleaseRepo(t, cfg)
+	repo.Commit("Feature: add export")
+
+	repo.Run(runner)
+
+	repo.AssertFileContains("CHANGELOG.md", "### Highlights\n\nExports arrive")
+	repo.AssertFileContains("RELEASES.md", "### Highlights\n\nExports arrive")
+	if len(notes) != 2 {
+		t.Fatalf("got %d notifications, want 2", len(notes))
+	}
+	for _, note := range notes {
+		if !strings.Contains(note, "Exports arrive") {
+			t.Errorf("notification lacks the summary:\n%s", note)
+		}
+	}
+
+	content, err := os.ReadFile(runs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count := strings.Count(string(content), "run"); count != 1 {
+		t.Errorf("summary_command ran %d times, want once", count)
+	}
+}
+
+func TestYankVPrefix(t *testing.T) {
+	runner := commettest.Build(t)
+
+	repo := commettest.NewRepo(t)
+	repo.WriteFile(".commet.toml", strings.Replace(e2eConfig, "[version]\n", "[version]\nformat = \"v-prefix\"\n", 1))
+	repo.WriteFile("package.json", `{"version": "v1.2.3"}`+"\n")
+	repo.Commit("Conf: initial")
+	repo.Tag("v1.2.3")
+	repo.Commit("Feature: add export")
+
+	repo.Run(runner)
+	repo.AssertFileContains("CHANGELOG.md", "## [v1.3.0]")
+
+	repo.Run(runner, "yank", "1.3.0", "--reason", "corrupts exports")
+	content := repo.ReadFile("CHANGELOG.md")
+	if !strings.Contains(content, "[YANKED]\n\n> **Yanked:** corrupts exports") || !strings.Contains(content, "## [v1.3.0]") {
+		t.Errorf("v1.3.0 is not marked as yanked:\n%s", content)
+	}
+}
+
+func TestYankChangesNothingOnFailure(t *testing.T) {
+	runner := commettest.Build(t)
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	t.Setenv("GITHUB_API_URL", server.URL)
+
+	repo := commettest.NewReleaseRepo(t, e2eConfig)
+	repo.WriteFile("go.mod", "module example.com/app\n\ngo 1.24\n")
+	repo.AddRemote("origin", "git@github.com:acme/widgets.git")
+	repo.Commit("Feature: add export")
+	repo.Run(runner)
+	changelog := repo.ReadFile("CHANGELOG.md")
+
+	out := repo.RunError(runner, "yank", "1.4.0", "--retract")
+	if !strings.Contains(out, "version 1.4.0 not found") {
+		t.Errorf("the missing version was not reported:\n%s", out)
+	}
+	repo.AssertFile("go.mod", "module example.com/app\n\ngo 1.24\n")
+
+	out = repo.RunError(runner, "yank", "1.3.0", "--retract", "--release")
+	if !strings.Contains(out, "failed to get release v1.3.0") {
+		t.Errorf("the missing release was not reported:\n%s", out)
+	}
+	repo.AssertFile("CHANGELOG.md", changelog)
+	repo.AssertFile("go.mod", "module example.com/app\n\ngo 1.24\n")
+}
+
+func TestReleaseNotesFile(t *testing.T) {
+	runner := commettest.Build(t)
+
+	t.Run("default file", func(t *testing.T) {
+		repo := commettest.NewRepo(t)
+		repo.WriteFile(".commet.toml", e2eConfig)
+		repo.WriteFile("package.json", `{"version": "1.2.3"}`+"\n")
+		repo.WriteFile("RELEASE_NOTES.md", "Hand-written notes\n")
+		repo.Commit("Conf: initial")
+		repo.Tag("v1.2.3")
+		repo.Commit("Feature(api): add export")
+
+		repo.Run(runner)
+		repo.AssertFileContains("CHANGELOG.md", "Hand-written notes")
+		repo.AssertFile("RELEASE_NOTES.md", "")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		repo := commettest.NewRepo(t)
+		repo.WriteFile(".commet.toml", e2eConfig+`release_notes_file = ""
+`)
+		repo.WriteFile("package.json", `{"version": "1.2.3"}`+"\n")
+		repo.WriteFile("RELEASE_NOTES.md", "Hand-written notes\n")
+		repo.Commit("Conf: initial")
+		repo.Tag("v1.2.3")
+		repo.Commit("Feature(api): add export")
+
+		repo.Run(runner)
+		repo.AssertFileContains("CHANGELOG.md", "add export")
+		repo.AssertFile("RELEASE_NOTES.md", "Hand-written notes\n")
+	})
+
+	t.Run("changelog command", func(t *testing.T) {
+		repo := commettest.NewRepo(t)
+		repo.WriteFile(".commet.toml", e2eConfig+`release_notes_file = "RELEASE_NOTES.md"
+release_notes_archive = "docs/releases"
+`)
+		repo.WriteFile("package.json", `{"version": "1.2.3"}`+"\n")
+		repo.WriteFile("RELEASE_NOTES.md", "Hand-written notes\n")
+		repo.Commit("Conf: initial")
+		repo.Tag("v1.2.3")
+		repo.Commit("Feature(api): add export")
+
+		output := repo.Run(runner, "changelog", "--stdout")
+		if !strings.Contains(output, "Hand-written notes") || strings.Contains(output, "add export") {
+			t.Errorf("changelog --stdout did not print the notes:\n%s", output)
+		}
+		repo.AssertFile("RELEASE_NOTES.md", "Hand-written notes\n")
+
+		repo.Run(runner, "changelog")
+		repo.AssertFileContains("CHANGELOG.md", "Hand-written notes")
+		repo.AssertFile("RELEASE_NOTES.md", "")
+		repo.AssertFile("docs/releases/1.2.3.md", "Hand-written notes\n")
+	})
+
+	t.Run("release pull request body", func(t *testing.T) {
+		repo := commettest.NewRepo(t)
+		repo.WriteFile(".commet.toml", e2eConfig+`release_notes_file = "RELEASE_NOTES.md"
+`)
+		repo.WriteFile("package.json", `{"version": "1.2.3"}`+"\n")
+		repo.WriteFile("RELEASE_NOTES.md", "Hand-written notes\n")
+		repo.Commit("Conf: initial")
+		repo.Tag("v1.2.3")
+		repo.Commit("Feature(api): add export")
+
+		output := repo.Run(runner, "release-pr", "--dry-run")
+		if !strings.Contains(output, "## [1.3.0]") || !strings.Contains(output, "Hand-written notes") || strings.Contains(output, "add export") {
+			t.Errorf("release-pr --dry-run did not use the notes as the body:\n%s", output)
+		}
+	})
+}
+
+func TestAggregate(t *testing.T) {
+	runner := commettest.Build(t)
+
+	release := func(commit string) *commettest.Repo {
+		repo := commettest.NewRepo(t)
+		repo.WriteFile(".commet.toml", e2eConfig)
+		repo.WriteFile("package.json", `{"version": "1.0.0"}`+"\n")
+		repo.Commit("Conf: initial")
+		repo.Tag("v1.0.0")
+		repo.Commit(commit)
+		repo.Run(runner)
+		return repo
+	}
+	api := release("Feature: add export")
+	web := release("Fix: handle nil")
+	web.Commit("Feature: unreleased work")
+
+	bulletin := commettest.NewRepo(t)
+	bulletin.WriteFile("repos.toml", fmt.Sprintf(`title = "Acme Platform"
+
+[[sources]]
+name = "api"
+path = %q
+
+[[sources]]
+name = "web"
+path = %q
+
+[[sources]]
+name = "missing"
+path = %q
+`, api.Dir, web.Dir, filepath.Join(t.TempDir(), "missing")))
+
+	out := bulletin.Run(runner, "aggregate", "--output", "BULLETIN.md")
+	if !strings.Contains(out, "[WARN] missing") {
+		t.Errorf("the missing source was not reported:\n%s", out)
+	}
+
+	content := bulletin.ReadFile("BULLETIN.md")
+	for _, want := range []string{"# Acme Platform", "## api 1.1.0", "### ✨ Features", "add export", "## web 1.0.1", "handle nil"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("bulletin is missing %q:\n%s", want, content)
+		}
+	}
+	if strings.Contains(content, "unreleased work") {
+		t.Errorf("bulletin contains unreleased commits:\n%s", content)
+	}
+
+	// Printed through color.Output, so --plain drops the emoji
+	out = bulletin.Run(runner, "aggregate", "--plain")
+	if !strings.Contains(out, "### Features") || strings.Contains(out, "✨") {
+		t.Errorf("aggregate --plain did not print plain notes:\n%s", out)
+	}
+}
+
+func TestChecklistBlocksRelease(t *testing.T) {
+	runner := commettest.Build(t)
+
+	repo := commettest.NewReleaseRepo(t, e2eConfig+`
+[checklist]
+tests = "exit 0"
+license = "echo 'GPL dependency found'; exit 1"
+`)
+	repo.Commit("Feature: add export")
+
+	out := repo.RunError(runner)
+	for _, want := range []string{"license", "FAILED", "GPL dependency found", "release blocked by failing checks: license"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+	repo.AssertFile("package.json", `{"version": "1.2.3"}`+"\n")
+	repo.AssertNoTag("v1.3.0")
+
+	repo.Run(runner, "--skip-checks", "license")
+	repo.AssertTag("v1.3.0")
+}
+
+func TestPackage(t *testing.T) {
+	runner := commettest.Build(t)
+
+	repo := commettest.NewReleaseRepo(t, e2eConfig+`
+[package]
+targets = ["linux/amd64", "windows/arm64"]
+binary = "tool"
+build_command = "echo {binary} {version} $GOOS/$GOARCH > {output}"
+files = ["LICENSE"]
+`)
+	repo.WriteFile("LICENSE", "MIT\n")
+
+	repo.Run(runner, "package")
+
+	checksums := repo.ReadFile("dist/checksums.txt")
+	for _, archive := range []string{"tool_1.2.3_linux_amd64.tar.gz", "tool_1.2.3_windows_arm64.zip"} {
+		if !strings.Contains(checksums, "  "+archive+"\n") {
+			t.Errorf("checksums.txt does not list %s:\n%s", archive, checksums)
+		}
+		if repo.ReadFile("dist/"+archive) == "" {
+			t.Errorf("%s is empty", archive)
+		}
+	}
+
+	// Only the tagged release is packaged
+	repo.WriteFile("package.json", `{"version": "1.2.4"}`+"\n")
+	if out := repo.RunError(runner, "package"); !strings.Contains(out, "uncommitted changes in package.json") {
+		t.Errorf("the uncommitted change was not reported:\n%s", out)
+	}
+	repo.Commit("Fix: bump by hand")
+	if out := repo.RunError(runner, "package"); !strings.Contains(out, "HEAD is not v1.2.3") {
+		t.Errorf("the untagged HEAD was not reported:\n%s", out)
+	}
+}
+
+func TestBuildNumberTemplates(t *testing.T) {
+	runner := commettest.Build(t)
+	t.Setenv("BUILD_NUMBER", "57")
+
+	cfg := strings.Replace(e2eConfig, `key = "version"`, "key = \"version\"\ntemplate = \"{version}+{build}\"", 1)
+	cfg = strings.Replace(cfg, `tag_format = "v{version}"`, `tag_format = "v{version}+build.{build}"`, 1)
+
+	repo := commettest.NewReleaseRepo(t, cfg)
+	repo.Commit("Feature: add export")
+
+	repo.Run(runner)
+
+	repo.AssertFile("package.json", `{"version": "1.3.0+57"}`+"\n")
+	repo.AssertTag("v1.3.0+build.57")
+}
+
+func TestRepoTemplateVars(t *testing.T) {
+	runner := commettest.Build(t)
+
+	cfg := strings.Replace(e2eConfig, `commit_message = "Conf: bump version to {version}"`, `commit_message = "Conf: release {owner}/{repo} {version} from {branch}"`, 1)
+
+	repo := commettest.NewRepo(t)
+	repo.WriteFile(".commet.toml", cfg+`board_url = "https://github.com/{owner}/{repo}/issues/{board}"
+`)
+	repo.WriteFile("package.json", `{"version": "1.2.3"}`+"\n")
+	repo.AddRemote("origin", "git@github.com:acme/widgets.git")
+	repo.Commit("Conf: initial")
+	repo.Tag("v1.2.3")
+	repo.Checkout("main")
+	repo.Commit("B-42: Feature add export")
+
+	repo.Run(runner)
+
+	if got, want := repo.HeadMessage(), "Conf: release acme/widgets 1.3.0 from main"; got != want {
+		t.Errorf("HEAD = %q, want %q", got, want)
+	}
+	repo.AssertFileContains("CHANGELOG.md", "https://github.com/acme/widgets/issues/B-42")
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/yendefrr/commet/internal/changelog"
+	"github.com/yendefrr/commet/internal/config"
+	"github.com/yendefrr/commet/internal/fsutil"
+	"github.com/yendefrr/commet/internal/updater"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var migrateScopeMap []string
+
+var migrateScopesCmd = &cobra.Command{
+	Use:   "migrate-scopes",
+	Short: "Rename commit scopes in the changelog and config",
+	Long: `Renames scopes after components were renamed: historical changelog lines
+("- **old**: ...") and "Type(old)" bump rules in the config file are rewritten
+so notes stay consistent going forward. Comments in the config are kept.`,
+	Example: `  commet migrate-scopes --map auth=identity --map db=storage`,
+	Args:    cobra.NoArgs,
+	RunE:    migrateScopes,
+}
+
+func init() {
+	rootCmd.AddCommand(migrateScopesCmd)
+
+	migrateScopesCmd.Flags().StringSliceVar(&migrateScopeMap, "map", nil, "scope rename as old=new (repeatable)")
+	migrateScopesCmd.MarkFlagRequired("map")
+}
+
+func migrateScopes(cmd *cobra.Command, args []string) error {
+	mapping, err := parseScopeMap(migrateScopeMap)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	backup := updater.NewBackup()
+
+	for _, target := range cfg.Changelog.Targets() {
+		if !fileExists(target.File) {
+			continue
+		}
+
+		content, err := os.ReadFile(target.File)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", target.File, err)
+		}
+
+		renamed, changed := changelog.RenameScopes(string(content), mapping)
+		if err := writeMigrated(backup, target.File, []byte(renamed), changed, "lines"); err != nil {
+			return rollback(backup, err)
+		}
+	}
+
+	configPath := cfgFile
+	if configPath == "" {
+		configPath = ".commet.toml"
+	}
+
+	if fileExists(configPath) {
+		data, err := os.ReadFile(configPath)
+		if err != nil {
+			return rollback(backup, fmt.Errorf("failed to read %s: %w", configPath, err))
+		}
+
+		renamed, changed, err := config.RenameRuleScopes(data, mapping)
+		if err != nil {
+			return rollback(backup, err)
+		}
+		if err := writeMigrated(backup, configPath, renamed, changed, "bump rules"); err != nil {
+			return rollback(backup, err)
+		}
+	}
+
+	if dryRun {
+		color.Yellow("No changes made (dry run mode)")
+	}
+
+	return nil
+}
+
+// parseScopeMap turns old=new pairs into a rename mapping.
+func parseScopeMap(pairs []string) (map[string]string, error) {
+	mapping := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		from, to, ok := strings.Cut(pair, "=")
+		from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+		if !ok || from == "" || to == "" {
+			return nil, fmt.Errorf("invalid --map %q: expected old=new", pair)
+		}
+		if strings.ContainsAny(from+to, ",()*") {
+			return nil, fmt.Errorf("invalid --map %q: scopes cannot contain , ( ) or *", pair)
+		}
+		mapping[from] = to
+	}
+	return mapping, nil
+}
+
+// writeMigrated saves path with its renamed content unless nothing changed or
+// this is a dry run.
+func writeMigrated(backup *updater.Backup, path string, content []byte, changed int, what string) error {
+	if changed == 0 {
+		color.Cyan("%s: nothing to rename", path)
+		return nil
+	}
+
+	if dryRun {
+		color.Yellow("[DRY RUN] Would rename scopes in %d %s of %s", changed, what, path)
+		return nil
+	}
+
+	if err := backup.Save(path); err != nil {
+		return err
+	}
+
+	if err := fsutil.WriteFile(path, content); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	color.Green("✓ Renamed scopes in %d %s of %s", changed, what, path)
+	return nil
+}
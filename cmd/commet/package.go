@@ -0,0 +1,212 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yendefrr/commet/internal/bundle"
+	"github.com/yendefrr/commet/internal/config"
+	"github.com/yendefrr/commet/internal/git"
+	"github.com/yendefrr/commet/internal/github"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var packageUpload bool
+
+var packageCmd = &cobra.Command{
+	Use:   "package [version]",
+	Short: "Build release archives for the configured targets",
+	Long: `Builds the [package] targets of a tagged release (the latest tag by default),
+archives each binary with the configured files under a templated name, writes
+a SHA-256 checksum file and, with --upload or package.upload, attaches
+everything to the GitHub release of the tag. The tag must be checked out
+without uncommitted changes, so the archives hold what was released.
+Uses GITHUB_TOKEN for authentication.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: packageRelease,
+}
+
+func init() {
+	rootCmd.AddCommand(packageCmd)
+
+	packageCmd.Flags().BoolVar(&packageUpload, "upload", false, "attach the archives to the GitHub release of the tag")
+}
+
+func packageRelease(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if len(cfg.Package.Targets) == 0 {
+		return fmt.Errorf("no targets configured: set package.targets, e.g. [\"linux/amd64\", \"darwin/arm64\"]")
+	}
+
+	gitClient, err := git.NewClient(".", cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize git: %w", err)
+	}
+
+	ver, tagName, err := packageVersion(cfg, gitClient, args)
+	if err != nil {
+		return err
+	}
+	if err := checkPackageTree(gitClient, tagName); err != nil {
+		return err
+	}
+
+	pkg := cfg.Package
+	if pkg.Binary == "" {
+		pkg.Binary, err = defaultBinaryName(pkg.Main)
+		if err != nil {
+			return err
+		}
+	}
+
+	targets := make([]bundle.Target, 0, len(pkg.Targets))
+	for _, t := range pkg.Targets {
+		target, err := bundle.ParseTarget(t)
+		if err != nil {
+			return err
+		}
+		targets = append(targets, target)
+	}
+
+	bundler := bundle.NewBundler(pkg, ver)
+	upload := packageUpload || pkg.Upload
+
+	if dryRun {
+		for _, target := range targets {
+			color.Yellow("[DRY RUN] Would build %s into %s", target, filepath.Join(pkg.Dist, bundler.ArchiveName(target)))
+		}
+		color.Yellow("[DRY RUN] Would write %s", filepath.Join(pkg.Dist, pkg.Checksum))
+		if upload {
+			color.Yellow("[DRY RUN] Would upload the archives to release %s", tagName)
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(pkg.Dist, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", pkg.Dist, err)
+	}
+
+	var artifacts []*bundle.Artifact
+	for _, target := range targets {
+		artifact, err := bundler.Build(target)
+		if err != nil {
+			return err
+		}
+		artifacts = append(artifacts, artifact)
+		color.Green("✓ Built %s", artifact.Path)
+	}
+
+	checksums, err := bundler.WriteChecksums(artifacts)
+	if err != nil {
+		return err
+	}
+	color.Green("✓ Wrote %s", checksums)
+
+	if !upload {
+		return nil
+	}
+
+	paths := []string{checksums}
+	for _, artifact := range artifacts {
+		paths = append(paths, artifact.Path)
+	}
+
+	return uploadReleaseAssets(gitClient, tagName, paths)
+}
+
+// packageVersion returns the version to package and its tag: the argument
+// when given, otherwise the latest release tag.
+func packageVersion(cfg *config.Config, gitClient *git.Client, args []string) (string, string, error) {
+	if len(args) == 1 {
+		ver := strings.TrimPrefix(args[0], "v")
+		tag, err := releaseTag(cfg, ver)
+		return ver, tag, err
+	}
+
+	tag, err := gitClient.GetLatestTag()
+	if err != nil {
+		return "", "", err
+	}
+	if tag == "" {
+		return "", "", fmt.Errorf("no release tag found: tag a release first or pass the version")
+	}
+
+	ver, err := gitClient.ExtractVersionFromTag(tag)
+	if err != nil {
+		return "", "", err
+	}
+
+	return ver, tag, nil
+}
+
+// checkPackageTree refuses to build from anything but the release: HEAD
+// must be the commit of tagName and tracked files must be unchanged.
+func checkPackageTree(gitClient *git.Client, tagName string) error {
+	tagged, err := gitClient.ResolveCommit(tagName)
+	if err != nil {
+		return err
+	}
+	head, err := gitClient.ResolveCommit("HEAD")
+	if err != nil {
+		return err
+	}
+	if head != tagged {
+		return fmt.Errorf("HEAD is not %s: check out the tag to package it", tagName)
+	}
+
+	dirty, err := gitClient.Dirty()
+	if err != nil {
+		return err
+	}
+	if len(dirty) > 0 {
+		return fmt.Errorf("uncommitted changes in %s: commit or stash them to package %s", strings.Join(dirty, ", "), tagName)
+	}
+
+	return nil
+}
+
+// defaultBinaryName names the binary after the main package's directory.
+func defaultBinaryName(main string) (string, error) {
+	dir, err := filepath.Abs(main)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", main, err)
+	}
+	return filepath.Base(dir), nil
+}
+
+// uploadReleaseAssets attaches paths to the GitHub release of tagName.
+func uploadReleaseAssets(gitClient *git.Client, tagName string, paths []string) error {
+	remote, err := gitClient.RemoteURL("origin")
+	if err != nil {
+		return err
+	}
+
+	owner, repo, err := github.ParseRepoURL(remote)
+	if err != nil {
+		return err
+	}
+
+	client := github.NewClient()
+
+	release, err := client.GetReleaseByTag(owner, repo, tagName)
+	if err != nil {
+		return fmt.Errorf("failed to get release %s: %w", tagName, err)
+	}
+
+	for _, path := range paths {
+		if err := client.UploadAsset(release, path); err != nil {
+			return fmt.Errorf("failed to upload %s: %w", filepath.Base(path), err)
+		}
+		color.Green("✓ Uploaded %s to %s", filepath.Base(path), tagName)
+	}
+
+	return nil
+}
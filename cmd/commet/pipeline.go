@@ -0,0 +1,427 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/yendefrr/commet/internal/config"
+	"github.com/yendefrr/commet/internal/git"
+	"github.com/yendefrr/commet/internal/parser"
+	"github.com/yendefrr/commet/internal/updater"
+	"github.com/yendefrr/commet/internal/version"
+
+	"github.com/fatih/color"
+)
+
+// release is the state of a release, handed from step to step.
+type release struct {
+	cfg        *config.Config
+	gitClient  *git.Client
+	calculator *version.Calculator
+
+	// line is the version line of a maintenance branch, e.g. "1.2"
+	line           string
+	currentVersion string
+	newVersion     string
+	bumpType       config.BumpType
+
+	commits       []*git.CommitInfo
+	parsedCommits []*parser.Commit
+	versionFiles  []config.VersionConfig
+	backup        *updater.Backup
+	updatedFiles  []string
+
+	// stamp receives the status lines of --stamp-file -
+	stamp io.Writer
+
+	// done ends the release after the current step, e.g. with nothing to bump
+	done bool
+}
+
+// step is one named stage of a release.
+type step struct {
+	name string
+	// mutates steps change files or the repository; --dry-run stops before the first
+	mutates bool
+	run     func(r *release) error
+}
+
+// releaseSteps are the steps of a release by name; pipeline.steps picks and
+// orders them.
+var releaseSteps = map[string]step{
+	"detect":    {"detect", false, detectStep},
+	"collect":   {"collect", false, collectStep},
+	"parse":     {"parse", false, parseStep},
+	"calculate": {"calculate", false, calculateStep},
+	"check":     {"check", false, checkStep},
+	"update":    {"update", true, updateStep},
+	"changelog": {"changelog", true, changelogStep},
+	"commit":    {"commit", true, commitStep},
+	"tag":       {"tag", true, tagStep},
+	"develop":   {"develop", true, developStep},
+	"publish":   {"publish", true, publishStep},
+	"notify":    {"notify", true, notifyStep},
+}
+
+// hook runs before or after a step; an error stops the release.
+type hook func(r *release) error
+
+// pipeline runs steps in order with the hooks registered around them.
+type pipeline struct {
+	steps  []step
+	before map[string][]hook
+	after  map[string][]hook
+	// preview reports what the mutating steps would do in a dry run
+	preview func(r *release)
+}
+
+// newPipeline builds the pipeline of pipeline.steps with the shell commands
+// of pipeline.hooks.
+func newPipeline(cfg config.PipelineConfig) *pipeline {
+	names := cfg.Steps
+	if len(names) == 0 {
+		names = config.PipelineSteps
+	}
+
+	p := &pipeline{before: make(map[string][]hook), after: make(map[string][]hook), preview: previewRelease}
+	for _, name := range names {
+		p.steps = append(p.steps, releaseSteps[name])
+	}
+
+	for name, command := range cfg.Hooks {
+		when, stepName, _ := strings.Cut(name, "_")
+		h := shellHook(name, command)
+		if when == "before" {
+			p.Before(stepName, h)
+		} else {
+			p.After(stepName, h)
+		}
+	}
+
+	return p
+}
+
+// Before registers h to run before the step name.
+func (p *pipeline) Before(name string, h hook) {
+	p.before[name] = append(p.before[name], h)
+}
+
+// After registers h to run after the step name.
+func (p *pipeline) After(name string, h hook) {
+	p.after[name] = append(p.after[name], h)
+}
+
+// Run runs the steps and their hooks until one fails or ends the release.
+// With --dry-run it previews the release instead of running the first
+// mutating step.
+func (p *pipeline) Run(r *release) error {
+	for _, s := range p.steps {
+		if s.mutates && dryRun {
+			p.preview(r)
+			return nil
+		}
+
+		if verbose {
+			color.Cyan("[STEP] %s", s.name)
+		}
+
+		for _, h := range p.before[s.name] {
+			if err := h(r); err != nil {
+				return err
+			}
+		}
+		if err := s.run(r); err != nil {
+			return err
+		}
+		if r.done {
+			return nil
+		}
+		for _, h := range p.after[s.name] {
+			if err := h(r); err != nil {
+				return err
+			}
+		}
+	}
+
+	if dryRun {
+		p.preview(r)
+	}
+	return nil
+}
+
+// shellHook runs command through the shell with the release in
+// COMMET_STEP, COMMET_PREVIOUS_VERSION, COMMET_VERSION and COMMET_BUMP. In a
+// dry run the command is only printed.
+func shellHook(name, command string) hook {
+	return func(r *release) error {
+		if dryRun {
+			color.Yellow("[DRY RUN] Would run hook %s: %s", name, command)
+			return nil
+		}
+
+		shell, flag := "sh", "-c"
+		if runtime.GOOS == "windows" {
+			shell, flag = "cmd", "/C"
+		}
+
+		cmd := exec.Command(shell, flag, command)
+		cmd.Env = append(os.Environ(),
+			"COMMET_STEP="+name,
+			"COMMET_PREVIOUS_VERSION="+r.currentVersion,
+			"COMMET_VERSION="+r.newVersion,
+			"COMMET_BUMP="+string(r.bumpType),
+		)
+		cmd.Stdout = color.Output
+		cmd.Stderr = os.Stderr
+
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("hook %s failed: %w", name, err)
+		}
+		color.Green("✓ Ran hook %s", name)
+		return nil
+	}
+}
+
+func detectStep(r *release) error {
+	var err error
+	if len(r.cfg.Branches) > 0 {
+		if r.line, err = applyBranchChannel(r.cfg, r.gitClient); err != nil {
+			return err
+		}
+	}
+
+	stop := phases.Start("tag scan")
+	r.currentVersion, err = detectVersion(r.gitClient, r.cfg)
+	stop()
+	if err != nil {
+		return fmt.Errorf("failed to detect current version: %w", err)
+	}
+
+	if verbose {
+		color.Cyan("[VERSION] Current: %s", r.currentVersion)
+	}
+	return nil
+}
+
+func collectStep(r *release) error {
+	var err error
+	stop := phases.Start("commit log")
+	r.commits, err = r.gitClient.GetCommits(fromRef, toRef)
+	stop()
+	if err != nil {
+		return fmt.Errorf("failed to get commits: %w", err)
+	}
+
+	if len(r.commits) == 0 && forceBump == "" {
+		color.Yellow("No commits found since %s", r.currentVersion)
+		r.done = true
+		return writeStampFile(r.stamp, stampFile, r.currentVersion)
+	}
+
+	if verbose {
+		color.Cyan("[GIT] Found %d commits", len(r.commits))
+	}
+	return nil
+}
+
+func parseStep(r *release) error {
+	stop := phases.Start("parse")
+	parsedCommits, err := parseStrictCommits(r.cfg, r.commits)
+	stop()
+
+	// Reclassifying may fix the commits detection.strict rejects
+	if interactive {
+		if !dryRun {
+			return fmt.Errorf("--interactive needs --dry-run: reclassify first, then release")
+		}
+		if err := reclassifyCommits(os.Stdin, r.cfg, r.commits); err != nil {
+			return err
+		}
+		parsedCommits, err = parseStrictCommits(r.cfg, r.commits)
+	}
+	if err != nil {
+		return err
+	}
+	r.parsedCommits = parsedCommits
+
+	if len(r.parsedCommits) == 0 && forceBump == "" {
+		color.Yellow("No valid commits found")
+		r.done = true
+		return writeStampFile(r.stamp, stampFile, r.currentVersion)
+	}
+	return nil
+}
+
+func calculateStep(r *release) error {
+	cfg := r.cfg
+	r.calculator = version.NewCalculator(cfg)
+
+	var err error
+	if forceBump != "" {
+		r.bumpType = cfg.ResolveBump(config.BumpType(forceBump))
+		r.newVersion, err = r.calculator.Apply(r.currentVersion, r.bumpType)
+		if verbose {
+			color.Cyan("[VERSION] Bump forced to %s, commit analysis skipped", r.bumpType)
+		}
+	} else {
+		r.newVersion, r.bumpType, err = r.calculator.Calculate(r.currentVersion, r.parsedCommits)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to calculate version: %w", err)
+	}
+
+	if r.line != "" && r.bumpType != config.BumpNone && !version.InLine(r.newVersion, r.line) {
+		return fmt.Errorf("%s is outside the %s line of this maintenance branch: only fixes can be released here", r.newVersion, r.line)
+	}
+
+	if err := checkVersionMax(cfg, r.currentVersion, r.newVersion, r.bumpType); err != nil {
+		return err
+	}
+
+	if err := enforceBoardPolicy(cfg, r.gitClient, r.calculator, r.parsedCommits); err != nil {
+		return err
+	}
+
+	if r.bumpType == config.BumpMajor && forceBump == "" {
+		if err := confirmMajor(cfg, r.calculator, r.parsedCommits, r.newVersion); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Version.BuildMetadata != "" && r.bumpType != config.BumpNone {
+		sha, err := r.gitClient.ShortHash(toRef)
+		if err != nil {
+			return err
+		}
+
+		metadata, err := version.BuildMetadata(cfg.Version.BuildMetadata, sha)
+		if err != nil {
+			return err
+		}
+
+		if r.newVersion, err = r.calculator.WithMetadata(r.newVersion, metadata); err != nil {
+			return err
+		}
+	}
+
+	if r.bumpType == config.BumpNone {
+		color.Green("No version bump needed (current: %s)", r.currentVersion)
+		r.done = true
+		return writeStampFile(r.stamp, stampFile, r.currentVersion)
+	}
+	return nil
+}
+
+func checkStep(r *release) error {
+	if err := runChecklist(r.cfg, r.newVersion); err != nil {
+		return err
+	}
+
+	if err := writeStampFile(r.stamp, stampFile, r.newVersion); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(color.Output)
+	color.Green("Current version: %s", r.currentVersion)
+	color.Green("Next version:    %s", r.newVersion)
+	color.Green("Bump type:       %s", strings.ToUpper(string(r.bumpType)))
+	fmt.Fprintln(color.Output)
+
+	var err error
+	r.versionFiles, err = releaseVersionFiles(r.cfg, r.gitClient, r.commits)
+	return err
+}
+
+// previewRelease prints what the rest of the release would change.
+func previewRelease(r *release) {
+	cfg := r.cfg
+
+	color.Yellow("Files to update:")
+	for _, versionFile := range r.versionFiles {
+		color.Yellow("  - %s (%s)", versionFile.File, versionFile.Location())
+	}
+	fmt.Fprintln(color.Output)
+	for _, versionFile := range r.versionFiles {
+		printFileDiff(versionFile, r.newVersion)
+	}
+	if devVersion, _, err := nextDevelopmentVersion(cfg, r.calculator, r.newVersion); err == nil && devVersion != "" {
+		color.Yellow("Next development version: %s", devVersion)
+		fmt.Fprintln(color.Output)
+	}
+	if cfg.Git.AutoTag && cfg.Git.AliasTags {
+		for _, alias := range version.Aliases(r.newVersion) {
+			if tagName, err := releaseTag(cfg, alias); err == nil {
+				color.Yellow("Would move tag: %s", tagName)
+			}
+		}
+	}
+	if cfg.Git.AutoPush {
+		color.Yellow("Would push to: %s", cfg.Git.PushRemote)
+	}
+	for _, channel := range cfg.Notifications {
+		color.Yellow("Would notify: %s", channel.Channel)
+	}
+	color.Yellow("No changes made (dry run mode)")
+}
+
+func updateStep(r *release) error {
+	r.backup = updater.NewBackup()
+
+	stop := phases.Start("update")
+	updatedFiles, err := updateVersionFiles(r.versionFiles, r.backup, r.newVersion)
+	stop()
+	if err != nil {
+		return rollback(r.backup, err)
+	}
+
+	r.updatedFiles = append(r.updatedFiles, updatedFiles...)
+	return nil
+}
+
+func changelogStep(r *release) error {
+	if r.backup == nil {
+		r.backup = updater.NewBackup()
+	}
+
+	stop := phases.Start("changelog")
+	written, err := writeReleaseChangelogs(r.cfg, r.backup, r.newVersion, r.parsedCommits)
+	stop()
+	if err != nil {
+		return rollback(r.backup, err)
+	}
+
+	r.updatedFiles = append(r.updatedFiles, written...)
+	return nil
+}
+
+func commitStep(r *release) error {
+	return commitRelease(r.cfg, r.gitClient, r.updatedFiles, r.newVersion)
+}
+
+func tagStep(r *release) error {
+	return tagRelease(r.cfg, r.gitClient, r.newVersion)
+}
+
+func developStep(r *release) error {
+	devVersion, devMessage, err := nextDevelopmentVersion(r.cfg, r.calculator, r.newVersion)
+	if err != nil {
+		return err
+	}
+	if devVersion == "" {
+		return nil
+	}
+	return openDevelopmentCycle(r.cfg, r.gitClient, r.versionFiles, devVersion, devMessage)
+}
+
+func publishStep(r *release) error {
+	return pushRelease(r.cfg, r.gitClient, r.newVersion)
+}
+
+func notifyStep(r *release) error {
+	notifyRelease(r.cfg, r.newVersion, r.parsedCommits)
+	return nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPipeline(t *testing.T) {
+	var ran []string
+	record := func(name string, mutates bool) step {
+		return step{name: name, mutates: mutates, run: func(r *release) error {
+			ran = append(ran, name)
+			return nil
+		}}
+	}
+	hookFor := func(name string, err error) hook {
+		return func(r *release) error {
+			ran = append(ran, name)
+			return err
+		}
+	}
+
+	newTestPipeline := func() *pipeline {
+		p := &pipeline{
+			steps:   []step{record("calculate", false), record("tag", true)},
+			before:  make(map[string][]hook),
+			after:   make(map[string][]hook),
+			preview: func(r *release) { ran = append(ran, "preview") },
+		}
+		p.After("calculate", hookFor("after calculate", nil))
+		p.Before("tag", hookFor("before tag", nil))
+		return p
+	}
+
+	tests := []struct {
+		name    string
+		dryRun  bool
+		setup   func(p *pipeline)
+		want    []string
+		wantErr bool
+	}{
+		{"runs steps and hooks in order", false, nil, []string{"calculate", "after calculate", "before tag", "tag"}, false},
+		{"dry run previews before mutating steps", true, nil, []string{"calculate", "after calculate", "preview"}, false},
+		{"failing hook stops the release", false, func(p *pipeline) {
+			p.Before("tag", hookFor("failing hook", errors.New("boom")))
+		}, []string{"calculate", "after calculate", "before tag", "failing hook"}, true},
+		{"done ends the release", false, func(p *pipeline) {
+			p.steps[0].run = func(r *release) error {
+				ran = append(ran, "nothing to release")
+				r.done = true
+				return nil
+			}
+		}, []string{"nothing to release"}, false},
+	}
+
+	t.Cleanup(func() { dryRun = false })
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ran = nil
+			dryRun = tt.dryRun
+
+			p := newTestPipeline()
+			if tt.setup != nil {
+				tt.setup(p)
+			}
+
+			err := p.Run(&release{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(ran, tt.want) {
+				t.Errorf("ran %v, want %v", ran, tt.want)
+			}
+		})
+	}
+}
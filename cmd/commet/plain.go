@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// plainWidth bounds the length of plain output lines; longer lines wrap.
+const plainWidth = 100
+
+var plain bool
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&plain, "plain", false, "plain output for screen readers and logs: no color, emoji or box drawing, PASS/FAIL/WARN prefixes")
+
+	cobra.OnInitialize(func() {
+		if !plain {
+			return
+		}
+		color.NoColor = true
+		color.Output = &plainWriter{w: os.Stdout}
+		color.Error = &plainWriter{w: os.Stderr}
+		rootCmd.SetErrPrefix("FAIL:")
+	})
+}
+
+// plainStatus maps the symbols opening status lines to explicit prefixes.
+var plainStatus = []struct {
+	symbol string
+	prefix string
+}{
+	{"✓ ", "PASS: "},
+	{"✗ ", "FAIL: "},
+	{"↺ ", "UNDO: "},
+	{"[WARN] ", "WARN: "},
+	{"[ERROR] ", "FAIL: "},
+}
+
+// plainSymbols replaces arrows and box drawing with ASCII.
+var plainSymbols = strings.NewReplacer(
+	"→", "->",
+	"←", "<-",
+	"│", "|",
+	"─", "-",
+	"…", "...",
+	"•", "*",
+)
+
+// plainWriter rewrites every line written through it with plainLine.
+type plainWriter struct {
+	w io.Writer
+}
+
+func (p *plainWriter) Write(b []byte) (int, error) {
+	lines := strings.Split(string(b), "\n")
+	for i, line := range lines {
+		lines[i] = plainLine(line)
+	}
+	if _, err := io.WriteString(p.w, strings.Join(lines, "\n")); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// plainLine turns status symbols into PASS/FAIL/WARN prefixes, replaces
+// arrows and box drawing with ASCII, drops emoji and wraps the line at
+// plainWidth, indenting the continuation lines.
+func plainLine(line string) string {
+	indent := line[:len(line)-len(strings.TrimLeft(line, " "))]
+	text := line[len(indent):]
+	for _, status := range plainStatus {
+		if strings.HasPrefix(text, status.symbol) {
+			text = status.prefix + strings.TrimPrefix(text, status.symbol)
+			break
+		}
+	}
+	text = stripEmoji(plainSymbols.Replace(text))
+
+	if utf8.RuneCountInString(indent+text) <= plainWidth {
+		return indent + text
+	}
+
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		// A long run of blanks is a blank line
+		return ""
+	}
+	var wrapped []string
+	current := indent + words[0]
+	for _, word := range words[1:] {
+		if utf8.RuneCountInString(current)+1+utf8.RuneCountInString(word) > plainWidth {
+			wrapped = append(wrapped, current)
+			current = indent + "  " + word
+			continue
+		}
+		current += " " + word
+	}
+
+	return strings.Join(append(wrapped, current), "\n")
+}
+
+// stripEmoji drops pictographs, with the variation selectors and joiners
+// that compose them and the space after them.
+func stripEmoji(s string) string {
+	var sb strings.Builder
+	skipSpace := false
+	for _, r := range s {
+		switch {
+		case unicode.Is(unicode.So, r), r == '\uFE0F', r == '\u200D':
+			skipSpace = true
+			continue
+		case r == ' ' && skipSpace:
+			skipSpace = false
+			continue
+		}
+		skipSpace = false
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// table aligns columns like tabwriter and writes the aligned rows through
+// color.Output in one piece on Flush, so --plain sees whole lines.
+type table struct {
+	*tabwriter.Writer
+	rows bytes.Buffer
+}
+
+func newTable() *table {
+	t := &table{}
+	t.Writer = tabwriter.NewWriter(&t.rows, 0, 0, 2, ' ', 0)
+	return t
+}
+
+func (t *table) Flush() error {
+	if err := t.Writer.Flush(); err != nil {
+		return err
+	}
+	_, err := color.Output.Write(t.rows.Bytes())
+	t.rows.Reset()
+	return err
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPlainLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"✓ Created tag: v1.2.0", "PASS: Created tag: v1.2.0"},
+		{"✗ package.json 1.1.0 (expected 1.2.0)", "FAIL: package.json 1.1.0 (expected 1.2.0)"},
+		{"[WARN] Failed to parse: wip", "WARN: Failed to parse: wip"},
+		{"Version updated: 1.1.0 → 1.2.0", "Version updated: 1.1.0 -> 1.2.0"},
+		{"### ✨ Features", "### Features"},
+		{"### ⚠️ Migration Notes", "### Migration Notes"},
+		{"left │ right", "left | right"},
+		{"  Fix: typo → PATCH", "  Fix: typo -> PATCH"},
+	}
+
+	for _, tt := range tests {
+		if got := plainLine(tt.line); got != tt.want {
+			t.Errorf("plainLine(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+
+	if got := plainLine(strings.Repeat(" ", plainWidth+20)); got != "" {
+		t.Errorf("plainLine() of a long blank line = %q, want it empty", got)
+	}
+
+	long := "✓ Pushed " + strings.Repeat("word ", 40)
+	wrapped := strings.Split(plainLine(long), "\n")
+	if len(wrapped) < 2 || !strings.HasPrefix(wrapped[0], "PASS: ") || !strings.HasPrefix(wrapped[1], "  word") {
+		t.Fatalf("plainLine() did not wrap the long line: %q", wrapped)
+	}
+	for _, line := range wrapped {
+		if len(line) > plainWidth {
+			t.Errorf("wrapped line is %d characters, want at most %d", len(line), plainWidth)
+		}
+	}
+}
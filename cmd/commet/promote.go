@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yendefrr/commet/internal/config"
+	"github.com/yendefrr/commet/internal/git"
+	"github.com/yendefrr/commet/internal/updater"
+	"github.com/yendefrr/commet/internal/version"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var promoteCmd = &cobra.Command{
+	Use:   "promote",
+	Short: "Promote the current pre-release to a stable release",
+	Long: `Turns the current pre-release (e.g. 2.0.0-rc.3) into the stable release (2.0.0):
+version files are updated, the changelog entry collects every commit since the
+last stable tag, and the release is committed and tagged as configured.`,
+	Args: cobra.NoArgs,
+	RunE: promote,
+}
+
+func init() {
+	rootCmd.AddCommand(promoteCmd)
+}
+
+func promote(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if !git.IsGitRepository(".") {
+		return fmt.Errorf("not a git repository")
+	}
+
+	gitClient, err := git.NewClient(".", cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize git: %w", err)
+	}
+	expandRepoVars(cfg, gitClient)
+
+	currentVersion, err := detectVersion(gitClient, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to detect current version: %w", err)
+	}
+
+	calculator := version.NewCalculator(cfg)
+	newVersion, err := calculator.Promote(currentVersion)
+	if err != nil {
+		return err
+	}
+
+	stableTag, err := lastStableTag(cfg, gitClient, newVersion)
+	if err != nil {
+		return err
+	}
+
+	commits, err := gitClient.Log(stableTag, toRef)
+	if err != nil {
+		return fmt.Errorf("failed to get commits: %w", err)
+	}
+	parsedCommits := parseReleaseCommits(cfg, commits)
+
+	since := stableTag
+	if since == "" {
+		since = "the first commit"
+	}
+
+	fmt.Println()
+	color.Green("Current version: %s", currentVersion)
+	color.Green("Next version:    %s", newVersion)
+	color.Green("Commits:         %d since %s", len(parsedCommits), since)
+	fmt.Println()
+
+	versionFiles := cfg.GetVersionFiles()
+
+	if dryRun {
+		color.Yellow("Files to update:")
+		for _, versionFile := range versionFiles {
+			color.Yellow("  - %s (%s)", versionFile.File, versionFile.Location())
+		}
+		fmt.Println()
+		for _, versionFile := range versionFiles {
+			printFileDiff(versionFile, newVersion)
+		}
+		color.Yellow("No changes made (dry run mode)")
+		return nil
+	}
+
+	backup := updater.NewBackup()
+	updatedFiles, err := updateVersionFiles(versionFiles, backup, newVersion)
+	if err != nil {
+		return rollback(backup, err)
+	}
+
+	written, err := writeReleaseChangelogs(cfg, backup, newVersion, parsedCommits)
+	if err != nil {
+		return rollback(backup, err)
+	}
+	updatedFiles = append(updatedFiles, written...)
+
+	if err := commitAndTag(cfg, gitClient, updatedFiles, newVersion); err != nil {
+		return err
+	}
+
+	notifyRelease(cfg, newVersion, parsedCommits)
+
+	fmt.Println()
+	color.Green("Version promoted: %s → %s", currentVersion, newVersion)
+
+	return nil
+}
+
+// lastStableTag returns the newest tag of a stable release older than ver,
+// or "" when there is none.
+func lastStableTag(cfg *config.Config, gitClient *git.Client, ver string) (string, error) {
+	target, err := semver.NewVersion(strings.TrimPrefix(ver, "v"))
+	if err != nil {
+		return "", fmt.Errorf("invalid version %s: %w", ver, err)
+	}
+
+	tags, err := gitClient.Tags()
+	if err != nil {
+		return "", err
+	}
+
+	for _, tag := range sortVersionTags(cfg, tags) {
+		v, err := git.ExtractVersion(cfg.Detection.TagPattern, tag)
+		if err != nil {
+			continue
+		}
+
+		parsed, err := semver.NewVersion(strings.TrimPrefix(v, "v"))
+		if err != nil || parsed.Prerelease() != "" {
+			continue
+		}
+
+		if parsed.LessThan(target) {
+			return tag, nil
+		}
+	}
+
+	return "", nil
+}
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yendefrr/commet/internal/changelog"
+	"github.com/yendefrr/commet/internal/config"
+	"github.com/yendefrr/commet/internal/forge"
+	"github.com/yendefrr/commet/internal/git"
+	"github.com/yendefrr/commet/internal/parser"
+	"github.com/yendefrr/commet/internal/updater"
+	"github.com/yendefrr/commet/internal/version"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// releasePRMarker opens the body of release pull requests, so they can be
+// told apart from pull requests written by hand.
+const releasePRMarker = "<!-- commet:release-pr -->"
+
+var releasePRCmd = &cobra.Command{
+	Use:   "release-pr",
+	Short: "Keep a pull request with the pending release up to date",
+	Long: `Run after every push to the release branch, e.g. main. commet commits the
+pending version bump and changelog to release_pr.branch (commet/release),
+force-pushes it to origin and opens or updates a pull request from it.
+
+Once that pull request is merged, the next run finds the released version in
+the version file and tags it, pushing the tag to origin.
+
+GitHub uses GITHUB_TOKEN and GitLab GITLAB_TOKEN for authentication.`,
+	Args: cobra.NoArgs,
+	RunE: releasePR,
+}
+
+func init() {
+	rootCmd.AddCommand(releasePRCmd)
+}
+
+func releasePR(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if !git.IsGitRepository(".") {
+		return fmt.Errorf("not a git repository")
+	}
+
+	gitClient, err := git.NewClient(".", cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize git: %w", err)
+	}
+	expandRepoVars(cfg, gitClient)
+	dateChangelog(cfg, gitClient)
+
+	merged, previousTag, err := mergedRelease(cfg, gitClient)
+	if err != nil {
+		return err
+	}
+	if merged != "" {
+		return tagMergedRelease(cfg, gitClient, merged, previousTag)
+	}
+
+	currentVersion, err := detectVersion(gitClient, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to detect current version: %w", err)
+	}
+
+	commits, err := gitClient.GetCommits(fromRef, toRef)
+	if err != nil {
+		return fmt.Errorf("failed to get commits: %w", err)
+	}
+	parsedCommits, err := parseStrictCommits(cfg, commits)
+	if err != nil {
+		return err
+	}
+
+	newVersion, bumpType, err := version.NewCalculator(cfg).Calculate(currentVersion, parsedCommits)
+	if err != nil {
+		return fmt.Errorf("failed to calculate version: %w", err)
+	}
+	if bumpType == config.BumpNone {
+		color.Green("No release pending (current: %s)", currentVersion)
+		return nil
+	}
+
+	notes, err := changelog.ReadReleaseNotes(cfg.Changelog.ReleaseNotesFile)
+	if err != nil {
+		return err
+	}
+	entry, err := changelogEntry(cfg.Changelog, newVersion, notes, parsedCommits)
+	if err != nil {
+		return fmt.Errorf("failed to render changelog: %w", err)
+	}
+
+	title := strings.ReplaceAll(cfg.ReleasePR.Title, "{version}", newVersion)
+	body := releasePRBody(newVersion, bumpType, entry)
+	branch := cfg.ReleasePR.Branch
+
+	fmt.Println()
+	color.Green("Current version: %s", currentVersion)
+	color.Green("Next version:    %s", newVersion)
+	color.Green("Bump type:       %s", strings.ToUpper(string(bumpType)))
+	fmt.Println()
+
+	if dryRun {
+		color.Yellow("Would push %s and open or update the pull request %q:", branch, title)
+		fmt.Fprintln(color.Output, body)
+		color.Yellow("No changes made (dry run mode)")
+		return nil
+	}
+
+	remote, err := gitClient.RemoteURL("origin")
+	if err != nil {
+		return err
+	}
+	hub, err := forge.New(cfg.ReleasePR.Forge, remote)
+	if err != nil {
+		return err
+	}
+
+	base := cfg.ReleasePR.Base
+	if base == "" {
+		if base, err = gitClient.CurrentBranch(); err != nil {
+			return err
+		}
+	}
+
+	if err := commitReleaseBranch(cfg, gitClient, commits, parsedCommits, newVersion); err != nil {
+		return err
+	}
+
+	if err := gitClient.Push("origin", true, "refs/heads/"+branch); err != nil {
+		return err
+	}
+	color.Green("✓ Pushed %s", branch)
+
+	pr, err := hub.FindPullRequest(branch)
+	if err != nil {
+		return fmt.Errorf("failed to find the release pull request on %s: %w", hub.Name(), err)
+	}
+
+	if pr == nil {
+		if pr, err = hub.CreatePullRequest(branch, base, title, body); err != nil {
+			return fmt.Errorf("failed to open the release pull request on %s: %w", hub.Name(), err)
+		}
+		color.Green("✓ Opened release pull request #%d: %s", pr.Number, pr.URL)
+		return nil
+	}
+
+	if !strings.HasPrefix(pr.Body, releasePRMarker) {
+		return fmt.Errorf("pull request #%d from %s was not opened by commet release-pr: close it or change release_pr.branch", pr.Number, branch)
+	}
+
+	if pr.Title == title && pr.Body == body {
+		color.Green("✓ Release pull request #%d is up to date", pr.Number)
+		return nil
+	}
+
+	pr.Title, pr.Body = title, body
+	if err := hub.UpdatePullRequest(pr); err != nil {
+		return fmt.Errorf("failed to update the release pull request on %s: %w", hub.Name(), err)
+	}
+	color.Green("✓ Updated release pull request #%d: %s", pr.Number, pr.URL)
+
+	return nil
+}
+
+// releasePRBody is the description of the release pull request: the marker,
+// a short explanation and the changelog entry, or the hand-written release
+// notes when there are any.
+func releasePRBody(ver string, bump config.BumpType, entry string) string {
+	return fmt.Sprintf("%s\nMerging this pull request releases **%s** (%s bump). It is kept up to date by `commet release-pr`.\n\n%s",
+		releasePRMarker, ver, bump, strings.TrimSpace(entry))
+}
+
+// commitReleaseBranch resets release_pr.branch to HEAD and commits the
+// version files and changelogs of ver to it, then returns to the branch or
+// commit checked out before.
+func commitReleaseBranch(cfg *config.Config, gitClient *git.Client, commits []*git.CommitInfo, parsedCommits []*parser.Commit, ver string) error {
+	dirty, err := gitClient.Dirty()
+	if err != nil {
+		return err
+	}
+	if len(dirty) > 0 {
+		return fmt.Errorf("uncommitted changes in %s: commit or stash them first", strings.Join(dirty, ", "))
+	}
+
+	original, err := gitClient.Head()
+	if err != nil {
+		return err
+	}
+
+	versionFiles, err := releaseVersionFiles(cfg, gitClient, commits)
+	if err != nil {
+		return err
+	}
+
+	if err := gitClient.ResetBranch(cfg.ReleasePR.Branch); err != nil {
+		return err
+	}
+
+	backup := updater.NewBackup()
+	updatedFiles, err := updateVersionFiles(versionFiles, backup, ver)
+	if err == nil {
+		var written []string
+		written, err = writeReleaseChangelogs(cfg, backup, ver, parsedCommits)
+		updatedFiles = append(updatedFiles, written...)
+	}
+	if err == nil {
+		commitMsg := strings.ReplaceAll(cfg.Git.CommitMessage, "{version}", ver)
+		if err = gitClient.CreateCommit(updatedFiles, commitMsg); err == nil {
+			color.Green("✓ Created commit on %s: %s", cfg.ReleasePR.Branch, commitMsg)
+		}
+	}
+	if err != nil {
+		err = rollback(backup, err)
+	}
+
+	if checkoutErr := gitClient.Checkout(original); checkoutErr != nil && err == nil {
+		err = checkoutErr
+	}
+
+	return err
+}
+
+// mergedRelease returns the version a merged release pull request left in
+// the version file, with the latest tag before it: HEAD changed the version
+// file, and its version is not tagged and higher than every tag. It returns
+// an empty version otherwise.
+func mergedRelease(cfg *config.Config, gitClient *git.Client) (string, string, error) {
+	if !fileExists(cfg.Version.File) {
+		return "", "", nil
+	}
+
+	changed, err := gitClient.ChangedFiles("HEAD")
+	if err != nil {
+		return "", "", err
+	}
+	if !containsFile(changed, cfg.Version.File) {
+		return "", "", nil
+	}
+
+	fileUpdater, err := newFileUpdater(cfg.Version)
+	if err != nil {
+		return "", "", err
+	}
+	released, err := fileUpdater.GetVersion(cfg.Version.Key)
+	if err != nil || released == "" {
+		return "", "", nil
+	}
+
+	scheme := version.SchemeFor(cfg)
+	if _, err := scheme.Parse(released); err != nil {
+		return "", "", nil
+	}
+
+	tagName, err := releaseTag(cfg, released)
+	if err != nil {
+		return "", "", err
+	}
+
+	tags, err := gitClient.Tags()
+	if err != nil {
+		return "", "", err
+	}
+	for _, tag := range tags {
+		if tag == tagName {
+			return "", "", nil
+		}
+	}
+
+	latest, err := gitClient.GetLatestTag()
+	if err != nil {
+		return "", "", err
+	}
+	if latest != "" {
+		latestVersion, err := gitClient.ExtractVersionFromTag(latest)
+		if err != nil {
+			return "", "", err
+		}
+		if result, err := scheme.Compare(released, latestVersion); err != nil || result <= 0 {
+			return "", "", nil
+		}
+	}
+
+	return released, latest, nil
+}
+
+// tagMergedRelease tags HEAD as ver, moves the alias tags, pushes them to
+// origin and sends the release notifications.
+func tagMergedRelease(cfg *config.Config, gitClient *git.Client, ver, previousTag string) error {
+	tagName, err := releaseTag(cfg, ver)
+	if err != nil {
+		return err
+	}
+
+	commits, err := gitClient.Log(previousTag, "HEAD")
+	if err != nil {
+		return fmt.Errorf("failed to get commits: %w", err)
+	}
+	parsedCommits := parseReleaseCommits(cfg, commits)
+
+	color.Green("Release pull request merged: %s", ver)
+
+	if dryRun {
+		color.Yellow("Would tag HEAD as %s and push the tag", tagName)
+		color.Yellow("No changes made (dry run mode)")
+		return nil
+	}
+
+	tagMsg := strings.ReplaceAll(cfg.Git.TagMessage, "{version}", ver)
+	if err := gitClient.CreateTag(tagName, tagMsg); err != nil {
+		return fmt.Errorf("failed to create tag: %w", err)
+	}
+	color.Green("✓ Created tag: %s", tagName)
+
+	if cfg.Git.AliasTags {
+		if err := moveAliasTags(gitClient, cfg.Git.TagFormat, ver); err != nil {
+			return err
+		}
+	}
+
+	if err := pushReleaseTags(cfg, gitClient, "origin", ver); err != nil {
+		return err
+	}
+
+	notifyRelease(cfg, ver, parsedCommits)
+
+	return nil
+}
+
+// containsFile reports whether files lists path, ignoring a leading "./".
+func containsFile(files []string, path string) bool {
+	path = strings.TrimPrefix(path, "./")
+	for _, file := range files {
+		if file == path {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yendefrr/commet/internal/config"
+	"github.com/yendefrr/commet/internal/git"
+	"github.com/yendefrr/commet/internal/github"
+	"github.com/yendefrr/commet/internal/version"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	scanOrg          string
+	scanPendingOnly  bool
+	scanWithArchived bool
+)
+
+var scanCmd = &cobra.Command{
+	Use:   "scan",
+	Short: "Report pending releases across a GitHub organization",
+	Long: `Lists every repository of a GitHub organization, compares its latest version tag
+with the default branch and prints a summary of the releases waiting to happen.
+Uses GITHUB_TOKEN for authentication when set.`,
+	RunE: scanOrganization,
+}
+
+func init() {
+	rootCmd.AddCommand(scanCmd)
+
+	scanCmd.Flags().StringVar(&scanOrg, "org", "", "GitHub organization to scan")
+	scanCmd.Flags().BoolVar(&scanPendingOnly, "pending", false, "only list repositories with a pending release")
+	scanCmd.Flags().BoolVar(&scanWithArchived, "archived", false, "include archived repositories")
+}
+
+func scanOrganization(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if scanOrg == "" {
+		return fmt.Errorf("--org is required")
+	}
+
+	client := github.NewClient()
+
+	repos, err := client.ListOrgRepos(scanOrg)
+	if err != nil {
+		return fmt.Errorf("failed to list repositories: %w", err)
+	}
+
+	if verbose {
+		color.Cyan("[GITHUB] Found %d repositories in %s", len(repos), scanOrg)
+	}
+
+	writer := newTable()
+	fmt.Fprintln(writer, "REPOSITORY\tCURRENT\tCOMMITS\tBUMP\tNEXT")
+
+	pending := 0
+	for _, repo := range repos {
+		if repo.Archived && !scanWithArchived {
+			continue
+		}
+
+		row, isPending := scanRepository(cfg, client, repo)
+		if isPending {
+			pending++
+		} else if scanPendingOnly {
+			continue
+		}
+
+		fmt.Fprintln(writer, row)
+	}
+
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Println()
+	color.Green("%d release(s) waiting to happen", pending)
+
+	return nil
+}
+
+// scanRepository returns the summary table row for repo and whether it has a pending release.
+func scanRepository(cfg *config.Config, client *github.Client, repo *github.Repository) (string, bool) {
+	tags, err := client.ListTags(scanOrg, repo.Name)
+	if err != nil {
+		return fmt.Sprintf("%s\t-\t-\terror\t%v", repo.Name, err), false
+	}
+
+	latestTag := latestVersionTag(cfg, tags)
+	if latestTag == "" {
+		return fmt.Sprintf("%s\t-\t-\t-\tno version tag", repo.Name), false
+	}
+
+	result, err := projectComparison(cfg, client, &github.Comparison{
+		Owner: scanOrg,
+		Repo:  repo.Name,
+		Base:  latestTag,
+		Head:  repo.DefaultBranch,
+	})
+	if err != nil {
+		return fmt.Sprintf("%s\t%s\t-\terror\t%v", repo.Name, latestTag, err), false
+	}
+
+	if result.bumpType == config.BumpNone {
+		return fmt.Sprintf("%s\t%s\t%d\tnone\t-", repo.Name, result.currentVersion, result.commits), false
+	}
+
+	return fmt.Sprintf("%s\t%s\t%d\t%s\t%s", repo.Name, result.currentVersion, result.commits,
+		strings.ToUpper(string(result.bumpType)), result.nextVersion), true
+}
+
+// latestVersionTag returns the tag with the highest version matching the tag pattern.
+func latestVersionTag(cfg *config.Config, tags []string) string {
+	scheme := version.SchemeFor(cfg)
+	latestTag, latestVersion := "", ""
+	for _, tag := range tags {
+		v, err := git.ExtractVersion(cfg.Detection.TagPattern, tag)
+		if err != nil {
+			continue
+		}
+
+		if latestVersion == "" {
+			latestTag, latestVersion = tag, v
+			continue
+		}
+
+		if result, err := scheme.Compare(v, latestVersion); err == nil && result > 0 {
+			latestTag, latestVersion = tag, v
+		}
+	}
+
+	return latestTag
+}
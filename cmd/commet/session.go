@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/yendefrr/commet/internal/config"
+	"github.com/yendefrr/commet/internal/git"
+
+	"github.com/fatih/color"
+)
+
+// sessionFile keeps the reclassifications of an interactive dry run for the
+// next release, keyed by full commit hash, in the same "Key: value" form as
+// commet notes. It is removed once that release is done.
+const sessionFile = ".commet-session.json"
+
+// loadSession returns the pending reclassifications, or nil without a session.
+func loadSession() (map[string]string, error) {
+	content, err := os.ReadFile(sessionFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", sessionFile, err)
+	}
+
+	var session map[string]string
+	if err := json.Unmarshal(content, &session); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", sessionFile, err)
+	}
+	return session, nil
+}
+
+func saveSession(session map[string]string) error {
+	content, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", sessionFile, err)
+	}
+	if err := os.WriteFile(sessionFile, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", sessionFile, err)
+	}
+	return nil
+}
+
+// clearSession removes the session file after the release it was meant for.
+func clearSession() error {
+	err := os.Remove(sessionFile)
+	if err == nil {
+		color.Green("✓ Applied and removed the reclassifications in %s", sessionFile)
+		return nil
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return fmt.Errorf("failed to remove %s: %w", sessionFile, err)
+}
+
+// commitOverrides joins the commit's note and its session reclassification;
+// the session comes last so it wins.
+func commitOverrides(c *git.CommitInfo, session map[string]string) string {
+	overrides := strings.TrimSpace(c.Note)
+	if reclassified := strings.TrimSpace(session[c.FullHash]); reclassified != "" {
+		overrides = strings.TrimSpace(overrides + "\n" + reclassified)
+	}
+	return overrides
+}
+
+// reclassifyCommits lets the user pick commits of the release and override
+// their type and scope or exclude them, reading answers from in. The choices
+// are saved to the session file for the next release only.
+func reclassifyCommits(in io.Reader, cfg *config.Config, commits []*git.CommitInfo) error {
+	session, err := loadSession()
+	if err != nil {
+		return err
+	}
+	if session == nil {
+		session = make(map[string]string)
+	}
+
+	reader := bufio.NewReader(in)
+	changed := false
+
+	for {
+		fmt.Println()
+		color.Cyan("Commits in this release:")
+		for i, c := range commits {
+			fmt.Printf("  %2d) %s %s\n", i+1, c.Hash, describeCommit(cfg, c, session))
+		}
+
+		answer, err := prompt(reader, "Reclassify which commit? (number, empty to finish): ")
+		if err != nil || answer == "" {
+			break
+		}
+
+		n, convErr := strconv.Atoi(answer)
+		if convErr != nil || n < 1 || n > len(commits) {
+			color.Yellow("Enter a number between 1 and %d", len(commits))
+			continue
+		}
+		c := commits[n-1]
+
+		current, _ := cfg.CommitParser().Parse(c.FullMessage())
+		if overrides := commitOverrides(c, session); overrides != "" {
+			current.Annotate(overrides)
+		}
+
+		var overrides [][2]string
+		exclude, err := prompt(reader, "  exclude from this release? (y/N): ")
+		if err != nil {
+			return err
+		}
+		if exclude = strings.ToLower(exclude); exclude == "y" || exclude == "yes" {
+			overrides = append(overrides, [2]string{"Exclude", "true"})
+		} else {
+			overrides = append(overrides, [2]string{"Exclude", "false"})
+
+			commitType, err := prompt(reader, fmt.Sprintf("  type [%s]: ", current.Type))
+			if err != nil {
+				return err
+			}
+			if commitType != "" {
+				overrides = append(overrides, [2]string{"Type", commitType})
+			}
+
+			scope, err := prompt(reader, fmt.Sprintf("  scope [%s]: ", current.Scope))
+			if err != nil {
+				return err
+			}
+			if scope != "" {
+				overrides = append(overrides, [2]string{"Scope", scope})
+			}
+		}
+
+		session[c.FullHash] = mergeNote(session[c.FullHash], overrides)
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+
+	if err := saveSession(session); err != nil {
+		return err
+	}
+	color.Green("✓ Saved reclassifications to %s; the next release applies and removes them", sessionFile)
+	return nil
+}
+
+// describeCommit shows the commit as the release sees it: type, scope and bump.
+func describeCommit(cfg *config.Config, c *git.CommitInfo, session map[string]string) string {
+	parsed, _ := cfg.CommitParser().Parse(c.FullMessage())
+	parsed.Files = c.Files
+	if overrides := commitOverrides(c, session); overrides != "" {
+		parsed.Annotate(overrides)
+	}
+
+	if parsed.Excluded {
+		return truncate(c.Message, 60) + " (excluded)"
+	}
+	if !parsed.IsValidCommit() {
+		return truncate(c.Message, 60) + " (no type)"
+	}
+
+	bump := cfg.CommitBump(parsed.Type, parsed.Scope, parsed.Boards, parsed.Files, parsed.Bump)
+	if parsed.ForceMajor {
+		bump = config.BumpMajor
+	}
+
+	label := parsed.Type
+	if parsed.Scope != "" {
+		label += "(" + parsed.Scope + ")"
+	}
+	return fmt.Sprintf("%s → %s: %s", truncate(c.Message, 60), label, bump)
+}
+
+// prompt prints question and returns the trimmed answer line.
+func prompt(reader *bufio.Reader, question string) (string, error) {
+	fmt.Print(question)
+	line, err := reader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
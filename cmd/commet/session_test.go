@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yendefrr/commet/internal/config"
+	"github.com/yendefrr/commet/internal/git"
+)
+
+func TestReclassifyCommits(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	cfg := config.DefaultConfig()
+	commits := []*git.CommitInfo{
+		{Hash: "a1b2c3d", FullHash: strings.Repeat("a", 40), Message: "Fix: drop the v1 endpoint"},
+		{Hash: "b2c3d4e", FullHash: strings.Repeat("b", 40), Message: "Feature: debug page"},
+		{Hash: "c3d4e5f", FullHash: strings.Repeat("c", 40), Message: "Docs: typo"},
+	}
+
+	// Reclassify the first commit as Breaking, exclude the second, then stop.
+	answers := "1\nn\nBreaking\n\n2\ny\n\n"
+	if err := reclassifyCommits(strings.NewReader(answers), cfg, commits); err != nil {
+		t.Fatalf("reclassifyCommits() error = %v", err)
+	}
+
+	parsed := parseReleaseCommits(cfg, commits)
+	if len(parsed) != 2 {
+		t.Fatalf("parseReleaseCommits() returned %d commits, want 2 (one excluded)", len(parsed))
+	}
+	if parsed[0].Type != "Breaking" || parsed[1].Type != "Docs" {
+		t.Errorf("types = %s, %s; want Breaking, Docs", parsed[0].Type, parsed[1].Type)
+	}
+
+	if err := clearSession(); err != nil {
+		t.Fatalf("clearSession() error = %v", err)
+	}
+	if parsed := parseReleaseCommits(cfg, commits); len(parsed) != 3 || parsed[0].Type != "Fix" {
+		t.Errorf("reclassifications still applied after clearSession()")
+	}
+}
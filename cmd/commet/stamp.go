@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/fatih/color"
+)
+
+// stampOutputs returns the writer for the status lines of --stamp-file -
+// and, with it, sends the rest of the release output to stderr until
+// restore is called, so Bazel reads only the status lines from stdout.
+func stampOutputs() (stamp io.Writer, restore func()) {
+	if stampFile != "-" {
+		return os.Stdout, func() {}
+	}
+
+	output := color.Output
+	color.Output = color.Error
+	return os.Stdout, func() { color.Output = output }
+}
+
+// writeStampFile writes Bazel workspace status lines for ver to path, or to
+// stamp when path is "-". STABLE_ keys make Bazel rebuild stamped targets
+// when the version changes.
+func writeStampFile(stamp io.Writer, path, ver string) error {
+	if path == "" {
+		return nil
+	}
+
+	lines := []string{"STABLE_VERSION " + ver}
+	if parsed, err := semver.NewVersion(strings.TrimPrefix(ver, "v")); err == nil {
+		lines = append(lines,
+			fmt.Sprintf("STABLE_VERSION_MAJOR %d", parsed.Major()),
+			fmt.Sprintf("STABLE_VERSION_MINOR %d", parsed.Minor()),
+			fmt.Sprintf("STABLE_VERSION_PATCH %d", parsed.Patch()),
+		)
+		if parsed.Prerelease() != "" {
+			lines = append(lines, "STABLE_VERSION_PRERELEASE "+parsed.Prerelease())
+		}
+	}
+	content := strings.Join(lines, "\n") + "\n"
+
+	if path == "-" {
+		if _, err := io.WriteString(stamp, content); err != nil {
+			return fmt.Errorf("failed to write stamp file: %w", err)
+		}
+		return nil
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write stamp file: %w", err)
+	}
+
+	return nil
+}
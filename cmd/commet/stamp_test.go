@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestWriteStampFile(t *testing.T) {
+	var stamp bytes.Buffer
+	if err := writeStampFile(&stamp, "-", "1.3.0-rc.1"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "STABLE_VERSION 1.3.0-rc.1\nSTABLE_VERSION_MAJOR 1\nSTABLE_VERSION_MINOR 3\nSTABLE_VERSION_PATCH 0\nSTABLE_VERSION_PRERELEASE rc.1\n"
+	if got := stamp.String(); got != want {
+		t.Errorf("writeStampFile() wrote %q, want %q", got, want)
+	}
+}
+
+func TestStampOutputs(t *testing.T) {
+	output, errOutput := color.Output, color.Error
+	defer func() { stampFile, color.Output, color.Error = "", output, errOutput }()
+
+	var stdout, stderr bytes.Buffer
+	color.Output, color.Error = &stdout, &stderr
+
+	stampFile = "-"
+	_, restore := stampOutputs()
+	color.Green("✓ Updated package.json")
+	restore()
+	color.Green("✓ Done")
+
+	if got := stderr.String(); got != "✓ Updated package.json\n" {
+		t.Errorf("stderr = %q, want the release output", got)
+	}
+	if got := stdout.String(); got != "✓ Done\n" {
+		t.Errorf("stdout = %q, want only the output after restore", got)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yendefrr/commet/internal/config"
+	"github.com/yendefrr/commet/internal/stats"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var statsSelf bool
+
+// phases times the steps of the current invocation for the stats file.
+var phases = stats.NewRecorder()
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Summarize local commet usage statistics",
+	Long: `With --self, summarizes the runs recorded in the stats file: how often each
+command ran and where the time went (tag scan, parse, update, ...).
+Recording is opt-in with [stats] enabled = true and never leaves the machine.`,
+	Args: cobra.NoArgs,
+	RunE: showStats,
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+
+	statsCmd.Flags().BoolVar(&statsSelf, "self", false, "report on commet's own usage")
+}
+
+func showStats(cmd *cobra.Command, args []string) error {
+	if !statsSelf {
+		return fmt.Errorf("only --self is supported")
+	}
+
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if !fileExists(cfg.Stats.File) {
+		color.Yellow("No statistics recorded in %s", cfg.Stats.File)
+		if !cfg.Stats.Enabled {
+			fmt.Println("  Enable recording with [stats] enabled = true")
+		}
+		return nil
+	}
+
+	records, err := stats.Load(cfg.Stats.File)
+	if err != nil {
+		return err
+	}
+
+	commands, steps := stats.Summarize(records)
+
+	color.Cyan("%d runs recorded in %s", len(records), cfg.Stats.File)
+	fmt.Println()
+	printSummaries("COMMAND", commands)
+	if len(steps) > 0 {
+		fmt.Println()
+		printSummaries("PHASE", steps)
+	}
+
+	return nil
+}
+
+func printSummaries(title string, summaries []stats.Summary) {
+	writer := newTable()
+	fmt.Fprintf(writer, "%s\tRUNS\tFAILED\tTOTAL\tAVG\tMAX\n", title)
+	for _, s := range summaries {
+		fmt.Fprintf(writer, "%s\t%d\t%d\t%s\t%s\t%s\n", s.Name, s.Runs, s.Failed,
+			roundDuration(s.Total), roundDuration(s.Average()), roundDuration(s.Max))
+	}
+	writer.Flush()
+}
+
+func roundDuration(d time.Duration) time.Duration {
+	if d < time.Second {
+		return d.Round(time.Microsecond)
+	}
+	return d.Round(time.Millisecond)
+}
+
+// recordStats appends the finished invocation to the stats file when enabled.
+// Failures to record are only reported in verbose mode.
+func recordStats(cmd *cobra.Command, start time.Time, runErr error) {
+	if cmd == nil || cmd == statsCmd {
+		return
+	}
+
+	cfg, err := config.Load(cfgFile)
+	if err != nil || !cfg.Stats.Enabled {
+		return
+	}
+
+	record := phases.Record(cmd.CommandPath(), start, runErr != nil)
+	if err := stats.Append(cfg.Stats.File, record); err != nil && verbose {
+		color.Yellow("[WARN] %v", err)
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/yendefrr/commet/internal/config"
+	"github.com/yendefrr/commet/internal/git"
+	"github.com/yendefrr/commet/internal/version"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check that version files and the latest tag agree",
+	Long: `Reads every configured version file and the latest git tag and fails
+if they report different versions. Intended as a CI gate against manual edits.
+
+With [snapshot] or git.post_release_version configured, the version files are
+expected to hold the development version opened after the latest tag. Files
+with paths are only updated by releases touching them, so they may lag
+behind the latest tag but must not be ahead of it.`,
+	RunE: verifyVersions,
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+}
+
+type versionSource struct {
+	name    string
+	version string
+	err     error
+	// tag marks the latest git tag, the released version
+	tag bool
+	// gated marks version files with paths, updated only by the releases
+	// that touch them
+	gated bool
+}
+
+func verifyVersions(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	var sources []versionSource
+
+	for _, versionFile := range cfg.GetVersionFiles() {
+		name := fmt.Sprintf("%s (%s)", versionFile.File, versionFile.Location())
+		if !fileExists(versionFile.File) {
+			color.Yellow("[WARN] File not found: %s", versionFile.File)
+			continue
+		}
+
+		fileUpdater, err := newFileUpdater(versionFile)
+		if err != nil {
+			sources = append(sources, versionSource{name: name, err: err})
+			continue
+		}
+
+		v, err := fileUpdater.GetVersion(versionFile.Key)
+		sources = append(sources, versionSource{name: name, version: v, err: err, gated: len(versionFile.Paths) > 0})
+	}
+
+	released := ""
+	if git.IsGitRepository(".") {
+		gitClient, err := git.NewClient(".", cfg)
+		if err != nil {
+			return fmt.Errorf("failed to initialize git: %w", err)
+		}
+
+		tag, err := gitClient.GetLatestTag()
+		switch {
+		case err != nil:
+			sources = append(sources, versionSource{name: "git tag", err: err})
+		case tag == "":
+			color.Yellow("[WARN] No tag matches %s", cfg.Detection.TagPattern)
+		default:
+			v, err := gitClient.ExtractVersionFromTag(tag)
+			sources = append(sources, versionSource{name: "git tag " + tag, version: v, err: err, tag: true})
+			if err == nil {
+				released = v
+			}
+		}
+	}
+
+	if len(sources) == 0 {
+		return fmt.Errorf("no version sources found")
+	}
+
+	// After a release with a development cycle the files are ahead of the tag
+	devVersion := ""
+	if released != "" {
+		devVersion, _, err = nextDevelopmentVersion(cfg, version.NewCalculator(cfg), released)
+		if err != nil {
+			return fmt.Errorf("failed to calculate the development version after %s: %w", released, err)
+		}
+	}
+
+	expected := devVersion
+	for _, source := range sources {
+		if expected == "" && source.err == nil && !source.gated {
+			expected = source.version
+		}
+	}
+	for _, source := range sources {
+		if expected == "" && source.err == nil {
+			expected = source.version
+		}
+	}
+
+	failed := false
+	for _, source := range sources {
+		want := expected
+		if source.tag && devVersion != "" {
+			want = released
+		}
+
+		switch {
+		case source.err != nil:
+			failed = true
+			color.Red("✗ %-40s %v", source.name, source.err)
+		case source.gated && !sameVersion(source.version, want) && notAhead(source.version, released):
+			color.Green("✓ %-40s %s (its paths are unchanged since)", source.name, source.version)
+		case !sameVersion(source.version, want):
+			failed = true
+			color.Red("✗ %-40s %s (expected %s)", source.name, source.version, want)
+		default:
+			color.Green("✓ %-40s %s", source.name, source.version)
+		}
+	}
+
+	fmt.Println()
+	if failed {
+		return fmt.Errorf("version sources disagree")
+	}
+
+	if devVersion != "" {
+		color.Green("All version files hold %s, the development version after %s", devVersion, released)
+		return nil
+	}
+	color.Green("All version sources agree on %s", expected)
+	return nil
+}
+
+// notAhead reports whether v is not newer than the released version.
+func notAhead(v, released string) bool {
+	if released == "" {
+		return false
+	}
+	result, err := version.Compare(v, released)
+	return err == nil && result <= 0
+}
+
+func sameVersion(a, b string) bool {
+	result, err := version.Compare(a, b)
+	if err != nil {
+		return a == b
+	}
+	return result == 0
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/yendefrr/commet/internal/changelog"
+	"github.com/yendefrr/commet/internal/config"
+	"github.com/yendefrr/commet/internal/fsutil"
+	"github.com/yendefrr/commet/internal/git"
+	"github.com/yendefrr/commet/internal/github"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"golang.org/x/mod/modfile"
+)
+
+var (
+	yankReason  string
+	yankRetract bool
+	yankRelease bool
+)
+
+var yankCmd = &cobra.Command{
+	Use:   "yank <version>",
+	Short: "Mark a released version as yanked",
+	Long: `Marks a version as yanked in the changelog. With --retract a retract directive
+is added to go.mod, and with --release the GitHub release of the version is
+flagged as a prerelease with the reason prepended to its notes.
+Uses GITHUB_TOKEN for authentication.`,
+	Args: cobra.ExactArgs(1),
+	RunE: yankVersion,
+}
+
+func init() {
+	rootCmd.AddCommand(yankCmd)
+
+	yankCmd.Flags().StringVar(&yankReason, "reason", "", "why the version was yanked")
+	yankCmd.Flags().BoolVar(&yankRetract, "retract", false, "add a retract directive to go.mod")
+	yankCmd.Flags().BoolVar(&yankRelease, "release", false, "mark the GitHub release as a prerelease")
+}
+
+func yankVersion(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	ver := strings.TrimPrefix(args[0], "v")
+	tagName, err := releaseTag(cfg, ver)
+	if err != nil {
+		return err
+	}
+
+	entry := changelogVersion(cfg, ver)
+
+	// Everything is checked before the first change, so a missing version or
+	// release leaves the changelog, go.mod and the release as they were
+	content, err := os.ReadFile(cfg.Changelog.File)
+	if err != nil {
+		return fmt.Errorf("failed to read changelog: %w", err)
+	}
+	yanked, err := changelog.MarkYanked(string(content), entry, yankReason)
+	if err != nil {
+		return fmt.Errorf("failed to mark %s: %w", cfg.Changelog.File, err)
+	}
+
+	var modFile []byte
+	if yankRetract {
+		if modFile, err = retractModuleVersion("go.mod", "v"+ver, yankReason); err != nil {
+			return err
+		}
+	}
+
+	if dryRun {
+		color.Yellow("[DRY RUN] Would mark %s as yanked in %s", entry, cfg.Changelog.File)
+		if yankRetract {
+			color.Yellow("[DRY RUN] Would retract v%s in go.mod", ver)
+		}
+		if yankRelease {
+			color.Yellow("[DRY RUN] Would mark release %s as prerelease", tagName)
+		}
+		return nil
+	}
+
+	var release *githubRelease
+	if yankRelease {
+		if release, err = findGitHubRelease(cfg, tagName); err != nil {
+			return err
+		}
+	}
+
+	if err := fsutil.WriteFile(cfg.Changelog.File, []byte(yanked)); err != nil {
+		return fmt.Errorf("failed to write changelog: %w", err)
+	}
+	color.Green("✓ Marked %s as yanked in %s", entry, cfg.Changelog.File)
+
+	if yankRetract {
+		if err := fsutil.WriteFile("go.mod", modFile); err != nil {
+			return fmt.Errorf("failed to write go.mod: %w", err)
+		}
+		color.Green("✓ Retracted v%s in go.mod", ver)
+	}
+
+	if release != nil {
+		if err := release.yank(yankReason); err != nil {
+			return err
+		}
+		color.Green("✓ Marked release %s as prerelease", tagName)
+	}
+
+	return nil
+}
+
+// retractModuleVersion returns the go.mod at path with a retract directive
+// for ver added.
+func retractModuleVersion(path, ver, rationale string) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read go.mod: %w", err)
+	}
+
+	modFile, err := modfile.Parse(path, content, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse go.mod: %w", err)
+	}
+
+	for _, retract := range modFile.Retract {
+		if retract.Low == ver && retract.High == ver {
+			return nil, fmt.Errorf("%s is already retracted in go.mod", ver)
+		}
+	}
+
+	if err := modFile.AddRetract(modfile.VersionInterval{Low: ver, High: ver}, rationale); err != nil {
+		return nil, fmt.Errorf("failed to add retract directive: %w", err)
+	}
+
+	updated, err := modFile.Format()
+	if err != nil {
+		return nil, fmt.Errorf("failed to format go.mod: %w", err)
+	}
+
+	return updated, nil
+}
+
+// githubRelease is the GitHub release of a yanked version.
+type githubRelease struct {
+	client  *github.Client
+	owner   string
+	repo    string
+	tag     string
+	release *github.Release
+}
+
+// findGitHubRelease returns the release of tagName on the origin repository.
+func findGitHubRelease(cfg *config.Config, tagName string) (*githubRelease, error) {
+	gitClient, err := git.NewClient(".", cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	remote, err := gitClient.RemoteURL("origin")
+	if err != nil {
+		return nil, err
+	}
+
+	owner, repo, err := github.ParseRepoURL(remote)
+	if err != nil {
+		return nil, err
+	}
+
+	client := github.NewClient()
+
+	release, err := client.GetReleaseByTag(owner, repo, tagName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get release %s: %w", tagName, err)
+	}
+
+	return &githubRelease{client: client, owner: owner, repo: repo, tag: tagName, release: release}, nil
+}
+
+// yank flags the release as a prerelease and notes the reason.
+func (r *githubRelease) yank(reason string) error {
+	release := r.release
+	release.Prerelease = true
+	if !strings.HasPrefix(release.Name, "[YANKED]") {
+		release.Name = strings.TrimSpace("[YANKED] " + release.Name)
+	}
+
+	notice := "**This release has been yanked.**"
+	if reason != "" {
+		notice = fmt.Sprintf("**This release has been yanked:** %s", reason)
+	}
+	release.Body = notice + "\n\n" + release.Body
+
+	if err := r.client.UpdateRelease(r.owner, r.repo, release); err != nil {
+		return fmt.Errorf("failed to update release %s: %w", r.tag, err)
+	}
+
+	return nil
+}
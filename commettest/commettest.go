@@ -0,0 +1,280 @@
+// Package commettest provides fixtures for end-to-end tests of commet:
+// throwaway git repositories seeded with files, commits and tags, a Runner
+// that executes commet inside them, and assertions on the result.
+//
+//	runner := commettest.Build(t)
+//	repo := commettest.NewRepo(t)
+//	repo.WriteFile("package.json", `{"version": "1.2.3"}`)
+//	repo.Commit("Conf: initial")
+//	repo.Tag("v1.2.3")
+//	repo.Commit("Feature: add export")
+//	repo.Run(runner)
+//	repo.AssertTag("v1.3.0")
+package commettest
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Author signs the commits and tags created by Repo.
+var Author = object.Signature{Name: "commettest", Email: "commettest@example.com"}
+
+// Repo is a temporary git repository removed when the test ends.
+type Repo struct {
+	Dir string
+
+	t    testing.TB
+	repo *git.Repository
+}
+
+// NewRepo initializes an empty repository in a temporary directory. Its
+// local git config carries Author so commet can commit without a global one.
+func NewRepo(t testing.TB) *Repo {
+	t.Helper()
+
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("failed to init repository: %v", err)
+	}
+
+	cfg, err := repo.Config()
+	if err != nil {
+		t.Fatalf("failed to read repository config: %v", err)
+	}
+	cfg.User.Name = Author.Name
+	cfg.User.Email = Author.Email
+	if err := repo.SetConfig(cfg); err != nil {
+		t.Fatalf("failed to write repository config: %v", err)
+	}
+
+	return &Repo{Dir: dir, t: t, repo: repo}
+}
+
+// NewReleaseRepo returns a repository with a release to build on: cfg as
+// .commet.toml and package.json at version 1.2.3, committed and tagged
+// v1.2.3.
+func NewReleaseRepo(t testing.TB, cfg string) *Repo {
+	t.Helper()
+
+	r := NewRepo(t)
+	r.WriteFile(".commet.toml", cfg)
+	r.WriteFile("package.json", `{"version": "1.2.3"}`+"\n")
+	r.Commit("Conf: initial")
+	r.Tag("v1.2.3")
+	return r
+}
+
+// WriteFile writes content to name, relative to the repository root,
+// creating parent directories as needed.
+func (r *Repo) WriteFile(name, content string) {
+	r.t.Helper()
+
+	path := filepath.Join(r.Dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		r.t.Fatalf("failed to create directory for %s: %v", name, err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		r.t.Fatalf("failed to write %s: %v", name, err)
+	}
+}
+
+// ReadFile returns the content of name, relative to the repository root.
+func (r *Repo) ReadFile(name string) string {
+	r.t.Helper()
+
+	content, err := os.ReadFile(filepath.Join(r.Dir, filepath.FromSlash(name)))
+	if err != nil {
+		r.t.Fatalf("failed to read %s: %v", name, err)
+	}
+	return string(content)
+}
+
+// Commit stages every change in the worktree and commits it with message,
+// returning the abbreviated hash. Empty commits are allowed.
+func (r *Repo) Commit(message string) string {
+	r.t.Helper()
+
+	worktree, err := r.repo.Worktree()
+	if err != nil {
+		r.t.Fatalf("failed to get worktree: %v", err)
+	}
+
+	if err := worktree.AddWithOptions(&git.AddOptions{All: true}); err != nil {
+		r.t.Fatalf("failed to stage changes: %v", err)
+	}
+
+	hash, err := worktree.Commit(message, &git.CommitOptions{
+		Author:            r.signature(),
+		AllowEmptyCommits: true,
+	})
+	if err != nil {
+		r.t.Fatalf("failed to commit %q: %v", message, err)
+	}
+
+	return hash.String()[:7]
+}
+
+// AddRemote configures a remote, e.g. origin, without fetching from it.
+func (r *Repo) AddRemote(name, url string) {
+	r.t.Helper()
+
+	if _, err := r.repo.CreateRemote(&config.RemoteConfig{Name: name, URLs: []string{url}}); err != nil {
+		r.t.Fatalf("failed to add remote %s: %v", name, err)
+	}
+}
+
+// Checkout switches to branch, creating it at HEAD when it does not exist.
+// Uncommitted changes are kept.
+func (r *Repo) Checkout(branch string) {
+	r.t.Helper()
+
+	worktree, err := r.repo.Worktree()
+	if err != nil {
+		r.t.Fatalf("failed to get worktree: %v", err)
+	}
+
+	name := plumbing.NewBranchReferenceName(branch)
+	_, err = r.repo.Reference(name, false)
+
+	err = worktree.Checkout(&git.CheckoutOptions{
+		Branch: name,
+		Create: err != nil,
+		Keep:   true,
+	})
+	if err != nil {
+		r.t.Fatalf("failed to check out %s: %v", branch, err)
+	}
+}
+
+// Tag creates an annotated tag at HEAD.
+func (r *Repo) Tag(name string) {
+	r.t.Helper()
+
+	head, err := r.repo.Head()
+	if err != nil {
+		r.t.Fatalf("failed to get HEAD: %v", err)
+	}
+
+	_, err = r.repo.CreateTag(name, head.Hash(), &git.CreateTagOptions{
+		Tagger:  r.signature(),
+		Message: name,
+	})
+	if err != nil {
+		r.t.Fatalf("failed to create tag %s: %v", name, err)
+	}
+}
+
+// Tags returns the names of all tags, sorted.
+func (r *Repo) Tags() []string {
+	r.t.Helper()
+
+	iter, err := r.repo.Tags()
+	if err != nil {
+		r.t.Fatalf("failed to list tags: %v", err)
+	}
+
+	var tags []string
+	iter.ForEach(func(ref *plumbing.Reference) error {
+		tags = append(tags, ref.Name().Short())
+		return nil
+	})
+	sort.Strings(tags)
+
+	return tags
+}
+
+// HeadMessage returns the subject of the commit at HEAD.
+func (r *Repo) HeadMessage() string {
+	r.t.Helper()
+
+	head, err := r.repo.Head()
+	if err != nil {
+		r.t.Fatalf("failed to get HEAD: %v", err)
+	}
+
+	commit, err := r.repo.CommitObject(head.Hash())
+	if err != nil {
+		r.t.Fatalf("failed to get HEAD commit: %v", err)
+	}
+
+	return strings.SplitN(commit.Message, "\n", 2)[0]
+}
+
+// AssertFile fails the test unless name contains exactly want.
+func (r *Repo) AssertFile(name, want string) {
+	r.t.Helper()
+
+	if got := r.ReadFile(name); got != want {
+		r.t.Errorf("%s:\ngot:\n%s\nwant:\n%s", name, got, want)
+	}
+}
+
+// AssertFileContains fails the test unless name contains substr.
+func (r *Repo) AssertFileContains(name, substr string) {
+	r.t.Helper()
+
+	if got := r.ReadFile(name); !strings.Contains(got, substr) {
+		r.t.Errorf("%s does not contain %q:\n%s", name, substr, got)
+	}
+}
+
+// AssertTag fails the test unless the tag exists.
+func (r *Repo) AssertTag(name string) {
+	r.t.Helper()
+
+	for _, tag := range r.Tags() {
+		if tag == name {
+			return
+		}
+	}
+	r.t.Errorf("tag %s not found, have %v", name, r.Tags())
+}
+
+// AssertNoTag fails the test if the tag exists.
+func (r *Repo) AssertNoTag(name string) {
+	r.t.Helper()
+
+	for _, tag := range r.Tags() {
+		if tag == name {
+			r.t.Errorf("unexpected tag %s", name)
+			return
+		}
+	}
+}
+
+// AssertTagAtHead fails the test unless the tag points at the commit at HEAD.
+func (r *Repo) AssertTagAtHead(name string) {
+	r.t.Helper()
+
+	head, err := r.repo.Head()
+	if err != nil {
+		r.t.Fatalf("failed to get HEAD: %v", err)
+	}
+
+	hash, err := r.repo.ResolveRevision(plumbing.Revision("refs/tags/" + name))
+	if err != nil {
+		r.t.Errorf("tag %s not found: %v", name, err)
+		return
+	}
+	if *hash != head.Hash() {
+		r.t.Errorf("tag %s points at %s, want HEAD %s", name, hash.String()[:7], head.Hash().String()[:7])
+	}
+}
+
+// signature returns Author stamped with the current time.
+func (r *Repo) signature() *object.Signature {
+	sig := Author
+	sig.When = time.Now()
+	return &sig
+}
@@ -0,0 +1,103 @@
+package commettest
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// Runner runs commet with args in dir and returns its combined output.
+type Runner func(dir string, args ...string) (string, error)
+
+// Binary returns a Runner executing the commet binary at path.
+func Binary(path string) Runner {
+	return func(dir string, args ...string) (string, error) {
+		cmd := exec.Command(path, args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "NO_COLOR=1")
+		output, err := cmd.CombinedOutput()
+		return string(output), err
+	}
+}
+
+// shared is the binary Build compiles once for the tests of a package run
+// through Main.
+var shared struct {
+	dir  string
+	once sync.Once
+	path string
+	err  error
+	log  []byte
+}
+
+// Main runs the tests of a package, sharing one commet binary between them
+// that is built on first use and removed afterwards. Call it from TestMain:
+//
+//	func TestMain(m *testing.M) { commettest.Main(m) }
+func Main(m *testing.M) {
+	dir, err := os.MkdirTemp("", "commettest")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create build directory: %v\n", err)
+		os.Exit(1)
+	}
+	shared.dir = dir
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// Build compiles commet with the go tool on PATH and returns a Runner for
+// it. Under Main the binary is built once and shared; otherwise each test
+// builds its own in a temporary directory. The module must be resolvable
+// from the working directory, which holds for commet itself and for modules
+// requiring it.
+func Build(t testing.TB) Runner {
+	t.Helper()
+
+	if shared.dir != "" {
+		shared.once.Do(func() {
+			shared.path = filepath.Join(shared.dir, "commet")
+			shared.log, shared.err = build(shared.path)
+		})
+		if shared.err != nil {
+			t.Fatalf("failed to build commet: %v\n%s", shared.err, shared.log)
+		}
+		return Binary(shared.path)
+	}
+
+	path := filepath.Join(t.TempDir(), "commet")
+	if log, err := build(path); err != nil {
+		t.Fatalf("failed to build commet: %v\n%s", err, log)
+	}
+	return Binary(path)
+}
+
+func build(path string) ([]byte, error) {
+	return exec.Command("go", "build", "-o", path, "github.com/yendefrr/commet/cmd/commet").CombinedOutput()
+}
+
+// Run runs commet in the repository and fails the test if it errors.
+func (r *Repo) Run(runner Runner, args ...string) string {
+	r.t.Helper()
+
+	output, err := runner(r.Dir, args...)
+	if err != nil {
+		r.t.Fatalf("commet %v: %v\n%s", args, err, output)
+	}
+	return output
+}
+
+// RunError runs commet in the repository and fails the test unless it errors.
+func (r *Repo) RunError(runner Runner, args ...string) string {
+	r.t.Helper()
+
+	output, err := runner(r.Dir, args...)
+	if err == nil {
+		r.t.Fatalf("commet %v: expected an error\n%s", args, output)
+	}
+	return output
+}
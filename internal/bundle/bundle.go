@@ -0,0 +1,263 @@
+// Package bundle builds release archives for a list of GOOS/GOARCH targets:
+// each target's binary is built, archived with the extra files under a
+// templated name, and every archive is listed in a SHA-256 checksum file.
+package bundle
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/yendefrr/commet/internal/config"
+)
+
+// Target is a GOOS/GOARCH pair such as linux/amd64.
+type Target struct {
+	OS   string
+	Arch string
+}
+
+// ParseTarget parses "linux/amd64".
+func ParseTarget(s string) (Target, error) {
+	goos, goarch, ok := strings.Cut(s, "/")
+	if !ok || goos == "" || goarch == "" {
+		return Target{}, fmt.Errorf("invalid target %q: expected <os>/<arch>", s)
+	}
+	return Target{OS: goos, Arch: goarch}, nil
+}
+
+func (t Target) String() string {
+	return t.OS + "/" + t.Arch
+}
+
+// Artifact is a built archive.
+type Artifact struct {
+	Target Target
+	Path   string
+	SHA256 string
+}
+
+// Bundler builds the archives of one version.
+type Bundler struct {
+	config  config.PackageConfig
+	version string
+}
+
+func NewBundler(cfg config.PackageConfig, version string) *Bundler {
+	return &Bundler{config: cfg, version: version}
+}
+
+// expand fills the {version}, {os}, {arch} and {binary} placeholders.
+func (b *Bundler) expand(template string, target Target) string {
+	return strings.NewReplacer(
+		"{version}", b.version,
+		"{os}", target.OS,
+		"{arch}", target.Arch,
+		"{binary}", b.config.Binary,
+	).Replace(template)
+}
+
+// ArchiveName returns the file name of target's archive.
+func (b *Bundler) ArchiveName(target Target) string {
+	ext := ".tar.gz"
+	if target.OS == "windows" {
+		ext = ".zip"
+	}
+	return b.expand(b.config.Name, target) + ext
+}
+
+// Build builds and archives target into the dist directory.
+func (b *Bundler) Build(target Target) (*Artifact, error) {
+	workDir := filepath.Join(b.config.Dist, b.expand(b.config.Name, target))
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", workDir, err)
+	}
+	defer os.RemoveAll(workDir)
+
+	binary := b.config.Binary
+	if target.OS == "windows" {
+		binary += ".exe"
+	}
+	output := filepath.Join(workDir, binary)
+
+	if err := b.compile(target, output); err != nil {
+		return nil, fmt.Errorf("failed to build %s: %w", target, err)
+	}
+
+	archive := filepath.Join(b.config.Dist, b.ArchiveName(target))
+	files := append([]string{output}, b.config.Files...)
+
+	var err error
+	if target.OS == "windows" {
+		err = writeZip(archive, files)
+	} else {
+		err = writeTarGz(archive, files)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to archive %s: %w", target, err)
+	}
+
+	sum, err := fileSHA256(archive)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Artifact{Target: target, Path: archive, SHA256: sum}, nil
+}
+
+// compile runs build_command, or go build of main when none is set.
+func (b *Bundler) compile(target Target, output string) error {
+	var cmd *exec.Cmd
+	if b.config.BuildCommand != "" {
+		shell, flag := "sh", "-c"
+		if runtime.GOOS == "windows" {
+			shell, flag = "cmd", "/C"
+		}
+		command := strings.ReplaceAll(b.expand(b.config.BuildCommand, target), "{output}", output)
+		cmd = exec.Command(shell, flag, command)
+	} else {
+		args := []string{"build", "-trimpath", "-o", output}
+		if b.config.Ldflags != "" {
+			args = append(args, "-ldflags", b.expand(b.config.Ldflags, target))
+		}
+		cmd = exec.Command("go", append(args, b.config.Main)...)
+	}
+
+	cmd.Env = append(os.Environ(), "GOOS="+target.OS, "GOARCH="+target.Arch, "CGO_ENABLED=0", "COMMET_VERSION="+b.version)
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// WriteChecksums writes "<sha256>  <archive>" lines to the checksum file in
+// the dist directory and returns its path.
+func (b *Bundler) WriteChecksums(artifacts []*Artifact) (string, error) {
+	var lines strings.Builder
+	for _, artifact := range artifacts {
+		fmt.Fprintf(&lines, "%s  %s\n", artifact.SHA256, filepath.Base(artifact.Path))
+	}
+
+	path := filepath.Join(b.config.Dist, b.expand(b.config.Checksum, Target{}))
+	if err := os.WriteFile(path, []byte(lines.String()), 0644); err != nil {
+		return "", fmt.Errorf("failed to write checksums: %w", err)
+	}
+
+	return path, nil
+}
+
+func writeTarGz(path string, files []string) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.Base(file)
+
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if err := copyFile(tw, file); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+func writeZip(path string, files []string) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	zw := zip.NewWriter(out)
+
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.Base(file)
+		header.Method = zip.Deflate
+
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		if err := copyFile(w, file); err != nil {
+			return err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+func copyFile(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(w, f)
+	return err
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
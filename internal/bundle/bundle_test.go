@@ -0,0 +1,191 @@
+package bundle
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yendefrr/commet/internal/config"
+)
+
+// testFiles writes the files to a new directory and returns their paths.
+func testFiles(t *testing.T, files map[string]string) []string {
+	t.Helper()
+
+	dir := t.TempDir()
+	var paths []string
+	for _, name := range []string{"app", "README.md", "LICENSE"} {
+		content, ok := files[name]
+		if !ok {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0755); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+var archived = map[string]string{
+	"app":       "#!/bin/sh\necho app\n",
+	"README.md": "# App\n",
+	"LICENSE":   "MIT\n",
+}
+
+func TestWriteTarGz(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "app.tar.gz")
+	if err := writeTarGz(archive, testFiles(t, archived)); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+
+	got := make(map[string]string)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got[header.Name] = string(content)
+		if header.Name == "app" && header.FileInfo().Mode().Perm()&0100 == 0 {
+			t.Errorf("app lost its executable bit: %v", header.FileInfo().Mode())
+		}
+	}
+	assertArchived(t, got)
+}
+
+func TestWriteZip(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "app.zip")
+	if err := writeZip(archive, testFiles(t, archived)); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+
+	got := make(map[string]string)
+	for _, file := range zr.File {
+		if file.Method != zip.Deflate {
+			t.Errorf("%s is not deflated", file.Name)
+		}
+		r, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got[file.Name] = string(content)
+	}
+	assertArchived(t, got)
+}
+
+func TestWriteMissingFile(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{filepath.Join(dir, "missing")}
+
+	if err := writeTarGz(filepath.Join(dir, "app.tar.gz"), files); err == nil {
+		t.Error("writeTarGz() of a missing file succeeded")
+	}
+	if err := writeZip(filepath.Join(dir, "app.zip"), files); err == nil {
+		t.Error("writeZip() of a missing file succeeded")
+	}
+}
+
+func assertArchived(t *testing.T, got map[string]string) {
+	t.Helper()
+
+	if len(got) != len(archived) {
+		t.Errorf("archived %d files, want %d", len(got), len(archived))
+	}
+	for name, content := range archived {
+		if got[name] != content {
+			t.Errorf("%s = %q, want %q", name, got[name], content)
+		}
+	}
+}
+
+func TestArchiveName(t *testing.T) {
+	bundler := NewBundler(config.PackageConfig{Name: "{binary}_{version}_{os}_{arch}", Binary: "app"}, "1.3.0")
+
+	tests := map[Target]string{
+		{OS: "linux", Arch: "amd64"}:   "app_1.3.0_linux_amd64.tar.gz",
+		{OS: "darwin", Arch: "arm64"}:  "app_1.3.0_darwin_arm64.tar.gz",
+		{OS: "windows", Arch: "amd64"}: "app_1.3.0_windows_amd64.zip",
+	}
+	for target, want := range tests {
+		if got := bundler.ArchiveName(target); got != want {
+			t.Errorf("ArchiveName(%s) = %q, want %q", target, got, want)
+		}
+	}
+}
+
+func TestParseTarget(t *testing.T) {
+	target, err := ParseTarget("linux/arm64")
+	if err != nil || target != (Target{OS: "linux", Arch: "arm64"}) {
+		t.Errorf("ParseTarget(linux/arm64) = %v, %v", target, err)
+	}
+	for _, s := range []string{"linux", "/amd64", "linux/"} {
+		if _, err := ParseTarget(s); err == nil {
+			t.Errorf("ParseTarget(%q) succeeded", s)
+		}
+	}
+}
+
+func TestWriteChecksums(t *testing.T) {
+	dist := t.TempDir()
+	archive := filepath.Join(dist, "app_1.3.0_linux_amd64.tar.gz")
+	if err := os.WriteFile(archive, []byte("archive"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sum, err := fileSHA256(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "0eb3e36bfb24dcd9bb1d1bece1531216b59539a8fde17ee80224af0653c92aa3"; sum != want {
+		t.Fatalf("fileSHA256() = %q, want %q", sum, want)
+	}
+
+	bundler := NewBundler(config.PackageConfig{Dist: dist, Checksum: "app_{version}_checksums.txt"}, "1.3.0")
+	path, err := bundler.WriteChecksums([]*Artifact{{Path: archive, SHA256: sum}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != filepath.Join(dist, "app_1.3.0_checksums.txt") {
+		t.Errorf("WriteChecksums() wrote %s", path)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := sum + "  app_1.3.0_linux_amd64.tar.gz\n"; string(content) != want {
+		t.Errorf("checksums = %q, want %q", content, want)
+	}
+}
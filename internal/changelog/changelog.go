@@ -2,20 +2,57 @@ package changelog
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"text/template"
 	"time"
 
+	"github.com/yendefrr/commet/internal/config"
+	"github.com/yendefrr/commet/internal/fsutil"
 	"github.com/yendefrr/commet/internal/parser"
 )
 
+// Clock returns the current time. Generators take one so tests can pin the
+// dates in rendered entries.
+type Clock func() time.Time
+
 type Generator struct {
 	filePath string
+	config   config.ChangelogConfig
+	clock    Clock
+	output   io.Writer
+}
+
+// NewGenerator dates entries with ReleaseClock.
+func NewGenerator(filePath string, cfg config.ChangelogConfig) *Generator {
+	return &Generator{filePath: filePath, config: cfg, clock: ReleaseClock(cfg)}
 }
 
-func NewGenerator(filePath string) *Generator {
-	return &Generator{filePath: filePath}
+// ReleaseClock returns cfg.ReleaseDate when it is set and time.Now otherwise,
+// so every changelog of a release carries the same date.
+func ReleaseClock(cfg config.ChangelogConfig) Clock {
+	if released := cfg.ReleaseDate; !released.IsZero() {
+		return func() time.Time { return released }
+	}
+	return time.Now
+}
+
+// WithClock dates entries with clock instead of time.Now.
+func (g *Generator) WithClock(clock Clock) *Generator {
+	g.clock = clock
+	return g
+}
+
+// WithWriter makes Generate and GenerateFromNotes write entries to w instead
+// of prepending them to the changelog file.
+func (g *Generator) WithWriter(w io.Writer) *Generator {
+	g.output = w
+	return g
 }
 
 type CommitGroup struct {
@@ -26,17 +63,54 @@ type CommitGroup struct {
 }
 
 func (g *Generator) Generate(version string, commits []*parser.Commit) error {
-	// Group commits by type
-	groups := g.groupCommits(commits)
-
 	// Generate markdown
-	entry := g.formatEntry(version, groups)
+	entry, err := g.Render(version, commits)
+	if err != nil {
+		return err
+	}
 
 	// Append to file
-	return g.appendToFile(entry)
+	return g.write(entry)
+}
+
+// Render returns the markdown entry for the given version without touching the changelog file.
+func (g *Generator) Render(version string, commits []*parser.Commit) (string, error) {
+	// Group commits by type
+	groups := GroupCommits(g.filterCommits(commits), g.config)
+
+	return g.formatEntry(version, groups)
 }
 
-func (g *Generator) groupCommits(commits []*parser.Commit) []*CommitGroup {
+// Groups returns the commits grouped by type as they appear in an entry,
+// limited to IncludeTypes.
+func (g *Generator) Groups(commits []*parser.Commit) []*CommitGroup {
+	return GroupCommits(g.filterCommits(commits), g.config)
+}
+
+// filterCommits keeps only the commit types listed in IncludeTypes, if any.
+func (g *Generator) filterCommits(commits []*parser.Commit) []*parser.Commit {
+	if len(g.config.IncludeTypes) == 0 {
+		return commits
+	}
+
+	included := make(map[string]bool, len(g.config.IncludeTypes))
+	for _, t := range g.config.IncludeTypes {
+		included[t] = true
+	}
+
+	var filtered []*parser.Commit
+	for _, commit := range commits {
+		if included[commit.Type] {
+			filtered = append(filtered, commit)
+		}
+	}
+
+	return filtered
+}
+
+// GroupCommits groups commits by type in the order entries list them, custom
+// types by name and untyped commits last, titled by cfg.Titles.
+func GroupCommits(commits []*parser.Commit, cfg config.ChangelogConfig) []*CommitGroup {
 	typeMap := make(map[string]*CommitGroup)
 	var untyped []*parser.Commit
 
@@ -56,6 +130,21 @@ func (g *Generator) groupCommits(commits []*parser.Commit) []*CommitGroup {
 		"Migrations": {"🗄️", "Migrations"},
 		"Submodule": {"🏷️", "Submodules"},
 		"Breaking":  {"💥", "Breaking Changes"},
+		"Security":  {"🔒", "Security"},
+		"Revert":    {"⏪", "Reverts"},
+		// Conventional Commits types
+		"feat":      {"✨", "Features"},
+		"fix":       {"🐝", "Bug Fixes"},
+		"perf":      {"⚡", "Performance Improvements"},
+		"refactor":  {"🔧", "Refactoring"},
+		"revert":    {"⏪", "Reverts"},
+		"docs":      {"📚", "Documentation"},
+		"style":     {"💅", "Styling"},
+		"build":     {"🏗️", "Build System"},
+		"ci":        {"🤖", "Continuous Integration"},
+		"test":      {"🧪", "Tests"},
+		"chore":     {"🧰", "Chores"},
+		"security":  {"🔒", "Security"},
 	}
 
 	for _, commit := range commits {
@@ -84,14 +173,28 @@ func (g *Generator) groupCommits(commits []*parser.Commit) []*CommitGroup {
 
 	typeOrder := []string{
 		"Breaking",
+		"Security",
+		"security",
 		"Feature",
+		"feat",
 		"Fix",
+		"fix",
+		"perf",
 		"Refactor",
+		"refactor",
+		"Revert",
+		"revert",
 		"Docs",
+		"docs",
 		"Style",
+		"style",
 		"Build",
+		"build",
+		"ci",
 		"Tests",
+		"test",
 		"Conf",
+		"chore",
 		"Migrations",
 		"Submodule",
 	}
@@ -103,8 +206,15 @@ func (g *Generator) groupCommits(commits []*parser.Commit) []*CommitGroup {
 		}
 	}
 
-	for _, group := range typeMap {
-		groups = append(groups, group)
+	// Custom types follow in name order so entries render the same every run
+	var custom []string
+	for typeName := range typeMap {
+		custom = append(custom, typeName)
+	}
+	sort.Strings(custom)
+
+	for _, typeName := range custom {
+		groups = append(groups, typeMap[typeName])
 	}
 
 	if len(untyped) > 0 {
@@ -116,13 +226,62 @@ func (g *Generator) groupCommits(commits []*parser.Commit) []*CommitGroup {
 		})
 	}
 
+	for _, group := range groups {
+		key := group.Type
+		if key == "" {
+			key = "Other"
+		}
+		if title, ok := cfg.Titles[key]; ok {
+			group.Description = title
+		}
+	}
+
 	return groups
 }
 
-func (g *Generator) formatEntry(version string, groups []*CommitGroup) string {
+// GenerateFromNotes writes hand-written release notes as the entry for version.
+// For a locale with a translate command the notes are translated first.
+func (g *Generator) GenerateFromNotes(version, notes string) error {
+	if g.config.TranslateCommand != "" {
+		translated, err := g.translate(version, notes)
+		if err != nil {
+			return err
+		}
+		notes = translated
+	}
+	return g.write(g.RenderNotes(version, notes))
+}
+
+// translate pipes text through the locale's translate command.
+func (g *Generator) translate(version, text string) (string, error) {
+	return runCommand("translate", g.config.TranslateCommand, []byte(text),
+		"COMMET_VERSION="+version, "COMMET_LOCALE="+g.config.Locale)
+}
+
+// RenderNotes wraps hand-written release notes in a version header.
+func (g *Generator) RenderNotes(version, notes string) string {
+	return g.formatHeader(version) + strings.TrimSpace(notes) + "\n\n"
+}
+
+func (g *Generator) formatHeader(version string) string {
+	return fmt.Sprintf("## [%s] - %s\n\n", version, g.clock().Format("2006-01-02"))
+}
+
+func (g *Generator) formatEntry(version string, groups []*CommitGroup) (string, error) {
 	var sb strings.Builder
 
-	sb.WriteString(fmt.Sprintf("## [%s] - %s\n\n", version, time.Now().Format("2006-01-02")))
+	summary := g.config.Summary
+	if g.config.SummaryCommand != "" && !g.config.Summarized {
+		var err error
+		if summary, err = summarize(g.config.SummaryCommand, version, groups); err != nil {
+			return "", err
+		}
+	}
+	if summary != "" {
+		sb.WriteString(fmt.Sprintf("### Highlights\n\n%s\n\n", summary))
+	}
+
+	sb.WriteString(formatMigrationNotes(groups))
 
 	for _, group := range groups {
 		if len(group.Commits) == 0 {
@@ -132,34 +291,314 @@ func (g *Generator) formatEntry(version string, groups []*CommitGroup) string {
 		sb.WriteString(fmt.Sprintf("### %s %s\n\n", group.Emoji, group.Description))
 
 		for _, commit := range group.Commits {
-			sb.WriteString(g.formatCommit(commit))
+			line, err := g.formatCommit(group.Type, commit)
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(line)
 		}
 
 		sb.WriteString("\n")
 	}
 
+	body := sb.String()
+	if g.config.TranslateCommand != "" && body != "" {
+		translated, err := g.translate(version, body)
+		if err != nil {
+			return "", err
+		}
+		body = translated + "\n\n"
+	}
+
+	if g.config.Contributors {
+		body += g.formatContributors(groups)
+	}
+
+	if g.config.FooterTemplate != "" {
+		footer, err := g.formatFooter(version, groups)
+		if err != nil {
+			return "", err
+		}
+		body += footer
+	}
+
+	return g.formatHeader(version) + body, nil
+}
+
+// EntryData is what footer_template is rendered with: the version and the
+// grouped commits of the entry.
+type EntryData struct {
+	Version string
+	Groups  []*CommitGroup
+	Commits []*parser.Commit
+}
+
+// Trailers returns the distinct values of the git trailer token across the
+// commits of the entry, in order, e.g. the reviewers for "Reviewed-by".
+func (d EntryData) Trailers(token string) []string {
+	var values []string
+	seen := make(map[string]bool)
+	for _, commit := range d.Commits {
+		for _, value := range commit.Footer(token) {
+			if !seen[value] {
+				seen[value] = true
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// formatFooter renders footer_template below the entry's sections.
+func (g *Generator) formatFooter(version string, groups []*CommitGroup) (string, error) {
+	tmpl, err := template.New("footer").Parse(g.config.FooterTemplate)
+	if err != nil {
+		return "", fmt.Errorf("invalid changelog footer template: %w", err)
+	}
+
+	data := EntryData{Version: version, Groups: groups}
+	for _, group := range groups {
+		data.Commits = append(data.Commits, group.Commits...)
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to render changelog footer template: %w", err)
+	}
+
+	footer := strings.TrimSpace(sb.String())
+	if footer == "" {
+		return "", nil
+	}
+	return footer + "\n\n", nil
+}
+
+// formatContributors lists the authors and co-authors of the entry's commits
+// by name.
+func (g *Generator) formatContributors(groups []*CommitGroup) string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, group := range groups {
+		for _, commit := range group.Commits {
+			for _, name := range commit.Contributors() {
+				if !seen[name] {
+					seen[name] = true
+					names = append(names, name)
+				}
+			}
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return strings.ToLower(names[i]) < strings.ToLower(names[j])
+	})
+
+	title := "Contributors"
+	if localized, ok := g.config.Titles[title]; ok {
+		title = localized
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "### 👥 %s\n\n", title)
+	for _, name := range names {
+		fmt.Fprintf(&sb, "- %s\n", name)
+	}
+	sb.WriteString("\n")
 	return sb.String()
 }
 
-func (g *Generator) formatCommit(commit *parser.Commit) string {
+// formatMigrationNotes lists the text of BREAKING CHANGE footers, if any.
+func formatMigrationNotes(groups []*CommitGroup) string {
+	var sb strings.Builder
+	for _, group := range groups {
+		for _, commit := range group.Commits {
+			if commit.BreakingChange == "" {
+				continue
+			}
+
+			text := strings.ReplaceAll(commit.BreakingChange, "\n", "\n  ")
+			if commit.Scope != "" {
+				text = fmt.Sprintf("**%s**: %s", commit.Scope, text)
+			}
+			sb.WriteString(fmt.Sprintf("- %s\n", text))
+		}
+	}
+
+	if sb.Len() == 0 {
+		return ""
+	}
+	return "### ⚠️ Migration Notes\n\n" + sb.String() + "\n"
+}
+
+func (g *Generator) formatCommit(commitType string, commit *parser.Commit) (string, error) {
+	if g.config.StripEmoji || g.config.StripBoards || g.config.Normalize {
+		cleaned := *commit
+		cleaned.Description = cleanDescription(commit.Description, g.boardPattern(), g.config.StripEmoji, g.config.StripBoards)
+		if g.config.Normalize {
+			cleaned.Description = normalizeDescription(cleaned.Description, g.boardPattern())
+		}
+		commit = &cleaned
+	}
+
+	if typeCfg, ok := g.config.Types[commitType]; ok && typeCfg.Template != "" {
+		return g.formatCommitTemplate(commitType, typeCfg.Template, commit)
+	}
+
+	return g.formatCommitDefault(commit), nil
+}
+
+func (g *Generator) formatCommitTemplate(commitType, text string, commit *parser.Commit) (string, error) {
+	tmpl, err := template.New(commitType).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid changelog template for %s: %w", commitType, err)
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, commit); err != nil {
+		return "", fmt.Errorf("failed to render changelog template for %s: %w", commitType, err)
+	}
+
+	line := sb.String()
+	if !strings.HasSuffix(line, "\n") {
+		line += "\n"
+	}
+
+	return line, nil
+}
+
+func (g *Generator) formatCommitDefault(commit *parser.Commit) string {
 	var parts []string
 
 	if commit.Scope != "" {
 		parts = append(parts, fmt.Sprintf("**%s**", commit.Scope))
 	}
 
-	parts = append(parts, commit.Description)
+	parts = append(parts, linkAdvisories(commit.Description))
 
 	var suffix string
-	if commit.Board != "" {
-		suffix = fmt.Sprintf(" (%s)", commit.Board)
+	if boards := g.formatBoards(commit); boards != "" {
+		suffix = fmt.Sprintf(" (%s)", boards)
+	}
+
+	if commit.PR != 0 {
+		suffix += " " + g.formatPR(commit.PR)
 	}
 
 	if commit.Hash != "" {
 		suffix += fmt.Sprintf(" [`%s`]", commit.Hash)
 	}
 
-	return fmt.Sprintf("- %s%s\n", strings.Join(parts, ": "), suffix)
+	line := fmt.Sprintf("- %s%s\n", strings.Join(parts, ": "), suffix)
+	if g.config.BodyBullets {
+		for _, bullet := range commit.Bullets {
+			line += fmt.Sprintf("  - %s\n", bullet)
+		}
+	}
+
+	return line
+}
+
+// formatBoards lists the commit's board IDs and the issues only its body
+// references, linked when board_url or issue_url is set.
+func (g *Generator) formatBoards(commit *parser.Commit) string {
+	boards := append([]string(nil), commit.Boards...)
+	if len(boards) == 0 && commit.Board != "" {
+		boards = []string{commit.Board}
+	}
+	for _, ref := range commit.References {
+		if !commit.HasBoard(ref.Issue) && !strings.Contains(commit.Message, ref.Issue) {
+			boards = append(boards, ref.Issue)
+		}
+	}
+
+	formatted := make([]string, 0, len(boards))
+	for _, board := range boards {
+		formatted = append(formatted, g.formatIssue(board))
+	}
+
+	return strings.Join(formatted, ", ")
+}
+
+// formatIssue links a board ID with board_url, or an issue number like #12
+// with issue_url, when they are set. Advisory IDs are always linked.
+func (g *Generator) formatIssue(issue string) string {
+	if link := advisoryURL(issue); link != "" {
+		return fmt.Sprintf("[%s](%s)", issue, link)
+	}
+
+	link := strings.ReplaceAll(g.config.BoardURL, "{board}", issue)
+	if number, ok := strings.CutPrefix(issue, "#"); ok {
+		link = strings.ReplaceAll(g.config.IssueURL, "{issue}", number)
+	}
+	if link == "" {
+		return issue
+	}
+	return fmt.Sprintf("[%s](%s)", issue, link)
+}
+
+// advisoryID matches the advisory IDs of parser.AdvisoryPattern in text.
+var advisoryID = regexp.MustCompile(`\b(?:` + parser.AdvisoryPattern + `)\b`)
+
+// advisoryURL returns the page of a CVE on the NVD or of a GitHub security
+// advisory, or "" when id is neither.
+func advisoryURL(id string) string {
+	switch {
+	case !advisoryID.MatchString(id):
+		return ""
+	case strings.HasPrefix(id, "CVE-"):
+		return "https://nvd.nist.gov/vuln/detail/" + id
+	default:
+		return "https://github.com/advisories/" + id
+	}
+}
+
+// linkAdvisories links the advisory IDs in desc, "fix XSS (CVE-2024-3094)".
+func linkAdvisories(desc string) string {
+	return advisoryID.ReplaceAllStringFunc(desc, func(id string) string {
+		return fmt.Sprintf("[%s](%s)", id, advisoryURL(id))
+	})
+}
+
+// formatPR renders a pull request number as (#482), linked when pr_url is set.
+func (g *Generator) formatPR(pr int) string {
+	if g.config.PRURL == "" {
+		return fmt.Sprintf("(#%d)", pr)
+	}
+	link := strings.ReplaceAll(g.config.PRURL, "{pr}", strconv.Itoa(pr))
+	return fmt.Sprintf("([#%d](%s))", pr, link)
+}
+
+// FilterBoards keeps only the commits that reference one of boards.
+func FilterBoards(commits []*parser.Commit, boards []string) []*parser.Commit {
+	if len(boards) == 0 {
+		return commits
+	}
+
+	var filtered []*parser.Commit
+	for _, commit := range commits {
+		for _, board := range boards {
+			if commit.HasBoard(board) {
+				filtered = append(filtered, commit)
+				break
+			}
+		}
+	}
+
+	return filtered
+}
+
+// write sends entry to the injected writer, or prepends it to the changelog file.
+func (g *Generator) write(entry string) error {
+	if g.output != nil {
+		if _, err := io.WriteString(g.output, entry); err != nil {
+			return fmt.Errorf("failed to write changelog: %w", err)
+		}
+		return nil
+	}
+
+	return g.appendToFile(entry)
 }
 
 func (g *Generator) appendToFile(entry string) error {
@@ -203,13 +642,119 @@ func (g *Generator) appendToFile(entry string) error {
 		newContent.WriteString(lines[i] + "\n")
 	}
 
-	if err := os.WriteFile(g.filePath, []byte(newContent.String()), 0644); err != nil {
+	if err := fsutil.WriteFile(g.filePath, []byte(newContent.String())); err != nil {
 		return fmt.Errorf("failed to write changelog: %w", err)
 	}
 
 	return nil
 }
 
+// ReadReleaseNotes returns the content of a hand-written release notes file,
+// or an empty string if it is missing or blank.
+func ReadReleaseNotes(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read release notes: %w", err)
+	}
+
+	return strings.TrimSpace(string(content)), nil
+}
+
+// ReleaseNotesArchivePath returns where ArchiveReleaseNotes keeps the notes for version.
+func ReleaseNotesArchivePath(archiveDir, version string) string {
+	if archiveDir == "" {
+		return ""
+	}
+	return filepath.Join(archiveDir, version+".md")
+}
+
+// ArchiveReleaseNotes copies the notes into archiveDir/<version>.md (when set)
+// and clears the notes file. It returns the paths it wrote.
+func ArchiveReleaseNotes(path, archiveDir, version string) ([]string, error) {
+	var touched []string
+
+	if archiveDir != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read release notes: %w", err)
+		}
+
+		if err := os.MkdirAll(archiveDir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create archive directory: %w", err)
+		}
+
+		archivePath := ReleaseNotesArchivePath(archiveDir, version)
+		if err := os.WriteFile(archivePath, content, 0644); err != nil {
+			return nil, fmt.Errorf("failed to archive release notes: %w", err)
+		}
+		touched = append(touched, archivePath)
+	}
+
+	if err := fsutil.WriteFile(path, nil); err != nil {
+		return nil, fmt.Errorf("failed to clear release notes: %w", err)
+	}
+	touched = append(touched, path)
+
+	return touched, nil
+}
+
+// MarkYanked returns changelog content with the entry for version flagged
+// as yanked and the reason recorded below its header, following the Keep a
+// Changelog convention. version is written as in the header, e.g. v1.2.3
+// with the v-prefix format.
+func MarkYanked(content, version, reason string) (string, error) {
+	lines := strings.Split(content, "\n")
+	header := fmt.Sprintf("## [%s]", version)
+
+	for i, line := range lines {
+		if !strings.HasPrefix(line, header) {
+			continue
+		}
+
+		if strings.HasSuffix(line, "[YANKED]") {
+			return "", fmt.Errorf("version %s is already marked as yanked", version)
+		}
+		lines[i] = line + " [YANKED]"
+
+		if reason != "" {
+			note := []string{"", fmt.Sprintf("> **Yanked:** %s", reason)}
+			lines = append(lines[:i+1], append(note, lines[i+1:]...)...)
+		}
+		return strings.Join(lines, "\n"), nil
+	}
+
+	return "", fmt.Errorf("version %s not found", version)
+}
+
+// Entry returns the body of the entry for version in changelog content,
+// without its "## [version]" header, or false when there is none. version is
+// written as in the header, e.g. v1.2.3 with the v-prefix format.
+func Entry(content, version string) (string, bool) {
+	header := fmt.Sprintf("## [%s]", version)
+
+	var body []string
+	found := false
+	for _, line := range strings.Split(content, "\n") {
+		if !found {
+			found = strings.HasPrefix(line, header)
+			continue
+		}
+		if strings.HasPrefix(line, "## ") {
+			break
+		}
+		body = append(body, line)
+	}
+
+	return strings.TrimSpace(strings.Join(body, "\n")), found
+}
+
 func GetCommitsSinceVersion(commits []*parser.Commit, version string) []*parser.Commit {
 	return commits
 }
@@ -0,0 +1,27 @@
+package changelog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkYanked(t *testing.T) {
+	content := "# Changelog\n\n## [1.3.0] - 2024-03-15\n\n### Features\n\n- add export\n\n## [1.2.0] - 2024-02-01\n\n- initial\n"
+
+	got, err := MarkYanked(content, "1.3.0", "corrupts exports")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# Changelog\n\n## [1.3.0] - 2024-03-15 [YANKED]\n\n> **Yanked:** corrupts exports\n\n### Features\n\n- add export\n\n## [1.2.0] - 2024-02-01\n\n- initial\n"
+	if got != want {
+		t.Errorf("MarkYanked() =\n%s\nwant\n%s", got, want)
+	}
+
+	if _, err := MarkYanked(got, "1.3.0", ""); err == nil || !strings.Contains(err.Error(), "already marked as yanked") {
+		t.Errorf("MarkYanked() of a yanked version = %v, want an already yanked error", err)
+	}
+
+	if _, err := MarkYanked(content, "1.4.0", ""); err == nil || !strings.Contains(err.Error(), "version 1.4.0 not found") {
+		t.Errorf("MarkYanked() of a missing version = %v, want a not found error", err)
+	}
+}
@@ -0,0 +1,129 @@
+package changelog
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/yendefrr/commet/internal/parser"
+)
+
+var (
+	// gitmojiCode matches a leading :shortcode: as used by gitmoji.
+	gitmojiCode = regexp.MustCompile(`^:[a-z0-9_+-]+:`)
+
+	// boardCache holds boardPrefix and ticketNoise for the last board
+	// pattern asked for, rebuilt when it changes.
+	boardCache struct {
+		sync.Mutex
+		expr        string
+		boardPrefix *regexp.Regexp
+		ticketNoise *regexp.Regexp
+	}
+)
+
+// boardRegexps returns boardPrefix, matching a leading board ID such as
+// "B-123", "[B-123]" or "B-123:", as left in descriptions by ad-hoc commit
+// styles, and ticketNoise, matching trailing ticket references: "(B-123)",
+// "[B-123]", "B-123", "(#42)", "refs #42", "closes B-123" and the like. Board
+// IDs match expr, the board pattern of the parser; with boards disabled expr
+// is "", boardPrefix is nil and ticketNoise only matches "#42".
+func boardRegexps(expr string) (*regexp.Regexp, *regexp.Regexp) {
+	boardCache.Lock()
+	defer boardCache.Unlock()
+
+	if boardCache.ticketNoise != nil && boardCache.expr == expr {
+		return boardCache.boardPrefix, boardCache.ticketNoise
+	}
+
+	ticket := `#\d+`
+	boardCache.boardPrefix = nil
+	if expr != "" {
+		ticket = `(?:` + expr + `)|#\d+`
+		boardCache.boardPrefix = regexp.MustCompile(`^\[?(?:` + expr + `)\]?[:\-]?(?:\s+|$)`)
+	}
+	boardCache.ticketNoise = regexp.MustCompile(`(?i)[\s,;:-]*(?:(?:refs?|see|closes?|fixe?s?|resolves?)\s+)?[(\[]?(?:` + ticket + `)[)\]]?\s*$`)
+	boardCache.expr = expr
+
+	return boardCache.boardPrefix, boardCache.ticketNoise
+}
+
+// boardPattern returns the board pattern of the parser, "" with boards
+// disabled.
+func (g *Generator) boardPattern() string {
+	switch {
+	case g.config.DisableBoards:
+		return ""
+	case g.config.BoardPattern == "":
+		return parser.DefaultBoardPattern
+	}
+	return g.config.BoardPattern
+}
+
+// normalizeDescription makes descriptions read alike: trailing ticket
+// references and periods are dropped and the first letter is capitalized.
+// Board IDs match the board pattern board.
+func normalizeDescription(desc, board string) string {
+	_, ticketNoise := boardRegexps(board)
+	desc = strings.TrimSpace(desc)
+	for {
+		trimmed := strings.TrimRight(ticketNoise.ReplaceAllString(desc, ""), " .")
+		if trimmed == desc || trimmed == "" {
+			break
+		}
+		desc = trimmed
+	}
+
+	r, size := utf8.DecodeRuneInString(desc)
+	if size == 0 {
+		return desc
+	}
+
+	return string(unicode.ToUpper(r)) + desc[size:]
+}
+
+// cleanDescription removes leading emoji and gitmoji shortcodes (stripEmoji)
+// and board IDs matching board (stripBoards) from a commit description, in
+// any order.
+func cleanDescription(desc, board string, stripEmoji, stripBoards bool) string {
+	boardPrefix, _ := boardRegexps(board)
+	for {
+		trimmed := strings.TrimLeftFunc(desc, unicode.IsSpace)
+
+		if stripEmoji {
+			if loc := gitmojiCode.FindStringIndex(trimmed); loc != nil {
+				desc = trimmed[loc[1]:]
+				continue
+			}
+			if r, size := utf8.DecodeRuneInString(trimmed); size > 0 && isEmoji(r) {
+				desc = trimmed[size:]
+				continue
+			}
+		}
+
+		if stripBoards && boardPrefix != nil {
+			if loc := boardPrefix.FindStringIndex(trimmed); loc != nil {
+				desc = trimmed[loc[1]:]
+				continue
+			}
+		}
+
+		return trimmed
+	}
+}
+
+// isEmoji reports whether r is an emoji or one of the joiners and modifiers
+// that make up emoji sequences.
+func isEmoji(r rune) bool {
+	switch {
+	case unicode.Is(unicode.So, r):
+		return true
+	case r == 0x200D, r == 0xFE0F, r == 0x20E3: // ZWJ, variation selector, keycap
+		return true
+	case r >= 0x1F3FB && r <= 0x1F3FF: // skin tones
+		return true
+	}
+	return false
+}
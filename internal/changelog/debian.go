@@ -0,0 +1,154 @@
+package changelog
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/yendefrr/commet/internal/config"
+	"github.com/yendefrr/commet/internal/fsutil"
+	"github.com/yendefrr/commet/internal/parser"
+)
+
+var debianHeaderPattern = regexp.MustCompile(`^([a-z0-9][a-z0-9.+-]*)\s+\(`)
+
+// DebianGenerator prepends stanzas to a debian/changelog file.
+type DebianGenerator struct {
+	config config.DebianConfig
+	clock  Clock
+}
+
+func NewDebianGenerator(cfg config.DebianConfig) *DebianGenerator {
+	return &DebianGenerator{config: cfg, clock: time.Now}
+}
+
+// WithClock dates stanzas with clock instead of time.Now.
+func (d *DebianGenerator) WithClock(clock Clock) *DebianGenerator {
+	d.clock = clock
+	return d
+}
+
+func (d *DebianGenerator) Generate(version string, commits []*parser.Commit) error {
+	stanza, err := d.Render(version, commits)
+	if err != nil {
+		return err
+	}
+
+	existing, err := os.ReadFile(d.config.File)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", d.config.File, err)
+	}
+
+	if dir := filepath.Dir(d.config.File); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create directory %s: %w", dir, err)
+		}
+	}
+
+	if err := fsutil.WriteFile(d.config.File, append([]byte(stanza), existing...)); err != nil {
+		return fmt.Errorf("failed to write %s: %w", d.config.File, err)
+	}
+
+	return nil
+}
+
+// Render returns a debian/changelog stanza for version with commits grouped by type.
+func (d *DebianGenerator) Render(version string, commits []*parser.Commit) (string, error) {
+	pkg, err := d.packageName()
+	if err != nil {
+		return "", err
+	}
+
+	maintainer := d.maintainer()
+	if maintainer == "" {
+		return "", fmt.Errorf("debian.maintainer is required (or set DEBFULLNAME and DEBEMAIL)")
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s (%s) %s; urgency=%s\n\n", pkg, DebianVersion(version, d.config.Revision), d.config.Distribution, d.config.Urgency))
+
+	groups := GroupCommits(commits, config.ChangelogConfig{})
+	for _, group := range groups {
+		if len(group.Commits) == 0 {
+			continue
+		}
+
+		sb.WriteString(fmt.Sprintf("  * %s:\n", group.Description))
+		for _, commit := range group.Commits {
+			line := commit.Description
+			if commit.Scope != "" {
+				line = commit.Scope + ": " + line
+			}
+			sb.WriteString(fmt.Sprintf("    - %s\n", line))
+		}
+	}
+
+	if len(groups) == 0 {
+		sb.WriteString("  * New upstream release.\n")
+	}
+
+	sb.WriteString(fmt.Sprintf("\n -- %s  %s\n\n", maintainer, d.clock().Format(time.RFC1123Z)))
+
+	return sb.String(), nil
+}
+
+// DebianVersion converts a semantic version to a Debian package version:
+// the v prefix is dropped, pre-releases sort before the release (1.0.0~rc.1)
+// and the Debian revision is appended.
+func DebianVersion(version, revision string) string {
+	version = strings.TrimPrefix(version, "v")
+	version, _, _ = strings.Cut(version, "+")
+	version = strings.Replace(version, "-", "~", 1)
+
+	if revision == "" {
+		return version
+	}
+	return version + "-" + revision
+}
+
+// packageName returns the configured package or the one named by the
+// existing changelog or debian/control.
+func (d *DebianGenerator) packageName() (string, error) {
+	if d.config.Package != "" {
+		return d.config.Package, nil
+	}
+
+	if file, err := os.Open(d.config.File); err == nil {
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		if scanner.Scan() {
+			if matches := debianHeaderPattern.FindStringSubmatch(scanner.Text()); matches != nil {
+				return matches[1], nil
+			}
+		}
+	}
+
+	control := filepath.Join(filepath.Dir(d.config.File), "control")
+	if file, err := os.Open(control); err == nil {
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			if name, ok := strings.CutPrefix(scanner.Text(), "Source:"); ok {
+				return strings.TrimSpace(name), nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("debian.package is required (not found in %s or %s)", d.config.File, control)
+}
+
+func (d *DebianGenerator) maintainer() string {
+	if d.config.Maintainer != "" {
+		return d.config.Maintainer
+	}
+
+	name, email := os.Getenv("DEBFULLNAME"), os.Getenv("DEBEMAIL")
+	if name == "" || email == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s <%s>", name, email)
+}
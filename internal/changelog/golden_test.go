@@ -0,0 +1,238 @@
+package changelog
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yendefrr/commet/internal/config"
+	"github.com/yendefrr/commet/internal/parser"
+)
+
+// Run "go test ./internal/changelog -update" to rewrite the golden files
+// after an intended change to the rendered output.
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+func fixedClock() time.Time {
+	return time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+}
+
+var goldenMessages = []string{
+	"Feature(api): add export endpoint",
+	"Fix: handle empty responses.",
+	"B-123 B-456(auth): Fix token refresh race",
+	"J-77(parser,regex): <Refactor> split tokenizer",
+	"Docs: describe board links",
+	"Perf(cache): skip cold lookups",
+	"Fix!(core): drop legacy endpoint",
+	"Feature: ✨ :sparkles: [B-9] dark mode",
+}
+
+func goldenCommits(t *testing.T) []*parser.Commit {
+	t.Helper()
+
+	commits := make([]*parser.Commit, 0, len(goldenMessages))
+	for i, message := range goldenMessages {
+		commit, err := parser.Parse(message)
+		if err != nil {
+			t.Fatalf("failed to parse %q: %v", message, err)
+		}
+		commit.Hash = []string{"a1b2c3d", "b2c3d4e", "c3d4e5f", "d4e5f6a", "e5f6a7b", "f6a7b8c", "a7b8c9d", "b8c9d0e"}[i]
+		commit.Author = "Jane Doe"
+		commits = append(commits, commit)
+	}
+
+	return commits
+}
+
+func TestGolden(t *testing.T) {
+	commits := goldenCommits(t)
+
+	tests := []struct {
+		name   string
+		render func() (string, error)
+	}{
+		{"default", func() (string, error) {
+			return NewGenerator("", config.ChangelogConfig{}).WithClock(fixedClock).Render("1.3.0", commits)
+		}},
+		{"include_types", func() (string, error) {
+			cfg := config.ChangelogConfig{IncludeTypes: []string{"Feature", "Fix"}}
+			return NewGenerator("", cfg).WithClock(fixedClock).Render("1.3.0", commits)
+		}},
+		{"type_template", func() (string, error) {
+			cfg := config.ChangelogConfig{Types: map[string]config.ChangelogTypeConfig{
+				"Feature": {Template: "- {{.Description}}{{if .Scope}} ({{.Scope}}){{end}} by {{.Author}}"},
+			}}
+			return NewGenerator("", cfg).WithClock(fixedClock).Render("1.3.0", commits)
+		}},
+		{"board_url", func() (string, error) {
+			cfg := config.ChangelogConfig{BoardURL: "https://jira.example.com/browse/{board}"}
+			return NewGenerator("", cfg).WithClock(fixedClock).Render("1.3.0", commits)
+		}},
+		{"clean", func() (string, error) {
+			cfg := config.ChangelogConfig{StripEmoji: true, StripBoards: true, Normalize: true}
+			return NewGenerator("", cfg).WithClock(fixedClock).Render("1.3.0", commits)
+		}},
+		{"locale", func() (string, error) {
+			cfg := config.ChangelogConfig{
+				Locale:           "zh",
+				Titles:           map[string]string{"Feature": "新功能", "Fix": "问题修复", "Other": "其他"},
+				TranslateCommand: "sed 's/endpoint/接口/g'",
+			}
+			return NewGenerator("", cfg).WithClock(fixedClock).Render("1.3.0", commits)
+		}},
+		{"breaking_footer", func() (string, error) {
+			breaking, err := parser.Parse("Feature(api): switch to cursor pagination\n\nPages are requested by cursor now.\n\nBREAKING CHANGE: the page parameter is gone,\npass the cursor from the previous response instead.\nRefs: #42")
+			if err != nil {
+				return "", err
+			}
+			breaking.Hash = "c9d0e1f"
+			return NewGenerator("", config.ChangelogConfig{}).WithClock(fixedClock).Render("2.0.0", append(commits[:2:2], breaking))
+		}},
+		{"issue_url", func() (string, error) {
+			fix, err := parser.Parse("Fix(auth): token refresh race\n\nCloses #12\nRefs: OPS-7")
+			if err != nil {
+				return "", err
+			}
+			fix.Hash = "c9d0e1f"
+			cfg := config.ChangelogConfig{
+				IssueURL: "https://github.com/acme/app/issues/{issue}",
+				BoardURL: "https://jira.example.com/browse/{board}",
+			}
+			return NewGenerator("", cfg).WithClock(fixedClock).Render("1.2.4", []*parser.Commit{fix})
+		}},
+		{"board_pattern", func() (string, error) {
+			p, err := parser.New(parser.Options{BoardPattern: `[a-z]+_\d+`})
+			if err != nil {
+				return "", err
+			}
+
+			fix, err := p.Parse("proj_1234(auth): Fix token refresh race (ops_7)")
+			if err != nil {
+				return "", err
+			}
+			fix.Hash = "c9d0e1f"
+			cfg := config.ChangelogConfig{BoardURL: "https://tracker.example.com/{board}", Normalize: true, BoardPattern: p.BoardPattern()}
+			return NewGenerator("", cfg).WithClock(fixedClock).Render("1.2.4", []*parser.Commit{fix})
+		}},
+		{"body_bullets", func() (string, error) {
+			detailed, err := parser.Parse("Feature(api): cursor pagination\n\nPages are requested by cursor now:\n\n- add the cursor parameter to every list\n  endpoint\n- return next_cursor in responses\n\nRefs: #42")
+			if err != nil {
+				return "", err
+			}
+			detailed.Hash = "c9d0e1f"
+			cfg := config.ChangelogConfig{BodyBullets: true}
+			return NewGenerator("", cfg).WithClock(fixedClock).Render("1.3.0", append(commits[:1:1], detailed))
+		}},
+		{"security", func() (string, error) {
+			security, err := parser.Parse("Security(auth): escape redirect targets (CVE-2024-3094)\n\nSee GHSA-2c8m-4xqh-9p6f")
+			if err != nil {
+				return "", err
+			}
+			security.Hash = "c9d0e1f"
+			return NewGenerator("", config.ChangelogConfig{}).WithClock(fixedClock).Render("1.2.4", append(commits[:2:2], security))
+		}},
+		{"footer_template", func() (string, error) {
+			reviewed, err := parser.Parse("Feature(auth): add SSO\n\nReviewed-by: Ana <ana@example.com>\nRefs: RFC-12")
+			if err != nil {
+				return "", err
+			}
+			reviewed.Hash = "c9d0e1f"
+			fix, err := parser.Parse("Fix(auth): token refresh race\n\nReviewed-by: Ben <ben@example.com>\nreviewed-by: Ana <ana@example.com>")
+			if err != nil {
+				return "", err
+			}
+			fix.Hash = "d0e1f2a"
+			cfg := config.ChangelogConfig{FooterTemplate: `{{with .Trailers "Refs"}}### References
+
+{{range .}}- {{.}}
+{{end}}{{end}}
+{{with .Trailers "Reviewed-by"}}Reviewed by {{range $i, $r := .}}{{if $i}}, {{end}}{{$r}}{{end}} for {{$.Version}}.{{end}}`}
+			return NewGenerator("", cfg).WithClock(fixedClock).Render("1.3.0", []*parser.Commit{reviewed, fix})
+		}},
+		{"contributors", func() (string, error) {
+			paired, err := parser.Parse("Feature(auth): add SSO\n\nCo-authored-by: Ben Ortiz <ben@example.com>\nCo-authored-by: ana <ana@example.com>")
+			if err != nil {
+				return "", err
+			}
+			paired.Hash, paired.Author = "c9d0e1f", "Carla Diaz"
+			solo := *commits[0]
+			solo.Author = "Ben Ortiz"
+			cfg := config.ChangelogConfig{Contributors: true}
+			return NewGenerator("", cfg).WithClock(fixedClock).Render("1.3.0", []*parser.Commit{&solo, paired})
+		}},
+		{"pr_url", func() (string, error) {
+			squashed, err := parser.Parse("Feature(auth): add SSO (#482)")
+			if err != nil {
+				return "", err
+			}
+			squashed.Hash = "c9d0e1f"
+			cfg := config.ChangelogConfig{PRURL: "https://github.com/acme/app/pull/{pr}"}
+			return NewGenerator("", cfg).WithClock(fixedClock).Render("1.3.0", append(commits[:2:2], squashed))
+		}},
+		{"notes", func() (string, error) {
+			return NewGenerator("", config.ChangelogConfig{}).WithClock(fixedClock).RenderNotes("1.3.0", "\nHand-written notes.\n\n- One\n- Two\n"), nil
+		}},
+		{"debian", func() (string, error) {
+			cfg := config.DebianConfig{
+				Package:      "commet",
+				Distribution: "unstable",
+				Urgency:      "medium",
+				Maintainer:   "Jane Doe <jane@example.com>",
+				Revision:     "1",
+			}
+			return NewDebianGenerator(cfg).WithClock(fixedClock).Render("1.3.0-rc.1", commits)
+		}},
+		{"debian_release_date", func() (string, error) {
+			cfg := config.DebianConfig{
+				Package:      "commet",
+				Distribution: "stable",
+				Urgency:      "low",
+				Maintainer:   "Jane Doe <jane@example.com>",
+			}
+			released := time.Date(2024, 2, 1, 9, 30, 0, 0, time.FixedZone("", 2*60*60))
+			clock := ReleaseClock(config.ChangelogConfig{ReleaseDate: released})
+			return NewDebianGenerator(cfg).WithClock(clock).Render("v1.2.0", commits[:5])
+		}},
+		{"writer", func() (string, error) {
+			var buf bytes.Buffer
+			err := NewGenerator("", config.ChangelogConfig{}).WithClock(fixedClock).WithWriter(&buf).Generate("1.3.0", commits[:2])
+			return buf.String(), err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.render()
+			if err != nil {
+				t.Fatalf("render: %v", err)
+			}
+			assertGolden(t, filepath.Join("testdata", tt.name+".golden"), got)
+		})
+	}
+}
+
+// assertGolden compares got with the golden file at path, or rewrites the
+// file when -update is set.
+func assertGolden(t *testing.T, path, got string) {
+	t.Helper()
+
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatalf("failed to update %s: %v", path, err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s (run with -update to create it): %v", path, err)
+	}
+
+	if got != string(want) {
+		t.Errorf("output differs from %s (run with -update to accept it)\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
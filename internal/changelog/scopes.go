@@ -0,0 +1,28 @@
+package changelog
+
+import (
+	"regexp"
+
+	"github.com/yendefrr/commet/internal/parser"
+)
+
+// scopeLabel matches the bold scope list of a rendered entry line, e.g. "- **api,core**: ...".
+var scopeLabel = regexp.MustCompile(`(?m)^(\s*- \*\*)([^*\n]+)(\*\*: )`)
+
+// RenameScopes rewrites the scopes of changelog lines according to mapping and
+// returns the new content with the number of lines changed. Only lines in the
+// default entry format are recognized; custom type templates are left alone.
+func RenameScopes(content string, mapping map[string]string) (string, int) {
+	changed := 0
+	content = scopeLabel.ReplaceAllStringFunc(content, func(line string) string {
+		parts := scopeLabel.FindStringSubmatch(line)
+		renamed := parser.RenameScopes(parts[2], mapping)
+		if renamed == parts[2] {
+			return line
+		}
+		changed++
+		return parts[1] + renamed + parts[3]
+	})
+
+	return content, changed
+}
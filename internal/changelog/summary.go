@@ -0,0 +1,120 @@
+package changelog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/yendefrr/commet/internal/config"
+	"github.com/yendefrr/commet/internal/parser"
+)
+
+// summaryTimeout bounds how long the summary and translate commands may run.
+const summaryTimeout = 2 * time.Minute
+
+// summaryInput is the JSON document written to the summary command's stdin.
+type summaryInput struct {
+	Version string         `json:"version"`
+	Groups  []summaryGroup `json:"groups"`
+}
+
+type summaryGroup struct {
+	Type    string          `json:"type"`
+	Title   string          `json:"title"`
+	Commits []summaryCommit `json:"commits"`
+}
+
+type summaryCommit struct {
+	Hash        string   `json:"hash,omitempty"`
+	SHA         string   `json:"sha,omitempty"`
+	Author      string   `json:"author,omitempty"`
+	Scope       string   `json:"scope,omitempty"`
+	Boards      []string `json:"boards,omitempty"`
+	Description string   `json:"description"`
+	Breaking    bool     `json:"breaking,omitempty"`
+	// BreakingChange is the text of a BREAKING CHANGE footer
+	BreakingChange string `json:"breaking_change,omitempty"`
+}
+
+// Summarize runs the summary command of cfg once for the release of version
+// and keeps its output in cfg. Generators of cfg, and of the targets and
+// locales it yields afterwards, then render that output instead of running
+// the command for every entry.
+func Summarize(cfg *config.ChangelogConfig, version string, commits []*parser.Commit) error {
+	if cfg.SummaryCommand == "" || cfg.Summarized {
+		return nil
+	}
+
+	summary, err := summarize(cfg.SummaryCommand, version, NewGenerator(cfg.File, *cfg).Groups(commits))
+	if err != nil {
+		return err
+	}
+	cfg.Summary, cfg.Summarized = summary, true
+	return nil
+}
+
+// summarize runs changelog.summary_command through the shell with the grouped
+// commits as JSON on stdin and returns its trimmed stdout. COMMET_VERSION is
+// set in its environment.
+func summarize(command, version string, groups []*CommitGroup) (string, error) {
+	input := summaryInput{Version: version}
+	for _, group := range groups {
+		sg := summaryGroup{Type: group.Type, Title: group.Description}
+		for _, commit := range group.Commits {
+			sg.Commits = append(sg.Commits, summaryCommit{
+				Hash:           commit.Hash,
+				SHA:            commit.FullHash,
+				Author:         commit.Author,
+				Scope:          commit.Scope,
+				Boards:         commit.Boards,
+				Description:    commit.Description,
+				Breaking:       commit.ForceMajor,
+				BreakingChange: commit.BreakingChange,
+			})
+		}
+		input.Groups = append(input.Groups, sg)
+	}
+
+	payload, err := json.Marshal(input)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode commits for summary: %w", err)
+	}
+
+	return runCommand("summary", command, payload, "COMMET_VERSION="+version)
+}
+
+// runCommand runs command through the shell with stdin and the extra
+// environment variables and returns its trimmed stdout. name identifies the
+// hook in errors.
+func runCommand(name, command string, stdin []byte, env ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), summaryTimeout)
+	defer cancel()
+
+	shell, flag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+
+	cmd := exec.CommandContext(ctx, shell, flag, command)
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Env = append(os.Environ(), env...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s command failed: %w: %s", name, err, msg)
+		}
+		return "", fmt.Errorf("%s command failed: %w", name, err)
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
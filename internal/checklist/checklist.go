@@ -0,0 +1,79 @@
+// Package checklist runs the release gates of the [checklist] config
+// section: named shell commands that must all pass before a release.
+package checklist
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Result is the outcome of one check.
+type Result struct {
+	Name     string
+	Command  string
+	Skipped  bool
+	Err      error
+	Output   string
+	Duration time.Duration
+}
+
+// Passed reports whether the check succeeded or was skipped.
+func (r Result) Passed() bool {
+	return r.Skipped || r.Err == nil
+}
+
+// Run executes every check in parallel through the shell, with the given
+// extra environment, and returns the results sorted by name. Checks named in
+// skip are not run.
+func Run(checks map[string]string, skip []string, env []string) []Result {
+	skipped := make(map[string]bool, len(skip))
+	for _, name := range skip {
+		skipped[name] = true
+	}
+
+	results := make([]Result, 0, len(checks))
+	for name, command := range checks {
+		results = append(results, Result{Name: name, Command: command, Skipped: skipped[name]})
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+
+	var wg sync.WaitGroup
+	for i := range results {
+		if results[i].Skipped {
+			continue
+		}
+
+		wg.Add(1)
+		go func(result *Result) {
+			defer wg.Done()
+			start := time.Now()
+			result.Output, result.Err = run(result.Command, env)
+			result.Duration = time.Since(start)
+		}(&results[i])
+	}
+	wg.Wait()
+
+	return results
+}
+
+// run executes command through the shell and returns its combined output.
+func run(command string, env []string) (string, error) {
+	shell, flag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+
+	var output bytes.Buffer
+	cmd := exec.Command(shell, flag, command)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	err := cmd.Run()
+	return output.String(), err
+}
@@ -0,0 +1,56 @@
+package clipboard
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+type command struct {
+	name string
+	args []string
+}
+
+func candidates() []command {
+	switch runtime.GOOS {
+	case "darwin":
+		return []command{{name: "pbcopy"}}
+	case "windows":
+		return []command{{name: "clip.exe"}, {name: "clip"}}
+	default:
+		return []command{
+			{name: "wl-copy"},
+			{name: "xclip", args: []string{"-selection", "clipboard"}},
+			{name: "xsel", args: []string{"--clipboard", "--input"}},
+			{name: "clip.exe"}, // WSL
+		}
+	}
+}
+
+// Copy writes text to the system clipboard using the first available platform tool.
+func Copy(text string) error {
+	for _, c := range candidates() {
+		path, err := exec.LookPath(c.name)
+		if err != nil {
+			continue
+		}
+
+		cmd := exec.Command(path, c.args...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("failed to copy with %s: %w", c.name, err)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("no clipboard tool found (tried %s)", strings.Join(names(), ", "))
+}
+
+func names() []string {
+	var result []string
+	for _, c := range candidates() {
+		result = append(result, c.name)
+	}
+	return result
+}
@@ -3,27 +3,275 @@ package config
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/yendefrr/commet/internal/parser"
 
 	"github.com/BurntSushi/toml"
+	"github.com/Masterminds/semver/v3"
 )
 
 type Config struct {
-	Version         VersionConfig       `toml:"version"`
-	BumpRules       map[string]BumpType `toml:"bump_rules"`
-	Detection       DetectionConfig     `toml:"detection"`
-	Git             GitConfig           `toml:"git"`
-	Changelog       ChangelogConfig     `toml:"changelog"`
-	AdditionalFiles []VersionConfig     `toml:"additional_files,omitempty"`
+	// RequiredVersion constrains the commet version, e.g. ">=1.5.0 <2"; see "commet env require"
+	RequiredVersion string               `toml:"required_version,omitempty"`
+	Version         VersionConfig        `toml:"version"`
+	BumpRules       map[string]BumpType  `toml:"bump_rules"`
+	Detection       DetectionConfig      `toml:"detection"`
+	Git             GitConfig            `toml:"git"`
+	Changelog       ChangelogConfig      `toml:"changelog"`
+	Snapshot        SnapshotConfig       `toml:"snapshot"`
+	Debian          DebianConfig         `toml:"debian"`
+	Policy          PolicyConfig         `toml:"policy"`
+	Stats           StatsConfig          `toml:"stats"`
+	Package         PackageConfig        `toml:"package,omitempty"`
+	Rollup          RollupConfig         `toml:"rollup,omitempty"`
+	ReleasePR       ReleasePRConfig      `toml:"release_pr,omitempty"`
+	Parser          ParserConfig         `toml:"parser,omitempty"`
+	Pipeline        PipelineConfig       `toml:"pipeline,omitempty"`
+	Lint            LintConfig           `toml:"lint,omitempty"`
+	AdditionalFiles []VersionConfig      `toml:"additional_files,omitempty"`
+	Notifications   []NotificationConfig `toml:"notifications,omitempty"`
+	PathRules       []PathRuleConfig     `toml:"path_rules,omitempty"`
+	Branches        []BranchConfig       `toml:"branches,omitempty"`
+	// Checklist maps check names to shell commands that must pass before a
+	// release, e.g. tests = "go test ./..."
+	Checklist map[string]string `toml:"checklist,omitempty"`
+	// BoardRules maps board prefixes to bumps, e.g. BUG = "patch" for BUG-123.
+	// They apply only to commits whose type has no bump rule.
+	BoardRules map[string]BumpType `toml:"board_rules,omitempty"`
+	// Preset adds the bump rules of a commit convention, e.g. "conventional"
+	// for feat/fix/chore; rules in bump_rules take precedence
+	Preset string `toml:"preset,omitempty"`
+	// Extends lists config files this one builds on, paths relative to it or
+	// HTTPS URLs pinned in .commet.lock; settings here take precedence
+	Extends []string `toml:"extends,omitempty"`
+	// BumpLevels defines custom bump levels for rules and --bump, each
+	// raising the version component of a built-in level, e.g.
+	// hotfix = "build" with version.format "four-part"
+	BumpLevels map[string]BumpType `toml:"bump_levels,omitempty"`
+	// TypeAliases renames commit types after parsing, e.g. feat = "Feature"
+	// and bugfix = "Fix", before bump rules and changelog groups apply
+	TypeAliases map[string]string `toml:"type_aliases,omitempty"`
+	// CaseInsensitiveTypes matches types against bump_rules and type_aliases
+	// ignoring case, so "FIX" and "fix" bump like "Fix"
+	CaseInsensitiveTypes bool `toml:"case_insensitive_types,omitempty"`
+
+	// commitParser parses commit messages with the settings above; see CommitParser
+	commitParser *parser.Parser
+}
+
+// BranchConfig maps branches to a release channel, e.g. branch = "develop"
+// with prerelease = "beta", or branch = "main" without one for stable releases.
+type BranchConfig struct {
+	// Branch is a branch name or glob pattern such as "release/*"
+	Branch string `toml:"branch"`
+	// Prerelease is the pre-release identifier of the channel; empty releases stable versions
+	Prerelease string `toml:"prerelease,omitempty"`
+	// Maintenance releases within the version line named by the last segment
+	// of the branch, e.g. 1.2.8 on release/1.2.x, from tags reachable from it
+	Maintenance bool `toml:"maintenance,omitempty"`
+}
+
+// ExpandVars replaces {name} placeholders for each entry of vars, such as
+// {owner} and {repo}, in every message, link and changelog or notification
+// template. Other placeholders like {version} are left alone.
+func (c *Config) ExpandVars(vars map[string]string) {
+	pairs := make([]string, 0, 2*len(vars))
+	for name, value := range vars {
+		pairs = append(pairs, "{"+name+"}", value)
+	}
+	replacer := strings.NewReplacer(pairs...)
+
+	for _, s := range []*string{
+		&c.Git.CommitMessage,
+		&c.Git.TagMessage,
+		&c.Git.PostReleaseCommitMessage,
+		&c.Snapshot.CommitMessage,
+		&c.Changelog.BoardURL,
+		&c.Changelog.PRURL,
+		&c.Changelog.IssueURL,
+		&c.Changelog.FooterTemplate,
+		&c.Policy.BoardCheckURL,
+	} {
+		*s = replacer.Replace(*s)
+	}
+
+	expandTypes := func(types map[string]ChangelogTypeConfig) {
+		for name, typeCfg := range types {
+			typeCfg.Template = replacer.Replace(typeCfg.Template)
+			types[name] = typeCfg
+		}
+	}
+	expandTypes(c.Changelog.Types)
+	for _, output := range c.Changelog.Outputs {
+		expandTypes(output.Types)
+	}
+	for _, locale := range c.Changelog.Locales {
+		expandTypes(locale.Types)
+	}
+
+	for i := range c.Notifications {
+		c.Notifications[i].Template = replacer.Replace(c.Notifications[i].Template)
+		c.Notifications[i].Subject = replacer.Replace(c.Notifications[i].Subject)
+	}
+}
+
+// Channel returns the first [[branches]] entry matching branch.
+func (c *Config) Channel(branch string) (BranchConfig, bool) {
+	for _, channel := range c.Branches {
+		if ok, _ := path.Match(channel.Branch, branch); ok {
+			return channel, true
+		}
+	}
+	return BranchConfig{}, false
+}
+
+// PathRuleConfig caps the bump of commits that only touch matching files,
+// e.g. paths = ["docs/**", "**/*_test.go"] with bump = "none".
+type PathRuleConfig struct {
+	Paths []string `toml:"paths"`
+	Bump  BumpType `toml:"bump"`
+}
+
+// CapByPaths lowers bump to the lowest bump of the path rules whose patterns
+// cover every file in files. Commits without file information are not capped.
+func (c *Config) CapByPaths(bump BumpType, files []string) BumpType {
+	if len(files) == 0 {
+		return bump
+	}
+
+	for _, rule := range c.PathRules {
+		if ruleBump := c.ResolveBump(rule.Bump); coversAll(rule.Paths, files) && bumpRank[ruleBump] < bumpRank[bump] {
+			bump = ruleBump
+		}
+	}
+
+	return bump
+}
+
+func coversAll(patterns, files []string) bool {
+	for _, file := range files {
+		covered := false
+		for _, pattern := range patterns {
+			if MatchPath(pattern, file) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+	return true
 }
 
 type VersionConfig struct {
 	File    string `toml:"file"`
 	Key     string `toml:"key"`
 	Initial string `toml:"initial"`
-	Format  string `toml:"format"` // "semver" or "v-prefix"
+	Format  string `toml:"format"` // "semver", "v-prefix", "calver", "four-part", "pep440" or a registered scheme
+
+	// CalVerPattern is the calver layout, e.g. "YYYY.MM.MICRO" or "0Y.0M.0D".
+	// Only read from [version]
+	CalVerPattern string `toml:"calver_pattern,omitempty"`
+
+	// BuildSegment is how the fourth segment of a four-part version
+	// (1.2.3.4) moves on patch, minor and major bumps: "reset" to 0 (default)
+	// or "increment" like a running build counter. Only read from [version]
+	BuildSegment string `toml:"build_segment,omitempty"`
+
+	// Template is how the version is written to this file, e.g.
+	// "{version}.{build}" for a four-part assembly version; {build} is the CI
+	// build number (BUILD_NUMBER, GITHUB_RUN_NUMBER, ...). Default "{version}"
+	Template string `toml:"template,omitempty"`
+
+	// CreateIfMissing writes a minimal file with the new version instead of skipping it
+	CreateIfMissing bool `toml:"create_if_missing,omitempty"`
+
+	// Paths limits updates to releases whose commits touch one of these
+	// files, directories or glob patterns (e.g. an API spec and its handlers)
+	Paths []string `toml:"paths,omitempty"`
+
+	// SyncLockfile also updates the root package entry in Cargo.lock
+	SyncLockfile bool `toml:"sync_lockfile,omitempty"`
+
+	// Prerelease makes releases pre-releases with this identifier (alpha, beta,
+	// rc), e.g. 1.3.0-rc.1 then 1.3.0-rc.2. Only read from [version]
+	Prerelease string `toml:"prerelease,omitempty"`
+
+	// ZeroVer keeps 0.x projects below 1.0.0: while the major version is 0,
+	// breaking changes bump minor and features bump patch. Only read from [version]
+	ZeroVer bool `toml:"zero_ver,omitempty"`
+
+	// MinBump is the smallest bump of a release with at least one commit, for
+	// teams that version every deploy even when all commits map to "none".
+	// Only read from [version]
+	MinBump BumpType `toml:"min_bump,omitempty"`
+
+	// BuildMetadata is appended to released versions after "+", e.g.
+	// "build.{env:BUILD_NUMBER}" or "sha.{sha}". Only read from [version]
+	BuildMetadata string `toml:"build_metadata,omitempty"`
+
+	// Max is the highest version line releases may reach, e.g. "1.x" for an
+	// LTS branch; crossing it needs --allow-max. Only read from [version]
+	Max string `toml:"max,omitempty"`
+
+	// Marker switches to the format-agnostic updater: the version is on the
+	// line after a comment containing this text, e.g. "commet:version"
+	Marker string `toml:"marker,omitempty"`
+}
+
+// Location describes where the version sits in the file, for messages.
+func (v VersionConfig) Location() string {
+	if v.Marker != "" {
+		return "after " + v.Marker
+	}
+	return v.Key
+}
+
+// Touches reports whether any of files falls under Paths. A file without
+// Paths is touched by every release.
+func (v VersionConfig) Touches(files []string) bool {
+	if len(v.Paths) == 0 {
+		return true
+	}
+
+	for _, file := range files {
+		for _, pattern := range v.Paths {
+			if MatchPath(pattern, file) {
+				return true
+			}
+		}
+	}
+
+	return false
 }
 
+// formats are the version.format values of schemes registered with
+// version.Register, built-in or custom.
+var formats = map[string]bool{}
+
+// RegisterFormat makes name a valid version.format. It is called by
+// version.Register; use that to add a scheme.
+func RegisterFormat(name string) {
+	formats[name] = true
+}
+
+// pep440Phases are the pre-release identifiers PEP 440 versions accept.
+var pep440Phases = map[string]bool{"a": true, "alpha": true, "b": true, "beta": true, "rc": true, "c": true}
+
+// prereleaseIdentifier matches a semver pre-release identifier without the counter.
+var prereleaseIdentifier = regexp.MustCompile(`^[0-9A-Za-z-]+$`)
+
+// versionLine matches a version line such as "1.x" or "1.4.x".
+var versionLine = regexp.MustCompile(`^[0-9]+(\.[0-9]+)*\.x$`)
+
 type BumpType string
 
 const (
@@ -31,12 +279,19 @@ const (
 	BumpPatch BumpType = "patch"
 	BumpMinor BumpType = "minor"
 	BumpMajor BumpType = "major"
+	// BumpBuild raises only the fourth segment of a four-part version
+	BumpBuild BumpType = "build"
 )
 
 type DetectionConfig struct {
 	Strategies    []string `toml:"strategies"`
 	TagPattern    string   `toml:"tag_pattern"`
 	ExcludeMerges bool     `toml:"exclude_merges"`
+	// IgnoreFile lists commit hashes or message regexes excluded from analysis and changelogs
+	IgnoreFile string `toml:"ignore_file,omitempty"`
+	// Strict fails a release when a commit in the range matches no commit
+	// pattern, instead of leaving it out
+	Strict bool `toml:"strict,omitempty"`
 }
 
 type GitConfig struct {
@@ -45,13 +300,470 @@ type GitConfig struct {
 	AutoTag       bool   `toml:"auto_tag"`
 	TagFormat     string `toml:"tag_format"`
 	TagMessage    string `toml:"tag_message"`
+	// TagType is "annotated" (default), with TagMessage, or "lightweight"
+	TagType string `toml:"tag_type,omitempty"`
+
+	// AliasTags also points floating major and minor tags at each stable
+	// release, e.g. v1 and v1.4 for v1.4.2, moving them if they exist
+	AliasTags bool `toml:"alias_tags,omitempty"`
+
+	// PostReleaseVersion is written to the version files after tagging, e.g. "{next_patch}-dev"
+	PostReleaseVersion       string `toml:"post_release_version,omitempty"`
+	PostReleaseCommitMessage string `toml:"post_release_commit_message,omitempty"`
+
+	// AutoPush pushes the release commits and tags once they are made,
+	// authenticating with the SSH agent or GITHUB_TOKEN/GITLAB_TOKEN on their hosts
+	AutoPush bool `toml:"auto_push,omitempty"`
+	// PushRemote is the remote pushed to (default "origin")
+	PushRemote string `toml:"push_remote,omitempty"`
+	// PushBranch is the remote branch the commits go to (default: the current branch)
+	PushBranch string `toml:"push_branch,omitempty"`
+	// GitHubHosts are GitHub Enterprise hosts trusted with GITHUB_TOKEN
+	// besides github.com, e.g. "github.example.com"
+	GitHubHosts []string `toml:"github_hosts,omitempty"`
+	// GitLabHosts are self-managed GitLab hosts trusted with GITLAB_TOKEN
+	// besides gitlab.com, e.g. "gitlab.example.com"
+	GitLabHosts []string `toml:"gitlab_hosts,omitempty"`
+
+	// Sign signs the release commits and annotated tags with GPG
+	Sign bool `toml:"sign,omitempty"`
+	// SigningKey is the GPG key ID (default: user.signingkey of the git config)
+	SigningKey string `toml:"signing_key,omitempty"`
+	// SigningKeyEnv names an environment variable holding an armored private
+	// key to sign with instead of gpg, e.g. a CI secret
+	SigningKeyEnv string `toml:"signing_key_env,omitempty"`
+	// SigningPassphraseEnv names the environment variable holding the passphrase of that key
+	SigningPassphraseEnv string `toml:"signing_passphrase_env,omitempty"`
 }
 
-type ChangelogConfig struct {
+// SnapshotConfig controls Maven-style development versions: after a release
+// is tagged, the next version with Suffix is written back to the version files.
+type SnapshotConfig struct {
+	Enabled       bool     `toml:"enabled"`
+	Suffix        string   `toml:"suffix"`
+	Bump          BumpType `toml:"bump"`
+	CommitMessage string   `toml:"commit_message"`
+}
+
+// DebianConfig controls the debian/changelog stanza written on release.
+type DebianConfig struct {
+	Enabled      bool   `toml:"enabled"`
+	File         string `toml:"file"`
+	Package      string `toml:"package,omitempty"`
+	Distribution string `toml:"distribution"`
+	Urgency      string `toml:"urgency"`
+	Maintainer   string `toml:"maintainer,omitempty"` // "Name <email>", defaults to DEBFULLNAME/DEBEMAIL
+	Revision     string `toml:"revision"`
+}
+
+// StatsConfig controls the opt-in local usage statistics. Nothing is sent
+// anywhere; records are appended to File and read by "commet stats --self".
+type StatsConfig struct {
 	Enabled bool   `toml:"enabled"`
 	File    string `toml:"file"`
 }
 
+// RollupConfig lets many small changes add up to a bigger release instead of
+// the highest single commit deciding the bump. A threshold of 0 disables it.
+type RollupConfig struct {
+	// PatchThreshold is how many patch-level commits roll up to a minor bump
+	PatchThreshold int `toml:"patch_threshold,omitempty"`
+	// MinorThreshold is how many minor-level commits roll up to a major bump
+	MinorThreshold int `toml:"minor_threshold,omitempty"`
+}
+
+// ReleasePRConfig controls "commet release-pr", which keeps a pull request
+// with the pending version bump and changelog up to date; merging it releases.
+type ReleasePRConfig struct {
+	// Branch receives the release commit and is force-pushed on every update (default "commet/release")
+	Branch string `toml:"branch,omitempty"`
+	// Base is the branch the pull request targets (default: the current branch)
+	Base string `toml:"base,omitempty"`
+	// Title is the pull request title, with {version} (default "Release {version}")
+	Title string `toml:"title,omitempty"`
+	// Forge is "github" or "gitlab"; detected from the origin URL when empty
+	Forge string `toml:"forge,omitempty"`
+}
+
+// validate fills in the default steps, drops the disabled ones and checks
+// the step names and hooks.
+func (p *PipelineConfig) validate() error {
+	known := make(map[string]bool, len(PipelineSteps))
+	for _, step := range PipelineSteps {
+		known[step] = true
+	}
+
+	if len(p.Steps) == 0 {
+		p.Steps = append([]string(nil), PipelineSteps...)
+	}
+
+	disabled := make(map[string]bool, len(p.Disable))
+	for _, step := range p.Disable {
+		if !known[step] {
+			return fmt.Errorf("pipeline.disable: unknown step %q", step)
+		}
+		disabled[step] = true
+	}
+
+	seen := make(map[string]bool, len(p.Steps))
+	var steps []string
+	for _, step := range p.Steps {
+		if !known[step] {
+			return fmt.Errorf("pipeline.steps: unknown step %q, expected one of %s", step, strings.Join(PipelineSteps, ", "))
+		}
+		if seen[step] {
+			return fmt.Errorf("pipeline.steps: %q is listed twice", step)
+		}
+		seen[step] = true
+		if !disabled[step] {
+			steps = append(steps, step)
+		}
+	}
+
+	for i, step := range requiredSteps {
+		if i >= len(steps) || steps[i] != step {
+			return fmt.Errorf("pipeline.steps must start with %s and cannot disable them", strings.Join(requiredSteps, ", "))
+		}
+	}
+	p.Steps = steps
+
+	for hook := range p.Hooks {
+		when, step, _ := strings.Cut(hook, "_")
+		if (when != "before" && when != "after") || !known[step] {
+			return fmt.Errorf("pipeline.hooks: %q must be before_<step> or after_<step>", hook)
+		}
+	}
+
+	return nil
+}
+
+// ParserConfig adds commit subject patterns for conventions the built-in
+// patterns do not cover, e.g. '^\[(?P<scope>[^\]]+)\] (?P<type>\w+): (?P<desc>.+)$'.
+type ParserConfig struct {
+	// Patterns are regular expressions with the named groups type, scope,
+	// board, desc and force, tried in order before the built-in patterns
+	Patterns []string `toml:"patterns,omitempty"`
+	// Replace drops the built-in patterns, so only Patterns are tried
+	Replace bool `toml:"replace,omitempty"`
+	// Gitmoji reads subjects like "✨ add search" or ":bug: fix crash" as
+	// the type of their emoji, e.g. Feature and Fix
+	Gitmoji bool `toml:"gitmoji,omitempty"`
+	// GitmojiTypes adds to or overrides the emoji types, e.g. "🚀" = "Feature"
+	GitmojiTypes map[string]string `toml:"gitmoji_types,omitempty"`
+	// BoardPattern matches board IDs, e.g. '[a-z]+_\d+' for "proj_1234"
+	// (default '[A-Z]+-\d+'); it must not be anchored
+	BoardPattern string `toml:"board_pattern,omitempty"`
+	// DisableBoards reads no board IDs from subjects at all
+	DisableBoards bool `toml:"disable_boards,omitempty"`
+	// SkipMarkers leave commits whose subject contains one out of releases
+	// and changelogs (default "[skip version]" and "[no bump]"); [] disables
+	SkipMarkers []string `toml:"skip_markers"`
+}
+
+// PipelineSteps are the steps of a release in their default order.
+var PipelineSteps = []string{"detect", "collect", "parse", "calculate", "check", "update", "changelog", "commit", "tag", "develop", "publish", "notify"}
+
+// requiredSteps open every release pipeline, in this order.
+var requiredSteps = []string{"detect", "collect", "parse", "calculate"}
+
+// PipelineConfig reorders, disables and hooks into the steps of a release.
+type PipelineConfig struct {
+	// Steps run in this order (default PipelineSteps); steps left out are
+	// disabled. detect, collect, parse and calculate always come first
+	Steps []string `toml:"steps,omitempty"`
+	// Disable leaves steps out of the default order, e.g. ["notify"]
+	Disable []string `toml:"disable,omitempty"`
+	// Hooks maps before_<step> and after_<step> to shell commands, e.g.
+	// before_tag = "make dist"; a failing hook stops the release
+	Hooks map[string]string `toml:"hooks,omitempty"`
+}
+
+// LintConfig sets the rules "commet lint" checks commit messages against,
+// besides the commit patterns.
+type LintConfig struct {
+	// Types are the allowed commit types (default: the types of bump_rules)
+	Types []string `toml:"types,omitempty"`
+	// Scopes are the allowed scopes; any scope is allowed when empty
+	Scopes []string `toml:"scopes,omitempty"`
+	// MaxSubjectLength caps the length of the subject line (default 72)
+	MaxSubjectLength int `toml:"max_subject_length,omitempty"`
+}
+
+// PackageConfig controls the release archives built by "commet package".
+// Templates may use {version}, {os}, {arch} and {binary}.
+type PackageConfig struct {
+	// Targets are GOOS/GOARCH pairs, e.g. ["linux/amd64", "darwin/arm64"]
+	Targets []string `toml:"targets,omitempty"`
+	// Main is the package passed to go build (default ".")
+	Main string `toml:"main,omitempty"`
+	// Binary is the executable name inside the archives (default: the directory name)
+	Binary string `toml:"binary,omitempty"`
+	// Ldflags are passed to go build, e.g. "-s -w -X main.version={version}"
+	Ldflags string `toml:"ldflags,omitempty"`
+	// BuildCommand replaces go build; it must write the binary to {output}.
+	// GOOS and GOARCH are set in its environment
+	BuildCommand string `toml:"build_command,omitempty"`
+	// Name is the archive name without extension (default "{binary}_{version}_{os}_{arch}");
+	// windows targets get .zip, others .tar.gz
+	Name string `toml:"name,omitempty"`
+	// Files are added to every archive next to the binary, e.g. README.md and LICENSE
+	Files []string `toml:"files,omitempty"`
+	// Dist is the output directory (default "dist")
+	Dist string `toml:"dist,omitempty"`
+	// Checksum is the SHA-256 checksum file written to Dist (default "checksums.txt")
+	Checksum string `toml:"checksum,omitempty"`
+	// Upload attaches the archives and checksums to the GitHub release of the tag
+	Upload bool `toml:"upload,omitempty"`
+}
+
+// PolicyConfig holds release policies enforced before any file is changed.
+type PolicyConfig struct {
+	// RequireBoardBranches lists branches (glob patterns, e.g. "release/*") where
+	// every releasable commit must reference a board ID
+	RequireBoardBranches []string `toml:"require_board_branches,omitempty"`
+	// BoardCheckURL is fetched for each board ID ({board} placeholder); a non-2xx response fails the release
+	BoardCheckURL string `toml:"board_check_url,omitempty"`
+	// BoardCheckTokenEnv names the environment variable holding a bearer token for BoardCheckURL
+	BoardCheckTokenEnv string `toml:"board_check_token_env,omitempty"`
+	// RequireConfirmationForMajor stops major releases unless confirmed at a
+	// prompt or with --allow-major, so a stray "!" cannot publish a new major
+	RequireConfirmationForMajor bool `toml:"require_confirmation_for_major,omitempty"`
+}
+
+// Notification channels.
+const (
+	ChannelSlack   = "slack"
+	ChannelWebhook = "webhook"
+	ChannelEmail   = "email"
+)
+
+// NotificationConfig is a channel told about each release. Values of url,
+// smtp_user and smtp_password may reference environment variables as ${NAME}.
+type NotificationConfig struct {
+	Channel string `toml:"channel"` // "slack", "webhook" or "email"
+
+	// Template is a text/template over .Project, .Version, .Tag, .Notes,
+	// .Groups and .Commits; each channel has a default
+	Template string `toml:"template,omitempty"`
+	// IncludeTypes limits .Notes, .Groups and .Commits to these commit types
+	IncludeTypes []string `toml:"include_types,omitempty"`
+
+	// Slack and webhook
+	URL         string `toml:"url,omitempty"`
+	ContentType string `toml:"content_type,omitempty"`
+
+	// Email
+	Subject      string   `toml:"subject,omitempty"`
+	From         string   `toml:"from,omitempty"`
+	To           []string `toml:"to,omitempty"`
+	SMTPHost     string   `toml:"smtp_host,omitempty"` // host:port
+	SMTPUser     string   `toml:"smtp_user,omitempty"`
+	SMTPPassword string   `toml:"smtp_password,omitempty"`
+}
+
+type ChangelogConfig struct {
+	Enabled bool                           `toml:"enabled"`
+	File    string                         `toml:"file"`
+	Types   map[string]ChangelogTypeConfig `toml:"types,omitempty"`
+
+	// ReleaseNotesFile holds hand-written notes that replace the generated entry
+	// and the release pull request body when present (default
+	// "RELEASE_NOTES.md"); empty disables them
+	ReleaseNotesFile string `toml:"release_notes_file"`
+	// ReleaseNotesArchive is a directory where used notes are kept as <version>.md; empty just clears the file
+	ReleaseNotesArchive string `toml:"release_notes_archive,omitempty"`
+
+	// IncludeTypes limits the entry to these commit types; empty includes everything
+	IncludeTypes []string `toml:"include_types,omitempty"`
+
+	// StripEmoji drops leading emoji and :gitmoji: codes from descriptions
+	StripEmoji bool `toml:"strip_emoji,omitempty"`
+	// StripBoards drops board IDs left at the start of descriptions, e.g. "Fix: [B-12] typo"
+	StripBoards bool `toml:"strip_boards,omitempty"`
+	// Normalize capitalizes descriptions and drops trailing periods and ticket references
+	Normalize bool `toml:"normalize,omitempty"`
+
+	// SummaryCommand receives the grouped commits as JSON on stdin; its output
+	// becomes a "Highlights" section at the top of the entry
+	SummaryCommand string `toml:"summary_command,omitempty"`
+
+	// BoardURL links board IDs in entries, e.g. "https://jira.example.com/browse/{board}"
+	BoardURL string `toml:"board_url,omitempty"`
+
+	// PRURL links the pull request numbers of squash merges, e.g.
+	// "https://github.com/{owner}/{repo}/pull/{pr}"
+	PRURL string `toml:"pr_url,omitempty"`
+
+	// IssueURL links issue references like "Closes #12" in commit bodies, e.g.
+	// "https://github.com/{owner}/{repo}/issues/{issue}"; other IDs use BoardURL
+	IssueURL string `toml:"issue_url,omitempty"`
+
+	// BodyBullets lists the bullet points of commit bodies as nested items
+	// below their commit (default true)
+	BodyBullets bool `toml:"body_bullets"`
+
+	// Contributors adds a section listing the commit authors and their
+	// Co-authored-by co-authors
+	Contributors bool `toml:"contributors,omitempty"`
+
+	// FooterTemplate is a text/template rendered below each entry with
+	// .Version, .Groups and .Commits; .Trailers "Reviewed-by" lists the
+	// distinct values of a git trailer across the entry's commits
+	FooterTemplate string `toml:"footer_template,omitempty"`
+
+	// HashLength is how many characters of commit hashes are shown (default 7,
+	// 40 for full SHAs)
+	HashLength int `toml:"hash_length,omitempty"`
+
+	// Date is "now" (default) to date entries with the system clock, or
+	// "commit" to use the tag or commit being released, so skewed CI clocks
+	// do not matter and regenerated entries keep their date
+	Date string `toml:"date,omitempty"`
+
+	// Order is "log" (default), the order git history is walked in, or
+	// "topo" to list every commit before its parents whatever their dates
+	Order string `toml:"order,omitempty"`
+
+	// Outputs are extra changelog files written in the same run, e.g. a public RELEASES.md
+	Outputs []ChangelogOutputConfig `toml:"outputs,omitempty"`
+
+	// Locales are the changelog in other languages, written in the same run,
+	// e.g. CHANGELOG.zh.md next to CHANGELOG.md
+	Locales []ChangelogLocaleConfig `toml:"locales,omitempty"`
+
+	// Locale, Titles and TranslateCommand are filled in per locale by Targets
+	Locale           string            `toml:"-"`
+	Titles           map[string]string `toml:"-"`
+	TranslateCommand string            `toml:"-"`
+
+	// ReleaseDate dates entries instead of the clock when set, with date = "commit"
+	ReleaseDate time.Time `toml:"-"`
+
+	// Summary is the output of SummaryCommand once Summarized, filled in by
+	// changelog.Summarize so every target, locale and notification of a
+	// release shows it without running the command again
+	Summary    string `toml:"-"`
+	Summarized bool   `toml:"-"`
+
+	// BoardPattern is the board pattern of the commit parser for strip_boards
+	// and normalize, parser.DefaultBoardPattern when empty, and DisableBoards
+	// is set when the parser reads no boards; Load fills both in
+	BoardPattern  string `toml:"-"`
+	DisableBoards bool   `toml:"-"`
+}
+
+type ChangelogOutputConfig struct {
+	File         string                         `toml:"file"`
+	IncludeTypes []string                       `toml:"include_types,omitempty"`
+	Types        map[string]ChangelogTypeConfig `toml:"types,omitempty"`
+}
+
+// ChangelogLocaleConfig is the changelog in another language, e.g. locale = "zh"
+// with translated section titles, per-type templates and a translation hook.
+type ChangelogLocaleConfig struct {
+	Locale string `toml:"locale"`
+	// File defaults to the changelog file with the locale before the extension, e.g. CHANGELOG.zh.md
+	File string `toml:"file,omitempty"`
+	// Titles rename the type sections, e.g. Feature = "新功能"; "Other" names untyped commits
+	Titles map[string]string              `toml:"titles,omitempty"`
+	Types  map[string]ChangelogTypeConfig `toml:"types,omitempty"`
+	// TranslateCommand gets the entry below its header on stdin and prints the
+	// translation; COMMET_VERSION and COMMET_LOCALE are set
+	TranslateCommand string `toml:"translate_command,omitempty"`
+}
+
+// LocaleFile returns the changelog file of locale next to file, e.g.
+// CHANGELOG.zh.md for CHANGELOG.md.
+func LocaleFile(file, locale string) string {
+	ext := filepath.Ext(file)
+	return strings.TrimSuffix(file, ext) + "." + locale + ext
+}
+
+// Targets returns the primary changelog followed by every extra output and
+// locale, each resolved to a full ChangelogConfig.
+func (c ChangelogConfig) Targets() []ChangelogConfig {
+	primary := c
+	primary.Outputs = nil
+	if primary.File == "" {
+		primary.File = "CHANGELOG.md"
+	}
+
+	targets := []ChangelogConfig{primary}
+	for _, output := range c.Outputs {
+		target := primary
+		target.File = output.File
+		target.IncludeTypes = output.IncludeTypes
+		target.Types = output.Types
+		targets = append(targets, target)
+	}
+
+	for _, locale := range c.Locales {
+		target := primary
+		target.File = locale.File
+		if target.File == "" {
+			target.File = LocaleFile(primary.File, locale.Locale)
+		}
+		target.Locale = locale.Locale
+		target.Titles = locale.Titles
+		if len(locale.Types) > 0 {
+			target.Types = locale.Types
+		}
+		target.TranslateCommand = locale.TranslateCommand
+		targets = append(targets, target)
+	}
+
+	return targets
+}
+
+type ChangelogTypeConfig struct {
+	// Template is a text/template rendered with the parsed commit, e.g.
+	// "- {{.Scope}}: {{.Description}} (thanks {{.Author}})"
+	Template string `toml:"template,omitempty"`
+}
+
+// presets are the bump rules of well-known commit conventions.
+var presets = map[string]map[string]BumpType{
+	// Conventional Commits 1.0 with the types of @commitlint/config-conventional;
+	// "feat!:" and "feat(api)!:" are major through the "!" marker
+	"conventional": {
+		"feat":     BumpMinor,
+		"fix":      BumpPatch,
+		"perf":     BumpPatch,
+		"revert":   BumpPatch,
+		"refactor": BumpNone,
+		"docs":     BumpNone,
+		"style":    BumpNone,
+		"test":     BumpNone,
+		"build":    BumpNone,
+		"ci":       BumpNone,
+		"chore":    BumpNone,
+	},
+}
+
+// ApplyPreset adds the bump rules of the configured preset that bump_rules
+// does not set itself.
+func (c *Config) ApplyPreset() error {
+	if c.Preset == "" {
+		return nil
+	}
+
+	rules, ok := presets[c.Preset]
+	if !ok {
+		return fmt.Errorf("unknown preset %q, expected \"conventional\"", c.Preset)
+	}
+
+	if c.BumpRules == nil {
+		c.BumpRules = make(map[string]BumpType, len(rules))
+	}
+	for commitType, bump := range rules {
+		if _, ok := c.BumpRules[commitType]; !ok {
+			c.BumpRules[commitType] = bump
+		}
+	}
+
+	return nil
+}
+
 func DefaultConfig() *Config {
 	return &Config{
 		Version: VersionConfig{
@@ -69,11 +781,13 @@ func DefaultConfig() *Config {
 			"Build":    BumpPatch,
 			"Tests":    BumpNone,
 			"Breaking": BumpMajor,
+			"Revert":   BumpPatch,
+			"Security": BumpPatch,
 			"!":        BumpMajor,
 		},
 		Detection: DetectionConfig{
 			Strategies:    []string{"git-tags", "version-file"},
-			TagPattern:    `^v?([0-9]+\.[0-9]+\.[0-9]+)$`,
+			TagPattern:    `^v?([0-9]+\.[0-9]+\.[0-9]+(?:-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?)$`,
 			ExcludeMerges: true,
 		},
 		Git: GitConfig{
@@ -84,8 +798,33 @@ func DefaultConfig() *Config {
 			TagMessage:    "Release {version}",
 		},
 		Changelog: ChangelogConfig{
+			Enabled:          false,
+			File:             "CHANGELOG.md",
+			ReleaseNotesFile: "RELEASE_NOTES.md",
+			BodyBullets:      true,
+		},
+		Debian: DebianConfig{
+			Enabled:      false,
+			File:         "debian/changelog",
+			Distribution: "unstable",
+			Urgency:      "medium",
+			Revision:     "1",
+		},
+		Stats: StatsConfig{
 			Enabled: false,
-			File:    "CHANGELOG.md",
+			File:    ".commet-stats.jsonl",
+		},
+		Parser: ParserConfig{
+			SkipMarkers: append([]string(nil), parser.DefaultSkipMarkers...),
+		},
+		Lint: LintConfig{
+			MaxSubjectLength: 72,
+		},
+		Snapshot: SnapshotConfig{
+			Enabled:       false,
+			Suffix:        "-SNAPSHOT",
+			Bump:          BumpMinor,
+			CommitMessage: "Conf: prepare next development version {version}",
 		},
 	}
 }
@@ -95,11 +834,24 @@ func Load(configPath string) (*Config, error) {
 		if _, err := os.Stat(".commet.toml"); err == nil {
 			configPath = ".commet.toml"
 		} else {
-			return DefaultConfig(), nil
+			// Without a config file both the built-in types and Conventional
+			// Commits work, so standard repositories need no setup
+			cfg := DefaultConfig()
+			cfg.Preset = "conventional"
+			if err := cfg.ApplyPreset(); err != nil {
+				return nil, err
+			}
+			if err := cfg.buildParser(); err != nil {
+				return nil, err
+			}
+			return cfg, nil
 		}
 	}
 
 	cfg := DefaultConfig()
+	if err := loadExtends(cfg, configPath); err != nil {
+		return nil, err
+	}
 	if _, err := toml.DecodeFile(configPath, cfg); err != nil {
 		return nil, fmt.Errorf("failed to parse config file %s: %w", configPath, err)
 	}
@@ -108,15 +860,58 @@ func Load(configPath string) (*Config, error) {
 		return nil, err
 	}
 
+	if err := cfg.buildParser(); err != nil {
+		return nil, err
+	}
+
 	return cfg, nil
 }
 
+// buildParser compiles the patterns, board pattern, gitmojis, type aliases
+// and skip markers into the commit parser and hands its board pattern to the
+// changelog.
+func (c *Config) buildParser() error {
+	p, err := parser.New(parser.Options{
+		Patterns:        c.Parser.Patterns,
+		Replace:         c.Parser.Replace,
+		BoardPattern:    c.Parser.BoardPattern,
+		DisableBoards:   c.Parser.DisableBoards,
+		Gitmoji:         c.Parser.Gitmoji,
+		GitmojiTypes:    c.Parser.GitmojiTypes,
+		TypeAliases:     c.TypeAliases,
+		FoldTypeAliases: c.CaseInsensitiveTypes,
+		SkipMarkers:     c.Parser.SkipMarkers,
+	})
+	if err != nil {
+		return fmt.Errorf("parser: %w", err)
+	}
+
+	c.commitParser = p
+	c.Changelog.BoardPattern, c.Changelog.DisableBoards = c.Parser.BoardPattern, c.Parser.DisableBoards
+	return nil
+}
+
+// CommitParser returns the parser of the commit messages of this
+// configuration. Configurations not read by Load parse as parser.Default.
+func (c *Config) CommitParser() *parser.Parser {
+	if c.commitParser == nil {
+		return parser.Default()
+	}
+	return c.commitParser
+}
+
 func (c *Config) Validate() error {
+	if c.RequiredVersion != "" {
+		if _, err := semver.NewConstraint(c.RequiredVersion); err != nil {
+			return fmt.Errorf("required_version %q is not a valid constraint: %w", c.RequiredVersion, err)
+		}
+	}
+
 	if c.Version.File == "" {
 		return fmt.Errorf("version.file is required")
 	}
 
-	if c.Version.Key == "" {
+	if c.Version.Key == "" && c.Version.Marker == "" {
 		return fmt.Errorf("version.key is required")
 	}
 
@@ -127,32 +922,521 @@ func (c *Config) Validate() error {
 	if c.Version.Format == "" {
 		c.Version.Format = "semver"
 	}
-	if c.Version.Format != "semver" && c.Version.Format != "v-prefix" {
-		return fmt.Errorf("version.format must be 'semver' or 'v-prefix'")
+	switch c.Version.Format {
+	case "semver", "v-prefix":
+	case "calver":
+		if c.Version.CalVerPattern == "" {
+			c.Version.CalVerPattern = "YYYY.MM.MICRO"
+		}
+		if c.Version.Prerelease != "" || c.Snapshot.Enabled {
+			return fmt.Errorf("version.format 'calver' cannot be combined with pre-releases or snapshots")
+		}
+	case "four-part":
+		if c.Version.Prerelease != "" || c.Snapshot.Enabled || c.Version.BuildMetadata != "" {
+			return fmt.Errorf("version.format 'four-part' cannot be combined with pre-releases, snapshots or build metadata")
+		}
+		switch c.Version.BuildSegment {
+		case "":
+			c.Version.BuildSegment = "reset"
+		case "reset", "increment":
+		default:
+			return fmt.Errorf("version.build_segment must be 'reset' or 'increment'")
+		}
+	case "pep440":
+		if c.Snapshot.Enabled || c.Version.BuildMetadata != "" {
+			return fmt.Errorf("version.format 'pep440' cannot be combined with snapshots or build metadata")
+		}
+		if c.Version.Prerelease != "" && !pep440Phases[c.Version.Prerelease] {
+			return fmt.Errorf("version.prerelease must be 'a', 'b' or 'rc' with version.format 'pep440'")
+		}
+	default:
+		if !formats[c.Version.Format] {
+			return fmt.Errorf("version.format must be 'semver', 'v-prefix', 'calver', 'four-part', 'pep440' or a registered scheme")
+		}
+	}
+
+	if c.Version.Max != "" && !versionLine.MatchString(c.Version.Max) {
+		return fmt.Errorf("version.max must be a version line such as \"1.x\" or \"1.4.x\", got %q", c.Version.Max)
+	}
+
+	for level, bump := range c.BumpLevels {
+		if _, ok := bumpRank[BumpType(level)]; ok {
+			return fmt.Errorf("bump_levels.%s: cannot redefine a built-in bump level", level)
+		}
+		if _, ok := bumpRank[bump]; !ok {
+			return fmt.Errorf("bump_levels.%s must be 'none', 'build', 'patch', 'minor' or 'major'", level)
+		}
+	}
+
+	if c.Version.MinBump != "" {
+		if !c.ValidBump(c.Version.MinBump) {
+			return fmt.Errorf("version.min_bump must be 'none', 'build', 'patch', 'minor', 'major' or a level from bump_levels")
+		}
+		c.Version.MinBump = c.ResolveBump(c.Version.MinBump)
+	}
+
+	if c.Version.Format != "four-part" {
+		if c.Version.MinBump == BumpBuild {
+			return fmt.Errorf("version.min_bump: 'build' bumps need version.format 'four-part'")
+		}
+		for rule, bump := range c.BumpRules {
+			if c.ResolveBump(bump) == BumpBuild {
+				return fmt.Errorf("bump_rules.%s: 'build' bumps need version.format 'four-part'", rule)
+			}
+		}
+		for prefix, bump := range c.BoardRules {
+			if c.ResolveBump(bump) == BumpBuild {
+				return fmt.Errorf("board_rules.%s: 'build' bumps need version.format 'four-part'", prefix)
+			}
+		}
+		for i, rule := range c.PathRules {
+			if c.ResolveBump(rule.Bump) == BumpBuild {
+				return fmt.Errorf("path_rules[%d]: 'build' bumps need version.format 'four-part'", i)
+			}
+		}
+	}
+
+	for _, versionFile := range c.GetVersionFiles() {
+		if versionFile.Template != "" && !strings.Contains(versionFile.Template, "{version}") {
+			return fmt.Errorf("template of %s must contain {version}", versionFile.File)
+		}
+		if versionFile.SyncLockfile && filepath.Base(versionFile.File) != "Cargo.toml" {
+			return fmt.Errorf("sync_lockfile is only supported for Cargo.toml, not %s", versionFile.File)
+		}
+	}
+
+	if c.Version.Prerelease != "" && !prereleaseIdentifier.MatchString(c.Version.Prerelease) {
+		return fmt.Errorf("version.prerelease must be a single identifier such as 'alpha', 'beta' or 'rc'")
+	}
+
+	for i, channel := range c.Branches {
+		if channel.Branch == "" {
+			return fmt.Errorf("branches[%d]: branch is required", i)
+		}
+		if _, err := path.Match(channel.Branch, ""); err != nil {
+			return fmt.Errorf("branches[%d]: invalid branch pattern %q: %w", i, channel.Branch, err)
+		}
+		if channel.Prerelease == "" {
+			continue
+		}
+		if !prereleaseIdentifier.MatchString(channel.Prerelease) {
+			return fmt.Errorf("branches[%d]: prerelease must be a single identifier such as 'alpha', 'beta' or 'rc'", i)
+		}
+		if c.Version.Format == "calver" || c.Version.Format == "four-part" {
+			return fmt.Errorf("branches[%d]: version.format '%s' cannot be combined with pre-releases", i, c.Version.Format)
+		}
+		if c.Version.Format == "pep440" && !pep440Phases[channel.Prerelease] {
+			return fmt.Errorf("branches[%d]: prerelease must be 'a', 'b' or 'rc' with version.format 'pep440'", i)
+		}
+	}
+
+	for i, notification := range c.Notifications {
+		switch notification.Channel {
+		case ChannelSlack, ChannelWebhook:
+			if notification.URL == "" {
+				return fmt.Errorf("notifications[%d]: url is required for %s", i, notification.Channel)
+			}
+		case ChannelEmail:
+			if notification.SMTPHost == "" || notification.From == "" || len(notification.To) == 0 {
+				return fmt.Errorf("notifications[%d]: smtp_host, from and to are required for email", i)
+			}
+		default:
+			return fmt.Errorf("notifications[%d]: channel must be 'slack', 'webhook' or 'email'", i)
+		}
+
+		if notification.Template != "" {
+			if _, err := template.New("notification").Funcs(template.FuncMap{"json": func(interface{}) string { return "" }}).Parse(notification.Template); err != nil {
+				return fmt.Errorf("notifications[%d]: invalid template: %w", i, err)
+			}
+		}
+	}
+
+	for i, rule := range c.PathRules {
+		if len(rule.Paths) == 0 {
+			return fmt.Errorf("path_rules[%d]: paths cannot be empty", i)
+		}
+		if !c.ValidBump(rule.Bump) {
+			return fmt.Errorf("path_rules[%d]: bump must be 'none', 'build', 'patch', 'minor', 'major' or a level from bump_levels", i)
+		}
+	}
+
+	if err := c.ApplyPreset(); err != nil {
+		return err
 	}
 
 	if len(c.BumpRules) == 0 {
 		return fmt.Errorf("bump_rules cannot be empty")
 	}
 
+	for rule, bump := range c.BumpRules {
+		if !c.ValidBump(bump) {
+			return fmt.Errorf("bump_rules.%s: bump must be 'none', 'build', 'patch', 'minor', 'major' or a level from bump_levels", rule)
+		}
+	}
+
+	for prefix, bump := range c.BoardRules {
+		if !c.ValidBump(bump) {
+			return fmt.Errorf("board_rules.%s: bump must be 'none', 'build', 'patch', 'minor', 'major' or a level from bump_levels", prefix)
+		}
+	}
+
 	if len(c.Detection.Strategies) == 0 {
 		c.Detection.Strategies = []string{"git-tags", "version-file"}
 	}
 
 	if c.Detection.TagPattern == "" {
-		c.Detection.TagPattern = `^v?([0-9]+\.[0-9]+\.[0-9]+)$`
+		c.Detection.TagPattern = `^v?([0-9]+\.[0-9]+\.[0-9]+(?:-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?)$`
+	}
+	if c.Version.Format == "four-part" && c.Detection.TagPattern == DefaultConfig().Detection.TagPattern {
+		c.Detection.TagPattern = `^v?([0-9]+\.[0-9]+\.[0-9]+(?:\.[0-9]+)?)$`
+	}
+	if c.Version.Format == "pep440" && c.Detection.TagPattern == DefaultConfig().Detection.TagPattern {
+		c.Detection.TagPattern = `^v?((?:[0-9]+!)?[0-9]+(?:\.[0-9]+)*(?:(?:a|b|rc)[0-9]+)?(?:\.post[0-9]+)?(?:\.dev[0-9]+)?)$`
+	}
+
+	if c.Detection.IgnoreFile == "" {
+		c.Detection.IgnoreFile = ".commetignore"
+	}
+
+	if c.Stats.File == "" {
+		c.Stats.File = ".commet-stats.jsonl"
+	}
+
+	if c.Debian.File == "" {
+		c.Debian.File = "debian/changelog"
+	}
+	if c.Debian.Distribution == "" {
+		c.Debian.Distribution = "unstable"
+	}
+	if c.Debian.Urgency == "" {
+		c.Debian.Urgency = "medium"
+	}
+
+	if c.Snapshot.Enabled && c.Git.PostReleaseVersion != "" {
+		return fmt.Errorf("snapshot.enabled and git.post_release_version cannot be used together")
+	}
+	// Without the release commit the development version would replace the
+	// released one in the version files
+	if c.Snapshot.Enabled && !c.Git.AutoCommit {
+		return fmt.Errorf("snapshot.enabled needs git.auto_commit: the release must be committed before the development version is written")
+	}
+	if c.Git.PostReleaseVersion != "" && !c.Git.AutoCommit {
+		return fmt.Errorf("git.post_release_version needs git.auto_commit: the release must be committed before the development version is written")
+	}
+
+	if c.Git.PostReleaseCommitMessage == "" {
+		c.Git.PostReleaseCommitMessage = "Conf: prepare next development version {version}"
+	}
+
+	switch c.Git.TagType {
+	case "":
+		c.Git.TagType = "annotated"
+	case "annotated", "lightweight":
+	default:
+		return fmt.Errorf("git.tag_type must be 'annotated' or 'lightweight'")
+	}
+	if c.Git.TagType == "lightweight" && c.Git.Sign {
+		return fmt.Errorf("git.sign needs git.tag_type 'annotated': lightweight tags cannot be signed")
+	}
+
+	if c.Git.AutoPush && !c.Git.AutoCommit && !c.Git.AutoTag {
+		return fmt.Errorf("git.auto_push needs git.auto_commit or git.auto_tag")
+	}
+	if c.Git.PushRemote == "" {
+		c.Git.PushRemote = "origin"
+	}
+
+	if c.Snapshot.Suffix == "" {
+		c.Snapshot.Suffix = "-SNAPSHOT"
+	}
+	switch c.Snapshot.Bump {
+	case "":
+		c.Snapshot.Bump = BumpMinor
+	case BumpPatch, BumpMinor, BumpMajor:
+	default:
+		return fmt.Errorf("snapshot.bump must be 'patch', 'minor' or 'major'")
+	}
+
+	if c.Rollup.PatchThreshold < 0 || c.Rollup.MinorThreshold < 0 {
+		return fmt.Errorf("rollup thresholds cannot be negative")
+	}
+
+	if c.ReleasePR.Branch == "" {
+		c.ReleasePR.Branch = "commet/release"
+	}
+	if c.ReleasePR.Title == "" {
+		c.ReleasePR.Title = "Release {version}"
+	}
+	switch c.ReleasePR.Forge {
+	case "", "github", "gitlab":
+	default:
+		return fmt.Errorf("release_pr.forge must be 'github' or 'gitlab'")
+	}
+
+	if c.Parser.Replace && len(c.Parser.Patterns) == 0 {
+		return fmt.Errorf("parser.replace requires parser.patterns")
+	}
+	if c.Parser.DisableBoards {
+		if len(c.Policy.RequireBoardBranches) > 0 {
+			return fmt.Errorf("policy.require_board_branches needs board IDs, but parser.disable_boards is set")
+		}
+		if len(c.BoardRules) > 0 {
+			return fmt.Errorf("board_rules need board IDs, but parser.disable_boards is set")
+		}
+	}
+
+	if err := c.Pipeline.validate(); err != nil {
+		return err
+	}
+
+	if c.Lint.MaxSubjectLength < 0 {
+		return fmt.Errorf("lint.max_subject_length must not be negative")
+	}
+	if c.Lint.MaxSubjectLength == 0 {
+		c.Lint.MaxSubjectLength = 72
+	}
+
+	for alias, target := range c.TypeAliases {
+		if strings.TrimSpace(target) == "" {
+			return fmt.Errorf("type_aliases.%s must name a commit type", alias)
+		}
+	}
+
+	if c.Package.Main == "" {
+		c.Package.Main = "."
+	}
+	if c.Package.Name == "" {
+		c.Package.Name = "{binary}_{version}_{os}_{arch}"
+	}
+	if c.Package.Dist == "" {
+		c.Package.Dist = "dist"
+	}
+	if c.Package.Checksum == "" {
+		c.Package.Checksum = "checksums.txt"
+	}
+	for i, target := range c.Package.Targets {
+		if goos, goarch, ok := strings.Cut(target, "/"); !ok || goos == "" || goarch == "" {
+			return fmt.Errorf("package.targets[%d]: %q must be <os>/<arch>, e.g. linux/amd64", i, target)
+		}
+	}
+
+	for name, command := range c.Checklist {
+		if strings.TrimSpace(command) == "" {
+			return fmt.Errorf("checklist.%s: command cannot be empty", name)
+		}
+	}
+
+	if c.Changelog.HashLength == 0 {
+		c.Changelog.HashLength = 7
+	}
+	if c.Changelog.HashLength < 4 || c.Changelog.HashLength > 40 {
+		return fmt.Errorf("changelog.hash_length must be between 4 and 40")
+	}
+
+	switch c.Changelog.Date {
+	case "":
+		c.Changelog.Date = "now"
+	case "now", "commit":
+	default:
+		return fmt.Errorf("changelog.date must be 'now' or 'commit'")
+	}
+
+	switch c.Changelog.Order {
+	case "":
+		c.Changelog.Order = "log"
+	case "log", "topo":
+	default:
+		return fmt.Errorf("changelog.order must be 'log' or 'topo'")
+	}
+
+	for i, output := range c.Changelog.Outputs {
+		if output.File == "" {
+			return fmt.Errorf("changelog.outputs[%d].file is required", i)
+		}
+	}
+
+	for i, locale := range c.Changelog.Locales {
+		if locale.Locale == "" {
+			return fmt.Errorf("changelog.locales[%d].locale is required", i)
+		}
+	}
+
+	if c.Changelog.FooterTemplate != "" {
+		if _, err := template.New("footer").Parse(c.Changelog.FooterTemplate); err != nil {
+			return fmt.Errorf("changelog.footer_template is invalid: %w", err)
+		}
+	}
+
+	for _, target := range c.Changelog.Targets() {
+		for typeName, typeCfg := range target.Types {
+			if typeCfg.Template == "" {
+				continue
+			}
+			if _, err := template.New(typeName).Parse(typeCfg.Template); err != nil {
+				return fmt.Errorf("changelog template for %s in %s is invalid: %w", typeName, target.File, err)
+			}
+		}
 	}
 
 	return nil
 }
 
 func (c *Config) GetBumpType(commitType string) BumpType {
-	if bump, ok := c.BumpRules[commitType]; ok {
-		return bump
+	if bump, ok := c.bumpRule(commitType); ok {
+		return c.ResolveBump(bump)
 	}
 	return BumpNone
 }
 
+// bumpRule looks key up in bump_rules, ignoring case with
+// case_insensitive_types when there is no exact match.
+func (c *Config) bumpRule(key string) (BumpType, bool) {
+	if bump, ok := c.BumpRules[key]; ok || !c.CaseInsensitiveTypes {
+		return bump, ok
+	}
+
+	keys := make([]string, 0, len(c.BumpRules))
+	for rule := range c.BumpRules {
+		keys = append(keys, rule)
+	}
+	sort.Strings(keys)
+	for _, rule := range keys {
+		if strings.EqualFold(rule, key) {
+			return c.BumpRules[rule], true
+		}
+	}
+	return "", false
+}
+
+// HasBumpRule reports whether commitType has a bump rule, ignoring case with
+// case_insensitive_types.
+func (c *Config) HasBumpRule(commitType string) bool {
+	_, ok := c.bumpRule(commitType)
+	return ok
+}
+
+// GetScopedBumpType is GetBumpType with "Type(scope)" rules taking precedence,
+// e.g. "Fix(deps)" = "none" next to "Fix" = "patch". With a comma-separated
+// scope list each scope gets its own rule and the highest bump wins.
+func (c *Config) GetScopedBumpType(commitType, scope string) BumpType {
+	typeBump := c.GetBumpType(commitType)
+
+	scoped := false
+	bump := BumpNone
+	for _, s := range strings.Split(scope, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+
+		scopeBump, ok := c.bumpRule(commitType + "(" + s + ")")
+		if ok {
+			scopeBump = c.ResolveBump(scopeBump)
+		} else {
+			scopeBump = typeBump
+		}
+		if bumpRank[scopeBump] > bumpRank[bump] {
+			bump = scopeBump
+		}
+		scoped = true
+	}
+
+	if !scoped {
+		return typeBump
+	}
+	return bump
+}
+
+// GetCommitBumpType is GetScopedBumpType with board rules as a fallback: when
+// commitType has no bump rule, the highest board_rules bump of the prefixes
+// of boards applies, so "BUG-123: Crash on start" is a patch with BUG = "patch".
+func (c *Config) GetCommitBumpType(commitType, scope string, boards []string) BumpType {
+	if _, ok := c.bumpRule(commitType); ok || len(c.BoardRules) == 0 {
+		return c.GetScopedBumpType(commitType, scope)
+	}
+
+	bump := BumpNone
+	for _, board := range boards {
+		prefix, _, _ := strings.Cut(board, "-")
+		if boardBump, ok := c.BoardRules[prefix]; ok && bumpRank[c.ResolveBump(boardBump)] > bumpRank[bump] {
+			bump = c.ResolveBump(boardBump)
+		}
+	}
+	return bump
+}
+
+// SecurityType is the commit type of security fixes, matched in any case.
+// Security commits always bump at least a patch.
+const SecurityType = "Security"
+
+// CommitBump is the bump of a commit: GetCommitBumpType capped by the path
+// rules of files, or override when set, as by a Version-Bump trailer or a
+// commet note. Security commits bump at least a patch whatever the rules.
+func (c *Config) CommitBump(commitType, scope string, boards, files []string, override string) BumpType {
+	bump := c.CapByPaths(c.GetCommitBumpType(commitType, scope, boards), files)
+	if override != "" {
+		bump = BumpType(override)
+	}
+	if strings.EqualFold(commitType, SecurityType) && bumpRank[c.ResolveBump(bump)] < bumpRank[BumpPatch] {
+		bump = BumpPatch
+	}
+	return bump
+}
+
+// ResolveBump returns the built-in level behind a custom level from
+// bump_levels, e.g. "build" for hotfix = "build". Built-in levels are
+// returned as is. The version scheme only ever sees built-in levels.
+func (c *Config) ResolveBump(bump BumpType) BumpType {
+	if resolved, ok := c.BumpLevels[string(bump)]; ok {
+		return resolved
+	}
+	return bump
+}
+
+// ValidBump reports whether bump is a built-in level or one from bump_levels.
+func (c *Config) ValidBump(bump BumpType) bool {
+	_, ok := bumpRank[c.ResolveBump(bump)]
+	return ok
+}
+
+// scopedRuleKey matches a quoted "Type(scope)" bump rule key in a config file.
+var scopedRuleKey = regexp.MustCompile(`"(\w+)\(([^)"]+)\)"(\s*=)`)
+
+// RenameRuleScopes rewrites the scopes of "Type(scope)" bump rules in the raw
+// config file data, keeping its comments and layout. It returns the new data
+// and the number of rules changed, and fails when a rename would leave two
+// rules for the same type and scope.
+func RenameRuleScopes(data []byte, mapping map[string]string) ([]byte, int, error) {
+	changed := 0
+	out := scopedRuleKey.ReplaceAllFunc(data, func(key []byte) []byte {
+		parts := scopedRuleKey.FindSubmatch(key)
+		scope := string(parts[2])
+		renamed := parser.RenameScopes(scope, mapping)
+		if renamed == scope {
+			return key
+		}
+		changed++
+		return []byte(fmt.Sprintf("%q%s", string(parts[1])+"("+renamed+")", parts[3]))
+	})
+
+	if changed > 0 {
+		if _, err := toml.Decode(string(out), &Config{}); err != nil {
+			return nil, 0, fmt.Errorf("renaming scopes would break the config: %w", err)
+		}
+	}
+
+	return out, changed, nil
+}
+
+// bumpRank orders bump types from none to major.
+var bumpRank = map[BumpType]int{
+	BumpNone:  0,
+	BumpBuild: 1,
+	BumpPatch: 2,
+	BumpMinor: 3,
+	BumpMajor: 4,
+}
+
 func (c *Config) GetVersionFiles() []VersionConfig {
 	files := []VersionConfig{c.Version}
 	files = append(files, c.AdditionalFiles...)
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateDisableBoards(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(c *Config)
+		err   string
+	}{
+		{"boards disabled", func(c *Config) {}, ""},
+		{"required board branches", func(c *Config) {
+			c.Policy.RequireBoardBranches = []string{"main"}
+		}, "policy.require_board_branches needs board IDs"},
+		{"board rules", func(c *Config) {
+			c.BoardRules = map[string]BumpType{"BUG": BumpPatch}
+		}, "board_rules need board IDs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Parser.DisableBoards = true
+			tt.setup(cfg)
+
+			err := cfg.Validate()
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Validate() = %v, want %q", err, tt.err)
+			}
+		})
+	}
+
+	cfg := DefaultConfig()
+	cfg.Policy.RequireBoardBranches = []string{"main"}
+	cfg.BoardRules = map[string]BumpType{"BUG": BumpPatch}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() with boards enabled = %v, want nil", err)
+	}
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"path"
+	"strings"
+)
+
+// MatchPath reports whether the slash-separated file name matches pattern.
+// Patterns use path.Match syntax per segment, "**" matches any number of
+// directories, and a pattern naming a directory ("docs" or "docs/") matches
+// everything below it.
+func MatchPath(pattern, name string) bool {
+	pattern = strings.TrimPrefix(pattern, "./")
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+
+	if matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/")) {
+		return true
+	}
+
+	// A plain directory pattern covers its contents
+	return !strings.ContainsAny(pattern, "*?[") && strings.HasPrefix(name, pattern+"/")
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			rest := pattern[1:]
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(rest, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
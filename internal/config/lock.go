@@ -0,0 +1,191 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// LockFile pins the remote sources of extends by their SHA-256. It lives
+// next to the config file and is written by "commet lock".
+const LockFile = ".commet.lock"
+
+// Lock is the content of LockFile.
+type Lock struct {
+	Sources []LockedSource `toml:"source"`
+}
+
+// LockedSource pins one remote config source to the content it had when it
+// was locked.
+type LockedSource struct {
+	URL    string `toml:"url"`
+	SHA256 string `toml:"sha256"`
+}
+
+// fetchTimeout bounds the download of a remote config source.
+const fetchTimeout = 30 * time.Second
+
+// LockPath returns the lock file belonging to configPath.
+func LockPath(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), LockFile)
+}
+
+// ReadLock reads the lock file at path. A missing file is an empty lock.
+func ReadLock(path string) (*Lock, error) {
+	lock := &Lock{}
+	if _, err := toml.DecodeFile(path, lock); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return lock, nil
+		}
+		return nil, fmt.Errorf("failed to parse lock file %s: %w", path, err)
+	}
+	return lock, nil
+}
+
+// Save writes the lock to path.
+func (l *Lock) Save(path string) error {
+	var sb strings.Builder
+	sb.WriteString("# Generated by \"commet lock\"; pins the remote sources of extends.\n\n")
+	if err := toml.NewEncoder(&sb).Encode(l); err != nil {
+		return fmt.Errorf("failed to encode lock file: %w", err)
+	}
+
+	if err := os.WriteFile(path, []byte(sb.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write lock file %s: %w", path, err)
+	}
+	return nil
+}
+
+// pinned returns the hash locked for url.
+func (l *Lock) pinned(url string) (string, bool) {
+	for _, source := range l.Sources {
+		if source.URL == url {
+			return source.SHA256, true
+		}
+	}
+	return "", false
+}
+
+// ResolveLock downloads the remote sources extended by the config file at
+// configPath and returns a lock pinning their current content.
+func ResolveLock(configPath string) (*Lock, error) {
+	extends, err := readExtends(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	lock := &Lock{}
+	for _, source := range extends {
+		if !isRemote(source) {
+			continue
+		}
+		content, err := fetchSource(source, filepath.Dir(configPath))
+		if err != nil {
+			return nil, err
+		}
+		lock.Sources = append(lock.Sources, LockedSource{URL: source, SHA256: hashSource(content)})
+	}
+	return lock, nil
+}
+
+// loadExtends decodes the sources extended by the config file at configPath
+// into cfg, in order, so later sources and the config file itself override
+// earlier ones. Remote sources must match the hash pinned in the lock file.
+// The extends of the sources themselves are not followed.
+func loadExtends(cfg *Config, configPath string) error {
+	extends, err := readExtends(configPath)
+	if err != nil || len(extends) == 0 {
+		return err
+	}
+
+	lockPath := LockPath(configPath)
+	lock, err := ReadLock(lockPath)
+	if err != nil {
+		return err
+	}
+
+	for _, source := range extends {
+		content, err := fetchSource(source, filepath.Dir(configPath))
+		if err != nil {
+			return err
+		}
+
+		if isRemote(source) {
+			locked, ok := lock.pinned(source)
+			if !ok {
+				return fmt.Errorf("%s is not pinned in %s, run \"commet lock\"", source, lockPath)
+			}
+			if sum := hashSource(content); sum != locked {
+				return fmt.Errorf("%s changed since it was locked (sha256 %s, locked %s), review it and run \"commet lock\"", source, sum, locked)
+			}
+		}
+
+		if _, err := toml.Decode(string(content), cfg); err != nil {
+			return fmt.Errorf("failed to parse config source %s: %w", source, err)
+		}
+	}
+
+	return nil
+}
+
+// readExtends returns the extends list of the config file at configPath.
+func readExtends(configPath string) ([]string, error) {
+	var file struct {
+		Extends []string `toml:"extends"`
+	}
+	if _, err := toml.DecodeFile(configPath, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", configPath, err)
+	}
+	return file.Extends, nil
+}
+
+// isRemote reports whether an extends source is downloaded rather than read
+// from the repository.
+func isRemote(source string) bool {
+	return strings.HasPrefix(source, "https://") || strings.HasPrefix(source, "http://")
+}
+
+// fetchSource reads an extends source: remote ones over HTTP, local ones
+// relative to dir, the directory of the config file.
+func fetchSource(source, dir string) ([]byte, error) {
+	if !isRemote(source) {
+		if !filepath.IsAbs(source) {
+			source = filepath.Join(dir, source)
+		}
+		content, err := os.ReadFile(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config source %s: %w", source, err)
+		}
+		return content, nil
+	}
+
+	client := &http.Client{Timeout: fetchTimeout}
+	resp, err := client.Get(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch config source %s: %w", source, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch config source %s: %s", source, resp.Status)
+	}
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch config source %s: %w", source, err)
+	}
+	return content, nil
+}
+
+func hashSource(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,178 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+const contextLines = 3
+
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+type op struct {
+	kind opKind
+	line string
+}
+
+// Unified returns a unified diff between before and after, labelled with
+// oldName and newName. It returns an empty string when the inputs are equal.
+func Unified(oldName, newName, before, after string) string {
+	if before == after {
+		return ""
+	}
+
+	a := splitLines(before)
+	b := splitLines(after)
+	ops := compute(a, b)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("--- %s\n", oldName))
+	sb.WriteString(fmt.Sprintf("+++ %s\n", newName))
+
+	for _, h := range hunks(ops) {
+		sb.WriteString(h)
+	}
+
+	return sb.String()
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// compute builds an edit script from the longest common subsequence of a and b.
+func compute(a, b []string) []op {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var ops []op
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, op{opEqual, a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, op{opDelete, a[i]})
+			i++
+		default:
+			ops = append(ops, op{opInsert, b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, op{opDelete, a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, op{opInsert, b[j]})
+	}
+
+	return ops
+}
+
+func hunks(ops []op) []string {
+	var result []string
+
+	// Line numbers (1-based) in the old and new file for each op
+	oldLine := make([]int, len(ops))
+	newLine := make([]int, len(ops))
+	o, n := 1, 1
+	for i, e := range ops {
+		oldLine[i], newLine[i] = o, n
+		if e.kind != opInsert {
+			o++
+		}
+		if e.kind != opDelete {
+			n++
+		}
+	}
+
+	i := 0
+	for i < len(ops) {
+		if ops[i].kind == opEqual {
+			i++
+			continue
+		}
+
+		start := max(i-contextLines, 0)
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != opEqual {
+				end++
+				continue
+			}
+			// Stop once we see more equal lines than two contexts can cover
+			run := end
+			for run < len(ops) && ops[run].kind == opEqual {
+				run++
+			}
+			if run == len(ops) || run-end > 2*contextLines {
+				end = min(end+contextLines, len(ops))
+				break
+			}
+			end = run
+		}
+
+		var oldCount, newCount int
+		var body strings.Builder
+		for _, e := range ops[start:end] {
+			prefix := " "
+			switch e.kind {
+			case opDelete:
+				prefix = "-"
+				oldCount++
+			case opInsert:
+				prefix = "+"
+				newCount++
+			default:
+				oldCount++
+				newCount++
+			}
+			body.WriteString(prefix + e.line)
+			if !strings.HasSuffix(e.line, "\n") {
+				body.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+
+		oldStart, newStart := oldLine[start], newLine[start]
+		if oldCount == 0 {
+			oldStart--
+		}
+		if newCount == 0 {
+			newStart--
+		}
+
+		result = append(result, fmt.Sprintf("@@ -%d,%d +%d,%d @@\n%s", oldStart, oldCount, newStart, newCount, body.String()))
+		i = end
+	}
+
+	return result
+}
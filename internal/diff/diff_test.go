@@ -0,0 +1,128 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name, before, after, want string
+	}{
+		{
+			name:   "equal",
+			before: "a\nb\n",
+			after:  "a\nb\n",
+			want:   "",
+		},
+		{
+			name:   "change",
+			before: "{\n  \"version\": \"1.2.3\"\n}\n",
+			after:  "{\n  \"version\": \"1.3.0\"\n}\n",
+			want:   "--- a/package.json\n+++ b/package.json\n@@ -1,3 +1,3 @@\n {\n-  \"version\": \"1.2.3\"\n+  \"version\": \"1.3.0\"\n }\n",
+		},
+		{
+			name:   "create",
+			before: "",
+			after:  "1.3.0\n",
+			want:   "--- a/package.json\n+++ b/package.json\n@@ -0,0 +1,1 @@\n+1.3.0\n",
+		},
+		{
+			name:   "no newline at end",
+			before: "1.2.3",
+			after:  "1.3.0",
+			want:   "--- a/package.json\n+++ b/package.json\n@@ -1,1 +1,1 @@\n-1.2.3\n\\ No newline at end of file\n+1.3.0\n\\ No newline at end of file\n",
+		},
+		{
+			name:   "two hunks",
+			before: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			after:  "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ntwelve\n",
+			want: "--- a/package.json\n+++ b/package.json\n" +
+				"@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n" +
+				"@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+twelve\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unified("a/package.json", "b/package.json", tt.before, tt.after); got != tt.want {
+				t.Errorf("Unified() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnifiedRoundTrip(t *testing.T) {
+	tests := []struct {
+		name, before, after string
+	}{
+		{"insert in the middle", "a\nb\nc\n", "a\nb\nx\nc\n"},
+		{"delete everything", "a\nb\n", ""},
+		{"rewrite", "a\nb\nc\nd\n", "d\nc\nb\na\n"},
+		{"far apart", strings.Repeat("same\n", 20) + "old\n" + strings.Repeat("same\n", 20), "new\n" + strings.Repeat("same\n", 20) + strings.Repeat("same\n", 20) + "tail"},
+		{"close together", "1\n2\n3\n4\n5\n6\n7\n8\n", "1\nB\n3\n4\n5\n6\nG\n8\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch := Unified("old", "new", tt.before, tt.after)
+			got, err := apply(tt.before, patch)
+			if err != nil {
+				t.Fatalf("%v in\n%s", err, patch)
+			}
+			if got != tt.after {
+				t.Errorf("applying\n%s\ngave %q, want %q", patch, got, tt.after)
+			}
+		})
+	}
+}
+
+// apply applies a unified diff made by Unified to before, checking the
+// context lines and hunk counts along the way.
+func apply(before, patch string) (string, error) {
+	old := splitLines(before)
+	var out []string
+	next := 0
+
+	lines := strings.SplitAfter(patch, "\n")
+	for i := 2; i < len(lines) && lines[i] != ""; {
+		var oldStart, oldCount, newStart, newCount int
+		if _, err := fmt.Sscanf(lines[i], "@@ -%d,%d +%d,%d @@", &oldStart, &oldCount, &newStart, &newCount); err != nil {
+			return "", fmt.Errorf("bad hunk header %q: %w", lines[i], err)
+		}
+		if oldCount > 0 {
+			oldStart--
+		}
+		out = append(out, old[next:oldStart]...)
+		next = oldStart
+		i++
+
+		var oldLines, newLines int
+		for ; i < len(lines) && lines[i] != "" && !strings.HasPrefix(lines[i], "@@"); i++ {
+			prefix, line := lines[i][0], lines[i][1:]
+			if i+1 < len(lines) && strings.HasPrefix(lines[i+1], "\\ No newline at end of file") {
+				line = strings.TrimSuffix(line, "\n")
+				i++
+			}
+
+			if prefix != '+' {
+				if next >= len(old) || old[next] != line {
+					return "", fmt.Errorf("line %d does not match %q", next+1, line)
+				}
+				next++
+				oldLines++
+			}
+			if prefix != '-' {
+				out = append(out, line)
+				newLines++
+			}
+		}
+		if oldLines != oldCount || newLines != newCount {
+			return "", fmt.Errorf("hunk at -%d has %d,%d lines, not %d,%d", oldStart+1, oldLines, newLines, oldCount, newCount)
+		}
+	}
+
+	out = append(out, old[next:]...)
+	return strings.Join(out, ""), nil
+}
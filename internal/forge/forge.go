@@ -0,0 +1,112 @@
+// Package forge manages pull requests on the service hosting the origin
+// remote, GitHub or GitLab, behind one interface.
+package forge
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/yendefrr/commet/internal/github"
+)
+
+// PullRequest is a GitHub pull request or a GitLab merge request.
+type PullRequest struct {
+	// Number is the pull request number, or the merge request IID on GitLab
+	Number int
+	Title  string
+	Body   string
+	URL    string
+}
+
+// Forge opens and updates pull requests of one repository.
+type Forge interface {
+	// Name is the forge name for messages, e.g. "GitHub"
+	Name() string
+	// FindPullRequest returns the open pull request from branch, or nil
+	FindPullRequest(branch string) (*PullRequest, error)
+	// CreatePullRequest opens a pull request merging branch into base
+	CreatePullRequest(branch, base, title, body string) (*PullRequest, error)
+	// UpdatePullRequest saves the title and body of pr
+	UpdatePullRequest(pr *PullRequest) error
+}
+
+// New returns the forge called name, "github" or "gitlab", for the
+// repository at remote. An empty name is detected from the remote host.
+func New(name, remote string) (Forge, error) {
+	if name == "" {
+		switch {
+		case strings.Contains(remote, "github"):
+			name = "github"
+		case strings.Contains(remote, "gitlab"):
+			name = "gitlab"
+		default:
+			return nil, fmt.Errorf("cannot tell the forge of %s: set release_pr.forge to 'github' or 'gitlab'", remote)
+		}
+	}
+
+	switch name {
+	case "github":
+		owner, repo, err := github.ParseRepoURL(remote)
+		if err != nil {
+			return nil, err
+		}
+		return &githubForge{client: github.NewClient(), owner: owner, repo: repo}, nil
+	case "gitlab":
+		project, err := projectPath(remote)
+		if err != nil {
+			return nil, err
+		}
+		return newGitLab(project), nil
+	default:
+		return nil, fmt.Errorf("unknown forge %q: must be 'github' or 'gitlab'", name)
+	}
+}
+
+// projectPath returns the full project path of a remote URL in HTTPS or SSH
+// form, e.g. group/subgroup/repo, as GitLab nests groups.
+func projectPath(remote string) (string, error) {
+	path := remote
+	if u, err := url.Parse(remote); err == nil && u.Host != "" {
+		path = u.Path
+	} else if _, after, ok := strings.Cut(remote, ":"); ok {
+		path = after
+	}
+
+	path = strings.Trim(strings.TrimSuffix(path, ".git"), "/")
+	if !strings.Contains(path, "/") {
+		return "", fmt.Errorf("cannot parse project from remote %s", remote)
+	}
+
+	return path, nil
+}
+
+type githubForge struct {
+	client *github.Client
+	owner  string
+	repo   string
+}
+
+func (f *githubForge) Name() string {
+	return "GitHub"
+}
+
+func (f *githubForge) FindPullRequest(branch string) (*PullRequest, error) {
+	pull, err := f.client.FindPullRequest(f.owner, f.repo, branch)
+	if err != nil || pull == nil {
+		return nil, err
+	}
+	return &PullRequest{Number: pull.Number, Title: pull.Title, Body: pull.Body, URL: pull.HTMLURL}, nil
+}
+
+func (f *githubForge) CreatePullRequest(branch, base, title, body string) (*PullRequest, error) {
+	pull, err := f.client.CreatePullRequest(f.owner, f.repo, branch, base, title, body)
+	if err != nil {
+		return nil, err
+	}
+	return &PullRequest{Number: pull.Number, Title: pull.Title, Body: pull.Body, URL: pull.HTMLURL}, nil
+}
+
+func (f *githubForge) UpdatePullRequest(pr *PullRequest) error {
+	return f.client.UpdatePullRequest(f.owner, f.repo, &github.PullRequest{Number: pr.Number, Title: pr.Title, Body: pr.Body})
+}
@@ -0,0 +1,204 @@
+package forge
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// apiCall is a request the fake forge received.
+type apiCall struct {
+	method string
+	path   string
+	query  string
+	token  string
+	body   map[string]interface{}
+}
+
+// newAPI serves responses by "METHOD /path" and records every call.
+func newAPI(t *testing.T, responses map[string]string, calls *[]apiCall) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := apiCall{
+			method: r.Method,
+			path:   r.URL.EscapedPath(),
+			query:  r.URL.RawQuery,
+			token:  r.Header.Get("Authorization") + r.Header.Get("PRIVATE-TOKEN"),
+		}
+		if r.Body != nil {
+			json.NewDecoder(r.Body).Decode(&call.body)
+		}
+		*calls = append(*calls, call)
+
+		response, ok := responses[r.Method+" "+call.path]
+		if !ok {
+			http.Error(w, `{"message": "Not Found"}`, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		forge   string
+		remote  string
+		want    string
+		wantErr bool
+	}{
+		{"github detected", "", "git@github.com:org/app.git", "GitHub", false},
+		{"gitlab detected", "", "https://gitlab.example.com/group/sub/app.git", "GitLab", false},
+		{"gitlab named", "gitlab", "git@git.example.com:group/app.git", "GitLab", false},
+		{"undetectable", "", "https://git.example.com/org/app.git", "", true},
+		{"unknown", "gitea", "https://git.example.com/org/app.git", "", true},
+		{"no project", "gitlab", "https://gitlab.com/app", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forge, err := New(tt.forge, tt.remote)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("New(%q, %q) = %s, want an error", tt.forge, tt.remote, forge.Name())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if forge.Name() != tt.want {
+				t.Errorf("New(%q, %q) = %s, want %s", tt.forge, tt.remote, forge.Name(), tt.want)
+			}
+		})
+	}
+}
+
+func TestGitHubPullRequests(t *testing.T) {
+	var calls []apiCall
+	server := newAPI(t, map[string]string{
+		"GET /repos/org/app/pulls":     `[{"number": 7, "title": "Release 1.3.0", "body": "notes", "html_url": "https://github.com/org/app/pull/7"}]`,
+		"POST /repos/org/app/pulls":    `{"number": 8, "title": "Release 1.4.0", "body": "new", "html_url": "https://github.com/org/app/pull/8"}`,
+		"PATCH /repos/org/app/pulls/7": `{}`,
+	}, &calls)
+	t.Setenv("GITHUB_API_URL", server.URL)
+	t.Setenv("GITHUB_TOKEN", "gh-token")
+
+	forge, err := New("", "git@github.com:org/app.git")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pr, err := forge.FindPullRequest("release/next")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := PullRequest{Number: 7, Title: "Release 1.3.0", Body: "notes", URL: "https://github.com/org/app/pull/7"}
+	if pr == nil || *pr != want {
+		t.Fatalf("FindPullRequest() = %+v, want %+v", pr, want)
+	}
+	if calls[0].query != "state=open&head=org%3Arelease%2Fnext" {
+		t.Errorf("FindPullRequest() queried %q", calls[0].query)
+	}
+	if calls[0].token != "Bearer gh-token" {
+		t.Errorf("Authorization = %q, want the token", calls[0].token)
+	}
+
+	created, err := forge.CreatePullRequest("release/next", "main", "Release 1.4.0", "new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Number != 8 || created.URL != "https://github.com/org/app/pull/8" {
+		t.Errorf("CreatePullRequest() = %+v", created)
+	}
+	wantBody := map[string]interface{}{"head": "release/next", "base": "main", "title": "Release 1.4.0", "body": "new"}
+	if !equalBody(calls[1].body, wantBody) {
+		t.Errorf("CreatePullRequest() sent %v, want %v", calls[1].body, wantBody)
+	}
+
+	pr.Title, pr.Body = "Release 1.3.1", "more notes"
+	if err := forge.UpdatePullRequest(pr); err != nil {
+		t.Fatal(err)
+	}
+	wantBody = map[string]interface{}{"title": "Release 1.3.1", "body": "more notes"}
+	if calls[2].method != http.MethodPatch || !equalBody(calls[2].body, wantBody) {
+		t.Errorf("UpdatePullRequest() sent %s %v, want PATCH %v", calls[2].method, calls[2].body, wantBody)
+	}
+
+	if err := forge.UpdatePullRequest(&PullRequest{Number: 9}); err == nil {
+		t.Error("UpdatePullRequest() of a missing pull request succeeded")
+	}
+}
+
+func TestGitLabMergeRequests(t *testing.T) {
+	var calls []apiCall
+	server := newAPI(t, map[string]string{
+		"GET /projects/group%2Fsub%2Fapp/merge_requests":   `[]`,
+		"POST /projects/group%2Fsub%2Fapp/merge_requests":  `{"iid": 3, "title": "Release 1.3.0", "description": "notes", "web_url": "https://gitlab.com/group/sub/app/-/merge_requests/3"}`,
+		"PUT /projects/group%2Fsub%2Fapp/merge_requests/3": `{}`,
+	}, &calls)
+	t.Setenv("GITLAB_API_URL", server.URL+"/")
+	t.Setenv("GITLAB_TOKEN", "gl-token")
+
+	forge, err := New("", "git@gitlab.com:group/sub/app.git")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pr, err := forge.FindPullRequest("release/next")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr != nil {
+		t.Fatalf("FindPullRequest() = %+v, want none", pr)
+	}
+	if calls[0].query != "state=opened&source_branch=release%2Fnext" {
+		t.Errorf("FindPullRequest() queried %q", calls[0].query)
+	}
+	if calls[0].token != "gl-token" {
+		t.Errorf("PRIVATE-TOKEN = %q, want the token", calls[0].token)
+	}
+
+	pr, err = forge.CreatePullRequest("release/next", "main", "Release 1.3.0", "notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := PullRequest{Number: 3, Title: "Release 1.3.0", Body: "notes", URL: "https://gitlab.com/group/sub/app/-/merge_requests/3"}
+	if *pr != want {
+		t.Errorf("CreatePullRequest() = %+v, want %+v", pr, want)
+	}
+	wantBody := map[string]interface{}{"source_branch": "release/next", "target_branch": "main", "title": "Release 1.3.0", "description": "notes"}
+	if !equalBody(calls[1].body, wantBody) {
+		t.Errorf("CreatePullRequest() sent %v, want %v", calls[1].body, wantBody)
+	}
+
+	pr.Body = "more notes"
+	if err := forge.UpdatePullRequest(pr); err != nil {
+		t.Fatal(err)
+	}
+	wantBody = map[string]interface{}{"title": "Release 1.3.0", "description": "more notes"}
+	if calls[2].method != http.MethodPut || !equalBody(calls[2].body, wantBody) {
+		t.Errorf("UpdatePullRequest() sent %s %v, want PUT %v", calls[2].method, calls[2].body, wantBody)
+	}
+
+	if err := forge.UpdatePullRequest(&PullRequest{Number: 4}); err == nil {
+		t.Error("UpdatePullRequest() of a missing merge request succeeded")
+	}
+}
+
+func equalBody(got, want map[string]interface{}) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for key, value := range want {
+		if got[key] != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,148 @@
+package forge
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const defaultGitLabAPIURL = "https://gitlab.com/api/v4"
+
+// gitlabForge manages merge requests through the GitLab REST API. The API
+// URL is read from GITLAB_API_URL or, in GitLab CI, CI_API_V4_URL, and the
+// token from GITLAB_TOKEN.
+type gitlabForge struct {
+	apiURL  string
+	token   string
+	project string
+	http    *http.Client
+}
+
+func newGitLab(project string) *gitlabForge {
+	apiURL := os.Getenv("GITLAB_API_URL")
+	if apiURL == "" {
+		apiURL = os.Getenv("CI_API_V4_URL")
+	}
+	if apiURL == "" {
+		apiURL = defaultGitLabAPIURL
+	}
+
+	return &gitlabForge{
+		apiURL:  strings.TrimSuffix(apiURL, "/"),
+		token:   os.Getenv("GITLAB_TOKEN"),
+		project: project,
+		http:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type mergeRequest struct {
+	IID         int    `json:"iid"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	WebURL      string `json:"web_url"`
+}
+
+func (m *mergeRequest) pullRequest() *PullRequest {
+	return &PullRequest{Number: m.IID, Title: m.Title, Body: m.Description, URL: m.WebURL}
+}
+
+func (f *gitlabForge) Name() string {
+	return "GitLab"
+}
+
+func (f *gitlabForge) FindPullRequest(branch string) (*PullRequest, error) {
+	endpoint := fmt.Sprintf("%s/merge_requests?state=opened&source_branch=%s", f.projectURL(), url.QueryEscape(branch))
+
+	var requests []*mergeRequest
+	if err := f.send(http.MethodGet, endpoint, nil, &requests); err != nil {
+		return nil, err
+	}
+	if len(requests) == 0 {
+		return nil, nil
+	}
+
+	return requests[0].pullRequest(), nil
+}
+
+func (f *gitlabForge) CreatePullRequest(branch, base, title, body string) (*PullRequest, error) {
+	payload := map[string]interface{}{
+		"source_branch": branch,
+		"target_branch": base,
+		"title":         title,
+		"description":   body,
+	}
+
+	var request mergeRequest
+	if err := f.send(http.MethodPost, f.projectURL()+"/merge_requests", payload, &request); err != nil {
+		return nil, err
+	}
+
+	return request.pullRequest(), nil
+}
+
+func (f *gitlabForge) UpdatePullRequest(pr *PullRequest) error {
+	endpoint := fmt.Sprintf("%s/merge_requests/%d", f.projectURL(), pr.Number)
+
+	payload := map[string]interface{}{
+		"title":       pr.Title,
+		"description": pr.Body,
+	}
+
+	return f.send(http.MethodPut, endpoint, payload, nil)
+}
+
+func (f *gitlabForge) projectURL() string {
+	return f.apiURL + "/projects/" + url.PathEscape(f.project)
+}
+
+// send performs an API request, encoding payload as JSON when set and
+// decoding the response into v when set.
+func (f *gitlabForge) send(method, endpoint string, payload, v interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, endpoint, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if f.token != "" {
+		req.Header.Set("PRIVATE-TOKEN", f.token)
+	}
+
+	resp, err := f.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("GitLab request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("GitLab API returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	if v == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode GitLab response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+// Package fsutil writes files in place of the ones commet updates, keeping
+// their permissions and owner.
+package fsutil
+
+import (
+	"fmt"
+	"os"
+)
+
+const defaultFileMode os.FileMode = 0644
+
+// WriteFile replaces the content of path while keeping its permission bits
+// and, when running as root, its owner. New files are created with 0644.
+func WriteFile(path string, data []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+		return os.WriteFile(path, data, defaultFileMode)
+	}
+
+	mode := info.Mode().Perm()
+	if err := os.WriteFile(path, data, mode); err != nil {
+		return err
+	}
+
+	// WriteFile only applies mode on creation; enforce it in case the file was replaced
+	if err := os.Chmod(path, mode); err != nil {
+		return fmt.Errorf("failed to restore permissions on %s: %w", path, err)
+	}
+
+	return RestoreOwner(path, info)
+}
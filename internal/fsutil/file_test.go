@@ -0,0 +1,46 @@
+package fsutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+
+	script := filepath.Join(dir, "version.sh")
+	if err := os.WriteFile(script, []byte("echo 1.2.3\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFile(script, []byte("echo 1.3.0\n")); err != nil {
+		t.Fatal(err)
+	}
+	assertFile(t, script, "echo 1.3.0\n", 0755)
+
+	created := filepath.Join(dir, "VERSION")
+	if err := WriteFile(created, []byte("1.3.0\n")); err != nil {
+		t.Fatal(err)
+	}
+	assertFile(t, created, "1.3.0\n", defaultFileMode)
+}
+
+func assertFile(t *testing.T, path, content string, mode os.FileMode) {
+	t.Helper()
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != content {
+		t.Errorf("%s = %q, want %q", path, got, content)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != mode {
+		t.Errorf("%s has mode %v, want %v", path, info.Mode().Perm(), mode)
+	}
+}
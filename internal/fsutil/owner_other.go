@@ -0,0 +1,10 @@
+//go:build !unix
+
+package fsutil
+
+import "os"
+
+// RestoreOwner is a no-op where files have no Unix owner.
+func RestoreOwner(path string, info os.FileInfo) error {
+	return nil
+}
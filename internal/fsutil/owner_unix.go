@@ -0,0 +1,28 @@
+//go:build unix
+
+package fsutil
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// RestoreOwner gives path back the owner recorded in info. Only root can
+// change ownership, so it is a no-op for everyone else.
+func RestoreOwner(path string, info os.FileInfo) error {
+	if os.Geteuid() != 0 {
+		return nil
+	}
+
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+
+	if err := os.Lchown(path, int(stat.Uid), int(stat.Gid)); err != nil {
+		return fmt.Errorf("failed to restore owner on %s: %w", path, err)
+	}
+
+	return nil
+}
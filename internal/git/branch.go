@@ -0,0 +1,109 @@
+package git
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// Head returns what HEAD points to, a branch name or a commit hash when it
+// is detached, for Checkout to return to later.
+func (c *Client) Head() (string, error) {
+	head, err := c.repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	if head.Name().IsBranch() {
+		return head.Name().Short(), nil
+	}
+	return head.Hash().String(), nil
+}
+
+// ResetBranch points branch at HEAD, creating it if needed, and checks it
+// out. Uncommitted changes are kept.
+func (c *Client) ResetBranch(branch string) error {
+	head, err := c.repo.Head()
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	name := plumbing.NewBranchReferenceName(branch)
+	if err := c.repo.Storer.SetReference(plumbing.NewHashReference(name, head.Hash())); err != nil {
+		return fmt.Errorf("failed to reset branch %s: %w", branch, err)
+	}
+
+	worktree, err := c.repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+	if err := worktree.Checkout(&git.CheckoutOptions{Branch: name, Keep: true}); err != nil {
+		return fmt.Errorf("failed to check out %s: %w", branch, err)
+	}
+
+	return nil
+}
+
+// Checkout switches the worktree to rev, a branch name or a commit hash as
+// returned by Head.
+func (c *Client) Checkout(rev string) error {
+	worktree, err := c.repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	opts := &git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName(rev), Force: true}
+	if _, err := c.repo.Reference(opts.Branch, false); err != nil {
+		opts = &git.CheckoutOptions{Hash: plumbing.NewHash(rev), Force: true}
+	}
+
+	if err := worktree.Checkout(opts); err != nil {
+		return fmt.Errorf("failed to check out %s: %w", rev, err)
+	}
+
+	return nil
+}
+
+// Push sends refs, such as "refs/heads/main" or "refs/tags/v1.2.0", to the
+// remote under the same name. With force the remote refs are overwritten.
+// See remoteAuth for how the remote is authenticated.
+func (c *Client) Push(remote string, force bool, refs ...string) error {
+	var specs []gitconfig.RefSpec
+	for _, ref := range refs {
+		spec := ref + ":" + ref
+		if force {
+			spec = "+" + spec
+		}
+		specs = append(specs, gitconfig.RefSpec(spec))
+	}
+
+	return c.push(remote, specs)
+}
+
+// Dirty returns the tracked files with uncommitted changes.
+func (c *Client) Dirty() ([]string, error) {
+	worktree, err := c.repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status: %w", err)
+	}
+
+	var files []string
+	for file, s := range status {
+		if s.Worktree == git.Untracked {
+			continue
+		}
+		if s.Worktree != git.Unmodified || s.Staging != git.Unmodified {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
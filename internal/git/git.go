@@ -2,20 +2,31 @@ package git
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/yendefrr/commet/internal/config"
+	"github.com/yendefrr/commet/internal/version"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/filesystem"
 )
 
 type Client struct {
 	repo   *git.Repository
 	config *config.Config
+
+	dangling []string
+
+	// line and head restrict GetLatestTag on maintenance branches
+	line string
+	head plumbing.Hash
 }
 
 func NewClient(repoPath string, cfg *config.Config) (*Client, error) {
@@ -31,10 +42,18 @@ func NewClient(repoPath string, cfg *config.Config) (*Client, error) {
 }
 
 type CommitInfo struct {
-	Hash    string
+	// Hash is abbreviated to changelog.hash_length, FullHash is the full SHA
+	Hash     string
+	FullHash string
+	// Message is the subject line, Body the rest of the message
 	Message string
+	Body    string
 	Author  string
 	Date    string
+	// Files is only filled in when path rules are configured
+	Files []string
+	// Note holds the commit's overrides from "commet annotate", if any
+	Note string
 }
 
 func (c *Client) GetCommits(from, to string) ([]*CommitInfo, error) {
@@ -45,19 +64,28 @@ func (c *Client) GetCommits(from, to string) ([]*CommitInfo, error) {
 		}
 	}
 
+	return c.Log(from, to)
+}
+
+// Log returns the commits reachable from to, newest first, stopping at from;
+// with changelog.order "topo" every commit comes before its parents. Unlike
+// GetCommits an empty from means the whole history.
+//
+// Only commit objects are read: no trees and no blobs besides commet notes,
+// which keeps memory flat on large monorepos. Trees are diffed only when path
+// rules need the changed files. TestLogReadsNoBlobs guards this.
+func (c *Client) Log(from, to string) ([]*CommitInfo, error) {
 	toRef, err := c.repo.ResolveRevision(plumbing.Revision(to))
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve 'to' ref %s: %w", to, err)
 	}
 
-	logOptions := &git.LogOptions{
-		From: *toRef,
-	}
-
-	commitIter, err := c.repo.Log(logOptions)
+	head, err := c.repo.CommitObject(*toRef)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get git log: %w", err)
 	}
+
+	commitIter := object.NewCommitPreorderIter(head, nil, nil)
 	defer commitIter.Close()
 
 	var fromHash plumbing.Hash
@@ -69,23 +97,54 @@ func (c *Client) GetCommits(from, to string) ([]*CommitInfo, error) {
 		fromHash = *fromRef
 	}
 
+	ignored, err := LoadIgnoreFile(c.config.Detection.IgnoreFile)
+	if err != nil {
+		return nil, err
+	}
+
+	notes, err := c.Notes()
+	if err != nil {
+		return nil, err
+	}
+
 	var commits []*CommitInfo
+	var walked []plumbing.Hash
+	parents := make(map[plumbing.Hash][]plumbing.Hash)
 	err = commitIter.ForEach(func(commit *object.Commit) error {
 		if from != "" && commit.Hash == fromHash {
 			return fmt.Errorf("stop")
 		}
 
+		walked = append(walked, commit.Hash)
+		parents[commit.Hash] = commit.ParentHashes
+
 		if c.config.Detection.ExcludeMerges && len(commit.ParentHashes) > 1 {
 			return nil
 		}
 
-		message := strings.Split(commit.Message, "\n")[0]
+		if ignored.Ignores(commit.Hash.String(), commit.Message) {
+			return nil
+		}
+
+		message, body := SplitMessage(commit.Message)
+
+		var files []string
+		if len(c.config.PathRules) > 0 {
+			var err error
+			if files, err = commitFiles(commit); err != nil {
+				return err
+			}
+		}
 
 		commits = append(commits, &CommitInfo{
-			Hash:    commit.Hash.String()[:7],
-			Message: message,
-			Author:  commit.Author.Name,
-			Date:    commit.Author.When.Format("2006-01-02"),
+			Hash:     Abbrev(commit.Hash.String(), c.config.Changelog.HashLength),
+			FullHash: commit.Hash.String(),
+			Message:  message,
+			Body:     body,
+			Author:   commit.Author.Name,
+			Date:     commit.Author.When.Format("2006-01-02"),
+			Files:    files,
+			Note:     notes[commit.Hash.String()],
 		})
 
 		return nil
@@ -95,46 +154,294 @@ func (c *Client) GetCommits(from, to string) ([]*CommitInfo, error) {
 		return nil, fmt.Errorf("failed to iterate commits: %w", err)
 	}
 
+	if c.config.Changelog.Order == "topo" {
+		commits = topoOrder(commits, walked, parents)
+	}
+
 	return commits, nil
 }
 
-func (c *Client) GetLatestTag() (string, error) {
+// topoOrder sorts commits so each one comes before its parents, like git log
+// --topo-order, and otherwise keeps the order history was walked in. walked
+// and parents cover every walked commit, including skipped merges, so their
+// ancestry still orders the commits around them.
+func topoOrder(commits []*CommitInfo, walked []plumbing.Hash, parents map[plumbing.Hash][]plumbing.Hash) []*CommitInfo {
+	index := make(map[plumbing.Hash]int, len(walked))
+	for i, hash := range walked {
+		index[hash] = i
+	}
+
+	pending := make([]int, len(walked))
+	for _, hash := range walked {
+		for _, parent := range parents[hash] {
+			if i, ok := index[parent]; ok {
+				pending[i]++
+			}
+		}
+	}
+
+	var ready []int
+	for i := range walked {
+		if pending[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	position := make(map[string]int, len(walked))
+	for len(ready) > 0 {
+		// The earliest walked commit whose children are all placed goes next
+		next := 0
+		for j := range ready {
+			if ready[j] < ready[next] {
+				next = j
+			}
+		}
+		i := ready[next]
+		ready = append(ready[:next], ready[next+1:]...)
+
+		position[walked[i].String()] = len(position)
+		for _, parent := range parents[walked[i]] {
+			if j, ok := index[parent]; ok {
+				if pending[j]--; pending[j] == 0 {
+					ready = append(ready, j)
+				}
+			}
+		}
+	}
+
+	sorted := append([]*CommitInfo(nil), commits...)
+	sort.SliceStable(sorted, func(a, b int) bool {
+		return position[sorted[a].FullHash] < position[sorted[b].FullHash]
+	})
+	return sorted
+}
+
+// ReleaseDate returns when rev was released: the tagger date of an annotated
+// tag, otherwise the committer date of the commit it points to.
+func (c *Client) ReleaseDate(rev string) (time.Time, error) {
+	if ref, err := c.repo.Tag(rev); err == nil {
+		if tag, err := c.repo.TagObject(ref.Hash()); err == nil {
+			return tag.Tagger.When, nil
+		}
+	}
+
+	hash, err := c.repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to resolve %s: %w", rev, err)
+	}
+
+	commit, err := c.repo.CommitObject(*hash)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get commit %s: %w", rev, err)
+	}
+
+	return commit.Committer.When, nil
+}
+
+// SplitMessage splits a commit message into its subject line and the trimmed body.
+func SplitMessage(message string) (string, string) {
+	subject, body, _ := strings.Cut(message, "\n")
+	return strings.TrimRight(subject, "\r"), strings.TrimSpace(body)
+}
+
+// FullMessage joins the subject and body again, for the parser.
+func (c *CommitInfo) FullMessage() string {
+	if c.Body == "" {
+		return c.Message
+	}
+	return c.Message + "\n\n" + c.Body
+}
+
+// ReadFile returns the content of path in the commit rev points to, e.g. a
+// changelog at a release tag.
+func (c *Client) ReadFile(rev, path string) ([]byte, error) {
+	hash, err := c.repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", rev, err)
+	}
+
+	commit, err := c.repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit %s: %w", rev, err)
+	}
+
+	file, err := commit.File(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s at %s: %w", path, rev, err)
+	}
+
+	content, err := file.Contents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s at %s: %w", path, rev, err)
+	}
+
+	return []byte(content), nil
+}
+
+// Tags returns the names of all tags matching the tag pattern, in no
+// particular order. Tags whose target is missing are left out; see DanglingTags.
+func (c *Client) Tags() ([]string, error) {
 	tags, err := c.repo.Tags()
 	if err != nil {
-		return "", fmt.Errorf("failed to get tags: %w", err)
+		return nil, fmt.Errorf("failed to get tags: %w", err)
 	}
 	defer tags.Close()
 
 	pattern, err := regexp.Compile(c.config.Detection.TagPattern)
 	if err != nil {
-		return "", fmt.Errorf("invalid tag pattern: %w", err)
+		return nil, fmt.Errorf("invalid tag pattern: %w", err)
 	}
 
-	var matchingTags []string
+	var names []string
 	err = tags.ForEach(func(ref *plumbing.Reference) error {
-		tagName := ref.Name().Short()
-		if pattern.MatchString(tagName) {
-			matchingTags = append(matchingTags, tagName)
+		name := ref.Name().Short()
+		if !pattern.MatchString(name) {
+			return nil
+		}
+
+		if !c.resolvesToCommit(ref) {
+			c.addDangling(name)
+			return nil
 		}
+
+		names = append(names, name)
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	return names, nil
+}
+
+// RestrictToLine makes GetLatestTag consider only tags reachable from rev
+// whose version is in line, e.g. "1.2.x" on a release/1.2.x branch.
+func (c *Client) RestrictToLine(rev, line string) error {
+	hash, err := c.repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", rev, err)
+	}
+
+	c.line = line
+	c.head = *hash
+	return nil
+}
+
+func (c *Client) GetLatestTag() (string, error) {
+	matchingTags, err := c.Tags()
 	if err != nil {
 		return "", err
 	}
 
+	if c.line != "" {
+		if matchingTags, err = c.lineTags(matchingTags); err != nil {
+			return "", err
+		}
+	}
+
 	if len(matchingTags) == 0 {
 		return "", nil
 	}
 
+	// Order by the configured version scheme so v1.10.0 beats v1.9.0, v1.3.0
+	// beats v1.3.0-rc.1 and 1.2.3.10 beats 1.2.3.9
+	scheme := version.SchemeFor(c.config)
+	versions := make(map[string]string, len(matchingTags))
+	for _, tag := range matchingTags {
+		v, err := c.ExtractVersionFromTag(tag)
+		if err != nil {
+			continue
+		}
+		if _, err := scheme.Parse(v); err == nil {
+			versions[tag] = v
+		}
+	}
+
 	sort.Slice(matchingTags, func(i, j int) bool {
-		return matchingTags[i] > matchingTags[j]
+		a, b := versions[matchingTags[i]], versions[matchingTags[j]]
+		switch {
+		case a != "" && b != "":
+			result, _ := scheme.Compare(a, b)
+			return result > 0
+		case a != "" || b != "":
+			return a != ""
+		default:
+			return matchingTags[i] > matchingTags[j]
+		}
 	})
 
 	return matchingTags[0], nil
 }
 
+// lineTags keeps the tags in the restricted line that are reachable from head.
+func (c *Client) lineTags(tags []string) ([]string, error) {
+	head, err := c.repo.CommitObject(c.head)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit %s: %w", c.head, err)
+	}
+
+	reachable := make(map[plumbing.Hash]bool)
+	iter := object.NewCommitPreorderIter(head, nil, nil)
+	defer iter.Close()
+	if err := iter.ForEach(func(commit *object.Commit) error {
+		reachable[commit.Hash] = true
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to walk history of %s: %w", c.head, err)
+	}
+
+	var kept []string
+	for _, tag := range tags {
+		v, err := c.ExtractVersionFromTag(tag)
+		if err != nil || !version.InLine(v, c.line) {
+			continue
+		}
+
+		hash, err := c.repo.ResolveRevision(plumbing.Revision("refs/tags/" + tag))
+		if err != nil || !reachable[*hash] {
+			continue
+		}
+
+		kept = append(kept, tag)
+	}
+
+	return kept, nil
+}
+
+// DanglingTags returns the matching tags skipped so far because their target
+// is no longer in the repository, e.g. after a history rewrite.
+func (c *Client) DanglingTags() []string {
+	return c.dangling
+}
+
+func (c *Client) addDangling(tag string) {
+	for _, t := range c.dangling {
+		if t == tag {
+			return
+		}
+	}
+	c.dangling = append(c.dangling, tag)
+}
+
+// resolvesToCommit reports whether the tag ref, lightweight or annotated,
+// points to a commit that exists.
+func (c *Client) resolvesToCommit(ref *plumbing.Reference) bool {
+	if tag, err := c.repo.TagObject(ref.Hash()); err == nil {
+		_, err = tag.Commit()
+		return err == nil
+	}
+
+	_, err := c.repo.CommitObject(ref.Hash())
+	return err == nil
+}
+
 func (c *Client) ExtractVersionFromTag(tag string) (string, error) {
-	pattern, err := regexp.Compile(c.config.Detection.TagPattern)
+	return ExtractVersion(c.config.Detection.TagPattern, tag)
+}
+
+// ExtractVersion returns the first capture group of tagPattern matched against tag.
+func ExtractVersion(tagPattern, tag string) (string, error) {
+	pattern, err := regexp.Compile(tagPattern)
 	if err != nil {
 		return "", fmt.Errorf("invalid tag pattern: %w", err)
 	}
@@ -160,7 +467,12 @@ func (c *Client) CreateCommit(files []string, message string) error {
 		}
 	}
 
-	_, err = worktree.Commit(message, &git.CommitOptions{})
+	signer, err := c.signer()
+	if err != nil {
+		return err
+	}
+
+	_, err = worktree.Commit(message, &git.CommitOptions{Signer: signer})
 	if err != nil {
 		return fmt.Errorf("failed to create commit: %w", err)
 	}
@@ -168,12 +480,32 @@ func (c *Client) CreateCommit(files []string, message string) error {
 	return nil
 }
 
+// CreateTag tags HEAD as git.tag_type configures: an annotated tag with
+// message, signed when git.sign is set, or a lightweight tag without one.
 func (c *Client) CreateTag(tag, message string) error {
 	head, err := c.repo.Head()
 	if err != nil {
 		return fmt.Errorf("failed to get HEAD: %w", err)
 	}
 
+	if c.config.Git.TagType == "lightweight" {
+		if _, err := c.repo.CreateTag(tag, head.Hash(), nil); err != nil {
+			return fmt.Errorf("failed to create tag: %w", err)
+		}
+		return nil
+	}
+
+	signer, err := c.signer()
+	if err != nil {
+		return err
+	}
+	if signer != nil {
+		if err := c.createSignedTag(tag, message, head.Hash(), signer); err != nil {
+			return fmt.Errorf("failed to create tag: %w", err)
+		}
+		return nil
+	}
+
 	opts := &git.CreateTagOptions{
 		Message: message,
 	}
@@ -186,6 +518,177 @@ func (c *Client) CreateTag(tag, message string) error {
 	return nil
 }
 
+// MoveTag points the lightweight tag at HEAD, creating it or moving it from
+// the commit it tagged before. It reports whether the tag already existed.
+func (c *Client) MoveTag(tag string) (bool, error) {
+	head, err := c.repo.Head()
+	if err != nil {
+		return false, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	name := plumbing.NewTagReferenceName(tag)
+	_, err = c.repo.Reference(name, false)
+	existed := err == nil
+
+	if err := c.repo.Storer.SetReference(plumbing.NewHashReference(name, head.Hash())); err != nil {
+		return false, fmt.Errorf("failed to move tag %s: %w", tag, err)
+	}
+
+	return existed, nil
+}
+
+// Abbrev shortens hash to length characters; length 0 keeps the default of 7.
+func Abbrev(hash string, length int) string {
+	if length <= 0 {
+		length = 7
+	}
+	if len(hash) <= length {
+		return hash
+	}
+	return hash[:length]
+}
+
+// ShortHash returns the hash of the commit rev points to, abbreviated to
+// changelog.hash_length.
+func (c *Client) ShortHash(rev string) (string, error) {
+	hash, err := c.repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", rev, err)
+	}
+	return Abbrev(hash.String(), c.config.Changelog.HashLength), nil
+}
+
+// ChangedFiles returns the paths added, modified or deleted by the commit.
+func (c *Client) ChangedFiles(hash string) ([]string, error) {
+	ref, err := c.repo.ResolveRevision(plumbing.Revision(hash))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve commit %s: %w", hash, err)
+	}
+
+	commit, err := c.repo.CommitObject(*ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit %s: %w", hash, err)
+	}
+
+	return commitFiles(commit)
+}
+
+// commitFiles diffs commit against its first parent.
+func commitFiles(commit *object.Commit) ([]string, error) {
+	hash := commit.Hash.String()[:7]
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree of %s: %w", hash, err)
+	}
+
+	var parentTree *object.Tree
+	if commit.NumParents() > 0 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get parent of %s: %w", hash, err)
+		}
+		if parentTree, err = parent.Tree(); err != nil {
+			return nil, fmt.Errorf("failed to get tree of %s: %w", parent.Hash, err)
+		}
+	}
+
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff %s: %w", hash, err)
+	}
+
+	var files []string
+	for _, change := range changes {
+		name := change.To.Name
+		if name == "" {
+			name = change.From.Name
+		}
+		files = append(files, name)
+	}
+
+	return files, nil
+}
+
+// CurrentBranch returns the checked-out branch. On a detached HEAD, as in
+// most CI checkouts, it falls back to the branch reported by the CI system.
+func (c *Client) CurrentBranch() (string, error) {
+	head, err := c.repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	if head.Name().IsBranch() {
+		return head.Name().Short(), nil
+	}
+
+	for _, env := range []string{"GITHUB_HEAD_REF", "GITHUB_REF_NAME", "CI_COMMIT_BRANCH", "BRANCH_NAME"} {
+		if branch := os.Getenv(env); branch != "" {
+			return branch, nil
+		}
+	}
+
+	return "", fmt.Errorf("HEAD is detached and no CI branch variable is set")
+}
+
+// RemoteURL returns the first URL configured for the named remote.
+func (c *Client) RemoteURL(name string) (string, error) {
+	remote, err := c.repo.Remote(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to get remote %s: %w", name, err)
+	}
+
+	urls := remote.Config().URLs
+	if len(urls) == 0 {
+		return "", fmt.Errorf("remote %s has no URL", name)
+	}
+
+	return urls[0], nil
+}
+
+// DefaultBranch returns the branch origin/HEAD points to, falling back to a
+// local main or master branch when the remote HEAD is unknown.
+func (c *Client) DefaultBranch() (string, error) {
+	ref, err := c.repo.Reference(plumbing.NewRemoteHEADReferenceName("origin"), false)
+	if err == nil && ref.Type() == plumbing.SymbolicReference {
+		return strings.TrimPrefix(ref.Target().String(), "refs/remotes/origin/"), nil
+	}
+
+	for _, branch := range []string{"main", "master"} {
+		if _, err := c.repo.Reference(plumbing.NewBranchReferenceName(branch), false); err == nil {
+			return branch, nil
+		}
+	}
+
+	return "", fmt.Errorf("cannot determine the default branch: origin/HEAD is not set")
+}
+
+// HooksDir returns the directory git runs hooks from: core.hooksPath,
+// relative to the work tree, or the hooks directory of the git directory.
+func (c *Client) HooksDir() (string, error) {
+	cfg, err := c.repo.Config()
+	if err != nil {
+		return "", fmt.Errorf("failed to read git config: %w", err)
+	}
+
+	if hooksPath := cfg.Raw.Section("core").Option("hooksPath"); hooksPath != "" {
+		if filepath.IsAbs(hooksPath) {
+			return hooksPath, nil
+		}
+		worktree, err := c.repo.Worktree()
+		if err != nil {
+			return "", fmt.Errorf("failed to get worktree: %w", err)
+		}
+		return filepath.Join(worktree.Filesystem.Root(), hooksPath), nil
+	}
+
+	storage, ok := c.repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return "", fmt.Errorf("the repository has no git directory")
+	}
+	return filepath.Join(storage.Filesystem().Root(), "hooks"), nil
+}
+
 func IsGitRepository(path string) bool {
 	_, err := git.PlainOpen(path)
 	return err == nil
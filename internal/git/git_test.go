@@ -0,0 +1,446 @@
+package git
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yendefrr/commet/internal/config"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// countingStorage records how many blobs are read from the object store.
+type countingStorage struct {
+	*memory.Storage
+	blobs int
+}
+
+func (s *countingStorage) EncodedObject(t plumbing.ObjectType, h plumbing.Hash) (plumbing.EncodedObject, error) {
+	obj, err := s.Storage.EncodedObject(t, h)
+	if err == nil && obj.Type() == plumbing.BlobObject {
+		s.blobs++
+	}
+	return obj, err
+}
+
+// newTestRepo commits count changes, each rewriting a large file, and tags
+// the first commit v1.0.0.
+func newTestRepo(tb testing.TB, count int) (*Client, *countingStorage) {
+	tb.Helper()
+
+	storage := &countingStorage{Storage: memory.NewStorage()}
+	fs := memfs.New()
+
+	repo, err := git.Init(storage, fs)
+	if err != nil {
+		tb.Fatalf("init: %v", err)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		tb.Fatalf("worktree: %v", err)
+	}
+
+	payload := strings.Repeat("x", 64*1024)
+	signature := &object.Signature{Name: "test", Email: "test@example.com", When: time.Unix(1700000000, 0)}
+
+	for i := 0; i < count; i++ {
+		name := fmt.Sprintf("pkg%d/data.txt", i%10)
+		file, err := fs.Create(name)
+		if err != nil {
+			tb.Fatalf("create: %v", err)
+		}
+		fmt.Fprintf(file, "%d%s", i, payload)
+		file.Close()
+
+		if _, err := worktree.Add(name); err != nil {
+			tb.Fatalf("add: %v", err)
+		}
+
+		hash, err := worktree.Commit(fmt.Sprintf("Fix(pkg%d): change %d", i%10, i), &git.CommitOptions{Author: signature})
+		if err != nil {
+			tb.Fatalf("commit: %v", err)
+		}
+
+		if i == 0 {
+			if _, err := repo.CreateTag("v1.0.0", hash, nil); err != nil {
+				tb.Fatalf("tag: %v", err)
+			}
+		}
+	}
+
+	cfg := config.DefaultConfig()
+	if err := cfg.Validate(); err != nil {
+		tb.Fatalf("config: %v", err)
+	}
+
+	storage.blobs = 0
+	return &Client{repo: repo, config: cfg}, storage
+}
+
+func TestLogReadsNoBlobs(t *testing.T) {
+	client, storage := newTestRepo(t, 50)
+
+	commits, err := client.GetCommits("", "HEAD")
+	if err != nil {
+		t.Fatalf("GetCommits() error = %v", err)
+	}
+
+	if len(commits) != 49 {
+		t.Errorf("GetCommits() returned %d commits, want 49", len(commits))
+	}
+	if storage.blobs != 0 {
+		t.Errorf("GetCommits() read %d blobs, want 0", storage.blobs)
+	}
+}
+
+func TestChangedFilesReadsNoBlobs(t *testing.T) {
+	client, storage := newTestRepo(t, 5)
+	client.config.PathRules = []config.PathRuleConfig{{Paths: []string{"docs/**"}, Bump: config.BumpNone}}
+
+	commits, err := client.Log("", "HEAD")
+	if err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+
+	if got := commits[0].Files; len(got) != 1 || got[0] != "pkg4/data.txt" {
+		t.Errorf("Files = %v, want [pkg4/data.txt]", got)
+	}
+	if storage.blobs != 0 {
+		t.Errorf("Log() with path rules read %d blobs, want 0", storage.blobs)
+	}
+}
+
+func BenchmarkLog(b *testing.B) {
+	client, _ := newTestRepo(b, 1000)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := client.Log("", "HEAD"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLogWithPathRules(b *testing.B) {
+	client, _ := newTestRepo(b, 1000)
+	client.config.PathRules = []config.PathRuleConfig{{Paths: []string{"docs/**"}, Bump: config.BumpNone}}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := client.Log("", "HEAD"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestLogHashLength(t *testing.T) {
+	for _, length := range []int{7, 12, 40} {
+		client, _ := newTestRepo(t, 2)
+		client.config.Changelog.HashLength = length
+
+		commits, err := client.Log("", "HEAD")
+		if err != nil {
+			t.Fatalf("Log() error = %v", err)
+		}
+
+		for _, commit := range commits {
+			if len(commit.Hash) != length {
+				t.Errorf("hash_length %d: Hash = %q", length, commit.Hash)
+			}
+			if len(commit.FullHash) != 40 || !strings.HasPrefix(commit.FullHash, commit.Hash) {
+				t.Errorf("hash_length %d: FullHash = %q, Hash = %q", length, commit.FullHash, commit.Hash)
+			}
+		}
+	}
+}
+
+func TestShortHashLength(t *testing.T) {
+	for _, length := range []int{0, 7, 12, 40} {
+		client, _ := newTestRepo(t, 1)
+		client.config.Changelog.HashLength = length
+
+		hash, err := client.ShortHash("HEAD")
+		if err != nil {
+			t.Fatalf("ShortHash() error = %v", err)
+		}
+
+		want := length
+		if want == 0 {
+			want = 7
+		}
+		if len(hash) != want {
+			t.Errorf("hash_length %d: ShortHash() = %q", length, hash)
+		}
+	}
+}
+
+func TestNotes(t *testing.T) {
+	client, _ := newTestRepo(t, 3)
+
+	hash, err := client.SetNote("HEAD~1", "Type: Breaking\n")
+	if err != nil {
+		t.Fatalf("SetNote() error = %v", err)
+	}
+	if _, err := client.SetNote("HEAD", "Bump: none\n"); err != nil {
+		t.Fatalf("SetNote() error = %v", err)
+	}
+
+	commits, err := client.Log("", "HEAD")
+	if err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+	if commits[1].FullHash != hash || commits[1].Note != "Type: Breaking\n" {
+		t.Errorf("Log()[1] = %s with note %q, want %s with the Breaking note", commits[1].FullHash, commits[1].Note, hash)
+	}
+	if commits[2].Note != "" {
+		t.Errorf("Log()[2].Note = %q, want none", commits[2].Note)
+	}
+
+	if _, err := client.SetNote("HEAD~1", ""); err != nil {
+		t.Fatalf("SetNote() removing error = %v", err)
+	}
+	notes, err := client.Notes()
+	if err != nil {
+		t.Fatalf("Notes() error = %v", err)
+	}
+	if len(notes) != 1 || notes[commits[0].FullHash] != "Bump: none\n" {
+		t.Errorf("Notes() after removal = %v, want only the HEAD note", notes)
+	}
+
+	if _, err := client.SetNote("HEAD~1", ""); err == nil {
+		t.Error("SetNote() removing a missing note succeeded")
+	}
+}
+
+func TestTopoOrder(t *testing.T) {
+	hash := func(c string) plumbing.Hash {
+		return plumbing.NewHash(strings.Repeat(c, 40))
+	}
+	base, main, branch, merge := hash("b"), hash("c"), hash("a"), hash("d")
+
+	// The walk reaches base through main before it visits branch, a child of base
+	walked := []plumbing.Hash{merge, main, base, branch}
+	parents := map[plumbing.Hash][]plumbing.Hash{
+		merge:  {main, branch},
+		main:   {base},
+		branch: {base},
+	}
+
+	var commits []*CommitInfo
+	for _, h := range []plumbing.Hash{main, base, branch} {
+		commits = append(commits, &CommitInfo{FullHash: h.String()})
+	}
+
+	var got []string
+	for _, commit := range topoOrder(commits, walked, parents) {
+		got = append(got, commit.FullHash[:1])
+	}
+	if strings.Join(got, "") != "cab" {
+		t.Errorf("topoOrder() = %v, want [c a b]", got)
+	}
+}
+
+func TestReleaseDate(t *testing.T) {
+	client, _ := newTestRepo(t, 2)
+
+	date, err := client.ReleaseDate("HEAD")
+	if err != nil {
+		t.Fatalf("ReleaseDate() error = %v", err)
+	}
+	if !date.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("ReleaseDate(HEAD) = %v, want the commit date", date)
+	}
+
+	head, err := client.repo.Head()
+	if err != nil {
+		t.Fatalf("head: %v", err)
+	}
+	tagged := time.Unix(1700086400, 0)
+	_, err = client.repo.CreateTag("v1.1.0", head.Hash(), &git.CreateTagOptions{
+		Tagger:  &object.Signature{Name: "test", Email: "test@example.com", When: tagged},
+		Message: "Release 1.1.0",
+	})
+	if err != nil {
+		t.Fatalf("tag: %v", err)
+	}
+
+	if date, err := client.ReleaseDate("v1.1.0"); err != nil || !date.Equal(tagged) {
+		t.Errorf("ReleaseDate(v1.1.0) = %v, %v, want the tagger date", date, err)
+	}
+}
+
+func TestRemoteAuth(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "gh-token")
+	t.Setenv("GITLAB_TOKEN", "gl-token")
+	t.Setenv("GITHUB_SERVER_URL", "")
+	t.Setenv("CI_SERVER_HOST", "gitlab.ci.example.com")
+	t.Setenv("SSH_AUTH_SOCK", "")
+
+	cfg := config.DefaultConfig()
+	cfg.Git.GitHubHosts = []string{"GitHub.Example.com"}
+	cfg.Git.GitLabHosts = []string{"gitlab.example.com"}
+
+	tests := []struct {
+		url      string
+		username string
+		password string
+	}{
+		{"https://github.com/acme/widgets.git", "x-access-token", "gh-token"},
+		{"https://gitlab.com/acme/widgets.git", "oauth2", "gl-token"},
+		{"https://github.example.com/acme/widgets.git", "x-access-token", "gh-token"},
+		{"https://gitlab.example.com/acme/widgets.git", "oauth2", "gl-token"},
+		{"https://gitlab.ci.example.com/acme/widgets.git", "oauth2", "gl-token"},
+		// Tokens never reach other hosts, whatever their name
+		{"https://git.example.com/acme/widgets.git", "", ""},
+		{"https://gitlab.attacker.example/acme/widgets.git", "", ""},
+		{"https://github.com.attacker.example/acme/widgets.git", "", ""},
+		// Without an SSH agent go-git falls back to its defaults
+		{"git@github.com:acme/widgets.git", "", ""},
+		{"/srv/git/widgets.git", "", ""},
+	}
+
+	for _, tt := range tests {
+		auth := remoteAuth(tt.url, cfg)
+		if tt.username == "" {
+			if auth != nil {
+				t.Errorf("remoteAuth(%q) = %v, want nil", tt.url, auth)
+			}
+			continue
+		}
+
+		basic, ok := auth.(*http.BasicAuth)
+		if !ok || basic.Username != tt.username || basic.Password != tt.password {
+			t.Errorf("remoteAuth(%q) = %v, want %s:%s", tt.url, auth, tt.username, tt.password)
+		}
+	}
+}
+
+func TestSign(t *testing.T) {
+	client, _ := newTestRepo(t, 1)
+
+	entity, err := openpgp.NewEntity("Release Bot", "", "release@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := entity.EncryptPrivateKeys([]byte("secret"), nil); err != nil {
+		t.Fatal(err)
+	}
+	armored := func(blockType string, serialize func(io.Writer) error) string {
+		var out strings.Builder
+		w, err := armor.Encode(&out, blockType, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := serialize(w); err != nil {
+			t.Fatal(err)
+		}
+		w.Close()
+		return out.String()
+	}
+	public := armored(openpgp.PublicKeyType, entity.Serialize)
+	private := armored(openpgp.PrivateKeyType, func(w io.Writer) error {
+		return entity.SerializePrivateWithoutSigning(w, nil)
+	})
+	t.Setenv("COMMET_TEST_KEY", private)
+	t.Setenv("COMMET_TEST_PASSPHRASE", "secret")
+
+	client.config.Git.Sign = true
+	client.config.Git.SigningKeyEnv = "COMMET_TEST_KEY"
+	client.config.Git.SigningPassphraseEnv = "COMMET_TEST_PASSPHRASE"
+
+	worktree, err := client.repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := worktree.Filesystem.Create("VERSION")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Write([]byte("1.1.0\n"))
+	file.Close()
+
+	if err := client.CreateCommit([]string{"VERSION"}, "Conf: bump version to 1.1.0"); err != nil {
+		t.Fatalf("CreateCommit() error = %v", err)
+	}
+	if err := client.CreateTag("v1.1.0", "Release 1.1.0"); err != nil {
+		t.Fatalf("CreateTag() error = %v", err)
+	}
+
+	head, err := client.repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit, err := client.repo.CommitObject(head.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := commit.Verify(public); err != nil {
+		t.Errorf("the release commit is not signed: %v", err)
+	}
+
+	ref, err := client.repo.Tag("v1.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := client.repo.TagObject(ref.Hash())
+	if err != nil {
+		t.Fatalf("v1.1.0 is not an annotated tag: %v", err)
+	}
+	if _, err := tag.Verify(public); err != nil {
+		t.Errorf("the release tag is not signed: %v", err)
+	}
+	if tag.Target != head.Hash() || tag.Message != "Release 1.1.0\n" {
+		t.Errorf("tag = %s %q, want %s %q", tag.Target, tag.Message, head.Hash(), "Release 1.1.0\n")
+	}
+
+	t.Setenv("COMMET_TEST_PASSPHRASE", "wrong")
+	if err := client.CreateTag("v1.1.1", "Release 1.1.1"); err == nil {
+		t.Error("CreateTag() with a wrong passphrase succeeded")
+	}
+}
+
+func TestCreateTagType(t *testing.T) {
+	client, _ := newTestRepo(t, 1)
+	head, err := client.repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tagType := range []string{"annotated", "lightweight"} {
+		client.config.Git.TagType = tagType
+		if err := client.CreateTag(tagType, "Release"); err != nil {
+			t.Fatalf("CreateTag() %s error = %v", tagType, err)
+		}
+
+		ref, err := client.repo.Tag(tagType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = client.repo.TagObject(ref.Hash())
+		if annotated := err == nil; annotated != (tagType == "annotated") {
+			t.Errorf("%s tag has a tag object: %v", tagType, annotated)
+		}
+		if tagType == "lightweight" && ref.Hash() != head.Hash() {
+			t.Errorf("lightweight tag points at %s, want HEAD %s", ref.Hash(), head.Hash())
+		}
+	}
+
+	if err := client.CreateTag("lightweight", "Release"); err == nil {
+		t.Error("CreateTag() of an existing tag succeeded")
+	}
+}
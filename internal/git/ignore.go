@@ -0,0 +1,83 @@
+package git
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// hashLine matches ignore-file lines that are (abbreviated) commit hashes.
+var hashLine = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+
+// IgnoreList holds the commits excluded from analysis by the ignore file,
+// in the spirit of .git-blame-ignore-revs.
+type IgnoreList struct {
+	hashes   []string
+	patterns []*regexp.Regexp
+}
+
+// LoadIgnoreFile reads an ignore file. Each line is a commit hash (full or
+// abbreviated) or a regular expression matched against the commit message;
+// blank lines and lines starting with # are skipped. A missing file yields
+// an empty list.
+func LoadIgnoreFile(path string) (*IgnoreList, error) {
+	list := &IgnoreList{}
+	if path == "" {
+		return list, nil
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return list, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ignore file: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if hashLine.MatchString(line) {
+			list.hashes = append(list.hashes, line)
+			continue
+		}
+
+		pattern, err := regexp.Compile(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid pattern: %w", path, lineNo, err)
+		}
+		list.patterns = append(list.patterns, pattern)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read ignore file: %w", err)
+	}
+
+	return list, nil
+}
+
+// Ignores reports whether the commit with the given full hash and message is excluded.
+func (l *IgnoreList) Ignores(hash, message string) bool {
+	for _, h := range l.hashes {
+		if strings.HasPrefix(hash, h) {
+			return true
+		}
+	}
+
+	for _, pattern := range l.patterns {
+		if pattern.MatchString(message) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,186 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// NotesRef holds the notes written by "commet annotate". Share them with
+// "git push origin refs/notes/commet".
+const NotesRef = "refs/notes/commet"
+
+// Notes returns the commet notes keyed by full commit hash, or nil when there
+// are none. Fanned-out note trees (ab/cdef...) are read as well.
+func (c *Client) Notes() (map[string]string, error) {
+	ref, err := c.repo.Reference(plumbing.ReferenceName(NotesRef), true)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", NotesRef, err)
+	}
+
+	commit, err := c.repo.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", NotesRef, err)
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", NotesRef, err)
+	}
+
+	notes := make(map[string]string)
+	err = tree.Files().ForEach(func(file *object.File) error {
+		content, err := file.Contents()
+		if err != nil {
+			return err
+		}
+		notes[strings.ReplaceAll(file.Name, "/", "")] = content
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", NotesRef, err)
+	}
+
+	return notes, nil
+}
+
+// ResolveCommit returns the full hash of the commit rev points to.
+func (c *Client) ResolveCommit(rev string) (string, error) {
+	hash, err := c.repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", rev, err)
+	}
+	return hash.String(), nil
+}
+
+// SetNote attaches text to the commit rev points to, replacing its previous
+// note. An empty text removes the note. It returns the full commit hash.
+func (c *Client) SetNote(rev, text string) (string, error) {
+	hash, err := c.ResolveCommit(rev)
+	if err != nil {
+		return "", err
+	}
+
+	notes, err := c.Notes()
+	if err != nil {
+		return "", err
+	}
+	if notes == nil {
+		notes = make(map[string]string)
+	}
+
+	if text == "" {
+		if _, ok := notes[hash]; !ok {
+			return "", fmt.Errorf("commit %s has no commet note", rev)
+		}
+		delete(notes, hash)
+	} else {
+		notes[hash] = text
+	}
+
+	if err := c.writeNotes(notes); err != nil {
+		return "", err
+	}
+
+	return hash, nil
+}
+
+// writeNotes commits notes as a flat tree on top of NotesRef.
+func (c *Client) writeNotes(notes map[string]string) error {
+	tree := &object.Tree{}
+	for commitHash, text := range notes {
+		blobHash, err := c.storeBlob(text)
+		if err != nil {
+			return err
+		}
+		tree.Entries = append(tree.Entries, object.TreeEntry{Name: commitHash, Mode: filemode.Regular, Hash: blobHash})
+	}
+	sort.Slice(tree.Entries, func(i, j int) bool { return tree.Entries[i].Name < tree.Entries[j].Name })
+
+	treeObject := c.repo.Storer.NewEncodedObject()
+	if err := tree.Encode(treeObject); err != nil {
+		return fmt.Errorf("failed to encode notes tree: %w", err)
+	}
+	treeHash, err := c.repo.Storer.SetEncodedObject(treeObject)
+	if err != nil {
+		return fmt.Errorf("failed to write notes tree: %w", err)
+	}
+
+	signature := c.signature()
+	commit := &object.Commit{
+		Author:    signature,
+		Committer: signature,
+		Message:   "Notes added by 'commet annotate'\n",
+		TreeHash:  treeHash,
+	}
+	if ref, err := c.repo.Reference(plumbing.ReferenceName(NotesRef), true); err == nil {
+		commit.ParentHashes = []plumbing.Hash{ref.Hash()}
+	}
+
+	commitObject := c.repo.Storer.NewEncodedObject()
+	if err := commit.Encode(commitObject); err != nil {
+		return fmt.Errorf("failed to encode notes commit: %w", err)
+	}
+	commitHash, err := c.repo.Storer.SetEncodedObject(commitObject)
+	if err != nil {
+		return fmt.Errorf("failed to write notes commit: %w", err)
+	}
+
+	ref := plumbing.NewHashReference(plumbing.ReferenceName(NotesRef), commitHash)
+	if err := c.repo.Storer.SetReference(ref); err != nil {
+		return fmt.Errorf("failed to update %s: %w", NotesRef, err)
+	}
+
+	return nil
+}
+
+func (c *Client) storeBlob(content string) (plumbing.Hash, error) {
+	blob := c.repo.Storer.NewEncodedObject()
+	blob.SetType(plumbing.BlobObject)
+
+	writer, err := blob.Writer()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to write note: %w", err)
+	}
+	if _, err := io.WriteString(writer, content); err != nil {
+		writer.Close()
+		return plumbing.ZeroHash, fmt.Errorf("failed to write note: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to write note: %w", err)
+	}
+
+	hash, err := c.repo.Storer.SetEncodedObject(blob)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to write note: %w", err)
+	}
+	return hash, nil
+}
+
+// signature is the git user from the repository, global or system config.
+func (c *Client) signature() object.Signature {
+	signature := object.Signature{Name: "commet", Email: "commet@localhost", When: time.Now()}
+
+	cfg, err := c.repo.ConfigScoped(gitconfig.SystemScope)
+	if err != nil {
+		return signature
+	}
+	if cfg.User.Name != "" {
+		signature.Name = cfg.User.Name
+	}
+	if cfg.User.Email != "" {
+		signature.Email = cfg.User.Email
+	}
+	return signature
+}
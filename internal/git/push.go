@@ -0,0 +1,146 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// ErrRemoteAhead is returned by PushHead and PushHeadWithLease when the
+// remote branch has commits that HEAD lacks.
+var ErrRemoteAhead = errors.New("the remote branch moved ahead")
+
+// PushHead sends the commit checked out to branch of the remote, which must
+// fast-forward to it. It works with a detached HEAD, as in CI checkouts.
+func (c *Client) PushHead(remote, branch string) error {
+	head, err := c.repo.Head()
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	spec := gitconfig.RefSpec(head.Hash().String() + ":" + plumbing.NewBranchReferenceName(branch).String())
+	err = c.push(remote, []gitconfig.RefSpec{spec})
+	// go-git rejects non-fast-forward updates itself; servers say "fetch first"
+	if err != nil && (strings.Contains(err.Error(), "non-fast-forward") || strings.Contains(err.Error(), "fetch first")) {
+		return fmt.Errorf("failed to push %s to %s: %w", branch, remote, ErrRemoteAhead)
+	}
+	return err
+}
+
+// PushHeadWithLease is PushHead, refusing unless branch of the remote still
+// points at expected, the commit HEAD was rebased on.
+func (c *Client) PushHeadWithLease(remote, branch, expected string) error {
+	current, err := c.remoteBranch(remote, branch)
+	if err != nil {
+		return err
+	}
+	if current != expected {
+		return fmt.Errorf("failed to push %s to %s: %w again", branch, remote, ErrRemoteAhead)
+	}
+
+	return c.PushHead(remote, branch)
+}
+
+// PushHeadReplaying is PushHead for release commits. When the remote moved
+// ahead, it fetches the branch, replays the commits of HEAD on top of it
+// with RebaseHead and pushes again with a lease on the fetched commit, up to
+// attempts times. It returns how many commits were replayed on the commit
+// finally pushed, 0 when the first push went through.
+//
+// When the commits do not replay cleanly, ErrRebaseConflict is returned and
+// nothing is pushed; when the remote keeps moving, ErrRemoteAhead.
+func (c *Client) PushHeadReplaying(remote, branch string, attempts int) (int, error) {
+	replayed := 0
+	err := c.PushHead(remote, branch)
+	for attempt := 1; errors.Is(err, ErrRemoteAhead) && attempt <= attempts; attempt++ {
+		upstream, fetchErr := c.FetchBranch(remote, branch)
+		if fetchErr != nil {
+			return 0, fetchErr
+		}
+		n, rebaseErr := c.RebaseHead(upstream)
+		if rebaseErr != nil {
+			return 0, rebaseErr
+		}
+		replayed = n
+
+		err = c.PushHeadWithLease(remote, branch, upstream)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return replayed, nil
+}
+
+// FetchBranch fetches branch of the remote into refs/remotes/<remote>/ and
+// returns the commit it points to.
+func (c *Client) FetchBranch(remote, branch string) (string, error) {
+	url, err := c.RemoteURL(remote)
+	if err != nil {
+		return "", err
+	}
+
+	tracking := plumbing.NewRemoteReferenceName(remote, branch)
+	spec := gitconfig.RefSpec("+" + plumbing.NewBranchReferenceName(branch).String() + ":" + tracking.String())
+	err = c.repo.Fetch(&git.FetchOptions{
+		RemoteName: remote,
+		RefSpecs:   []gitconfig.RefSpec{spec},
+		Auth:       remoteAuth(url, c.config),
+		Tags:       git.NoTags,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return "", fmt.Errorf("failed to fetch %s from %s: %w", branch, remote, err)
+	}
+
+	ref, err := c.repo.Reference(tracking, true)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", tracking.Short(), err)
+	}
+	return ref.Hash().String(), nil
+}
+
+// remoteBranch returns the commit branch of the remote points at, as the
+// remote advertises it now.
+func (c *Client) remoteBranch(remote, branch string) (string, error) {
+	url, err := c.RemoteURL(remote)
+	if err != nil {
+		return "", err
+	}
+	r, err := c.repo.Remote(remote)
+	if err != nil {
+		return "", fmt.Errorf("failed to get remote %s: %w", remote, err)
+	}
+
+	refs, err := r.List(&git.ListOptions{Auth: remoteAuth(url, c.config)})
+	if err != nil {
+		return "", fmt.Errorf("failed to list refs of %s: %w", remote, err)
+	}
+	name := plumbing.NewBranchReferenceName(branch)
+	for _, ref := range refs {
+		if ref.Name() == name {
+			return ref.Hash().String(), nil
+		}
+	}
+	return "", nil
+}
+
+func (c *Client) push(remote string, specs []gitconfig.RefSpec) error {
+	url, err := c.RemoteURL(remote)
+	if err != nil {
+		return err
+	}
+
+	err = c.repo.Push(&git.PushOptions{
+		RemoteName: remote,
+		RefSpecs:   specs,
+		Auth:       remoteAuth(url, c.config),
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("failed to push to %s: %w", remote, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,215 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yendefrr/commet/internal/config"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+var pushAuthor = object.Signature{Name: "Test", Email: "test@example.com", When: time.Unix(1700000000, 0)}
+
+// pushFixture is a repository with a bare origin both have main pushed to.
+type pushFixture struct {
+	t         *testing.T
+	dir       string
+	repo      *git.Repository
+	remoteDir string
+	remote    *git.Repository
+}
+
+func newPushFixture(t *testing.T) *pushFixture {
+	t.Helper()
+
+	f := &pushFixture{t: t, dir: t.TempDir(), remoteDir: filepath.Join(t.TempDir(), "app.git")}
+	var err error
+	if f.remote, err = git.PlainInit(f.remoteDir, true); err != nil {
+		t.Fatal(err)
+	}
+	if f.repo, err = git.PlainInitWithOptions(f.dir, &git.PlainInitOptions{
+		InitOptions: git.InitOptions{DefaultBranch: plumbing.NewBranchReferenceName("main")},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.repo.CreateRemote(&gitconfig.RemoteConfig{Name: "origin", URLs: []string{f.remoteDir}}); err != nil {
+		t.Fatal(err)
+	}
+
+	commitFile(t, f.repo, f.dir, "package.json", `{"version": "1.2.3"}`, "Conf: initial")
+	if err := f.repo.Push(&git.PushOptions{RefSpecs: []gitconfig.RefSpec{"refs/heads/main:refs/heads/main"}}); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+// client opens the repository as commet does.
+func (f *pushFixture) client() *Client {
+	f.t.Helper()
+	client, err := NewClient(f.dir, &config.Config{})
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	return client
+}
+
+// pushElsewhere commits file from another clone and pushes it to main.
+func (f *pushFixture) pushElsewhere(file, content, message string) {
+	f.t.Helper()
+	dir := f.t.TempDir()
+	clone, err := git.PlainClone(dir, false, &git.CloneOptions{URL: f.remoteDir, ReferenceName: plumbing.NewBranchReferenceName("main")})
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	commitFile(f.t, clone, dir, file, content, message)
+	if err := clone.Push(&git.PushOptions{}); err != nil {
+		f.t.Fatal(err)
+	}
+}
+
+// remoteMain returns the messages of the last n commits of main on origin.
+func (f *pushFixture) remoteMain(n int) []string {
+	f.t.Helper()
+	ref, err := f.remote.Reference(plumbing.NewBranchReferenceName("main"), false)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	commit, err := f.remote.CommitObject(ref.Hash())
+	if err != nil {
+		f.t.Fatal(err)
+	}
+
+	var messages []string
+	for len(messages) < n {
+		messages = append(messages, commit.Message)
+		if commit.NumParents() == 0 {
+			break
+		}
+		if commit, err = commit.Parent(0); err != nil {
+			f.t.Fatal(err)
+		}
+	}
+	return messages
+}
+
+func commitFile(t *testing.T, repo *git.Repository, dir, file, content, message string) plumbing.Hash {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := worktree.Add(file); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := worktree.Commit(message, &git.CommitOptions{Author: &pushAuthor, Committer: &pushAuthor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
+
+func TestPushHeadReplaying(t *testing.T) {
+	f := newPushFixture(t)
+	f.pushElsewhere("README.md", "# app\n", "Docs: add readme")
+
+	release := commitFile(t, f.repo, f.dir, "package.json", `{"version": "1.3.0"}`, "Conf: bump version to 1.3.0")
+	if _, err := f.repo.CreateTag("v1.3.0", release, &git.CreateTagOptions{Tagger: &pushAuthor, Message: "Release 1.3.0"}); err != nil {
+		t.Fatal(err)
+	}
+
+	client := f.client()
+	if err := client.PushHead("origin", "main"); !errors.Is(err, ErrRemoteAhead) {
+		t.Fatalf("PushHead() = %v, want ErrRemoteAhead", err)
+	}
+
+	replayed, err := client.PushHeadReplaying("origin", "main", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed != 1 {
+		t.Errorf("PushHeadReplaying() replayed %d commits, want 1", replayed)
+	}
+
+	want := []string{"Conf: bump version to 1.3.0", "Docs: add readme", "Conf: initial"}
+	if got := f.remoteMain(3); len(got) != 3 || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("origin/main = %q, want %q", got, want)
+	}
+
+	head, err := f.repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tagRef, err := f.repo.Tag("v1.3.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := f.repo.TagObject(tagRef.Hash())
+	if err != nil {
+		t.Fatalf("v1.3.0 is no longer annotated: %v", err)
+	}
+	if tag.Target != head.Hash() || tag.Message != "Release 1.3.0\n" {
+		t.Errorf("v1.3.0 = %s %q, want the replayed commit %s", tag.Target, tag.Message, head.Hash())
+	}
+	if _, err := os.Stat(filepath.Join(f.dir, "README.md")); err != nil {
+		t.Errorf("the upstream readme is missing from the worktree: %v", err)
+	}
+}
+
+func TestPushHeadReplayingConflict(t *testing.T) {
+	f := newPushFixture(t)
+	f.pushElsewhere("package.json", `{"version": "1.2.4"}`, "Conf: bump version to 1.2.4")
+
+	release := commitFile(t, f.repo, f.dir, "package.json", `{"version": "1.3.0"}`, "Conf: bump version to 1.3.0")
+
+	_, err := f.client().PushHeadReplaying("origin", "main", 3)
+	if !errors.Is(err, ErrRebaseConflict) {
+		t.Fatalf("PushHeadReplaying() = %v, want ErrRebaseConflict", err)
+	}
+
+	head, err := f.repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.Hash() != release {
+		t.Errorf("HEAD = %s, want the release commit %s left as it was", head.Hash(), release)
+	}
+	if got := f.remoteMain(1); got[0] != "Conf: bump version to 1.2.4" {
+		t.Errorf("origin/main = %q, want the upstream commit only", got)
+	}
+}
+
+func TestPushHeadWithLease(t *testing.T) {
+	f := newPushFixture(t)
+	client := f.client()
+
+	upstream, err := client.FetchBranch("origin", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, f.repo, f.dir, "package.json", `{"version": "1.3.0"}`, "Conf: bump version to 1.3.0")
+	f.pushElsewhere("README.md", "# app\n", "Docs: add readme")
+
+	if err := client.PushHeadWithLease("origin", "main", upstream); !errors.Is(err, ErrRemoteAhead) {
+		t.Errorf("PushHeadWithLease() with a stale lease = %v, want ErrRemoteAhead", err)
+	}
+
+	if upstream, err = client.FetchBranch("origin", "main"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.RebaseHead(upstream); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.PushHeadWithLease("origin", "main", upstream); err != nil {
+		t.Errorf("PushHeadWithLease() with a fresh lease = %v", err)
+	}
+}
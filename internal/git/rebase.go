@@ -0,0 +1,262 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// ErrRebaseConflict is returned by RebaseHead when the commits cannot be
+// replayed cleanly, e.g. because a file they change also changed upstream.
+var ErrRebaseConflict = errors.New("the release commits do not apply cleanly")
+
+// RebaseHead replays the commits of HEAD that onto lacks on top of onto and
+// moves the tags pointing at them, e.g. the release tag, along. The replayed
+// commits keep their author and message and are signed when git.sign is set.
+// It returns how many commits it replayed.
+//
+// Only a clean rebase is attempted: the worktree must have no uncommitted
+// changes, the commits must be linear and none may change a file that also
+// changed upstream, or ErrRebaseConflict is returned. Nothing changes when
+// it fails.
+func (c *Client) RebaseHead(onto string) (int, error) {
+	head, err := c.repo.Head()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	headCommit, err := c.repo.CommitObject(head.Hash())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get HEAD commit: %w", err)
+	}
+	ontoCommit, err := c.repo.CommitObject(plumbing.NewHash(onto))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get commit %s: %w", onto, err)
+	}
+
+	bases, err := headCommit.MergeBase(ontoCommit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find the merge base with %s: %w", onto, err)
+	}
+	if len(bases) != 1 {
+		return 0, fmt.Errorf("HEAD and %s have no single merge base", onto)
+	}
+	base := bases[0]
+	if base.Hash == ontoCommit.Hash {
+		return 0, nil
+	}
+
+	// The commits to replay, newest first
+	var replay []*object.Commit
+	for commit := headCommit; commit.Hash != base.Hash; {
+		if commit.NumParents() != 1 {
+			return 0, fmt.Errorf("%s is a merge commit: %w", commit.Hash.String()[:7], ErrRebaseConflict)
+		}
+		replay = append(replay, commit)
+		if commit, err = commit.Parent(0); err != nil {
+			return 0, fmt.Errorf("failed to get parent of %s: %w", replay[len(replay)-1].Hash.String()[:7], err)
+		}
+	}
+
+	upstream, err := treeChanges(base, ontoCommit)
+	if err != nil {
+		return 0, err
+	}
+	for _, commit := range replay {
+		files, err := commitFiles(commit)
+		if err != nil {
+			return 0, err
+		}
+		for _, file := range files {
+			if upstream[file] {
+				return 0, fmt.Errorf("%s changed upstream too: %w", file, ErrRebaseConflict)
+			}
+		}
+	}
+
+	dirty, err := c.Dirty()
+	if err != nil {
+		return 0, err
+	}
+	if len(dirty) > 0 {
+		return 0, fmt.Errorf("uncommitted changes in %s: %w", strings.Join(dirty, ", "), ErrRebaseConflict)
+	}
+
+	worktree, err := c.repo.Worktree()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	if err := worktree.Reset(&git.ResetOptions{Commit: ontoCommit.Hash, Mode: git.HardReset}); err != nil {
+		return 0, fmt.Errorf("failed to reset to %s: %w", onto, err)
+	}
+
+	moved := make(map[plumbing.Hash]plumbing.Hash, len(replay))
+	for i := len(replay) - 1; i >= 0; i-- {
+		hash, err := c.replay(worktree, replay[i])
+		if err != nil {
+			// Back to the commits as they were
+			if resetErr := worktree.Reset(&git.ResetOptions{Commit: head.Hash(), Mode: git.HardReset}); resetErr != nil {
+				return 0, fmt.Errorf("%w; restoring HEAD failed too: %v", err, resetErr)
+			}
+			return 0, err
+		}
+		moved[replay[i].Hash] = hash
+	}
+
+	if err := c.retag(moved); err != nil {
+		return 0, err
+	}
+	return len(replay), nil
+}
+
+// replay commits the changes of commit to its first parent on top of the
+// worktree.
+func (c *Client) replay(worktree *git.Worktree, commit *object.Commit) (plumbing.Hash, error) {
+	hash := commit.Hash.String()[:7]
+
+	files, err := commitFiles(commit)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to get tree of %s: %w", hash, err)
+	}
+
+	for _, name := range files {
+		file, err := tree.File(name)
+		if errors.Is(err, object.ErrFileNotFound) {
+			if _, err := worktree.Remove(name); err != nil {
+				return plumbing.ZeroHash, fmt.Errorf("failed to remove %s: %w", name, err)
+			}
+			continue
+		}
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to read %s of %s: %w", name, hash, err)
+		}
+
+		contents, err := file.Contents()
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to read %s of %s: %w", name, hash, err)
+		}
+		mode, err := file.Mode.ToOSFileMode()
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to read the mode of %s: %w", name, err)
+		}
+		if err := writeWorktreeFile(worktree, name, contents, mode); err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		if _, err := worktree.Add(name); err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to add file %s: %w", name, err)
+		}
+	}
+
+	signer, err := c.signer()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	committer := c.signature()
+	replayed, err := worktree.Commit(commit.Message, &git.CommitOptions{
+		Author:    &commit.Author,
+		Committer: &committer,
+		Signer:    signer,
+		// Commits without changes stay so; overlapping ones were refused before
+		AllowEmptyCommits: true,
+	})
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to replay %s: %w", hash, err)
+	}
+	return replayed, nil
+}
+
+// retag points the tags of the commits in moved at their replacements.
+// Annotated tags are written anew with their message, signed when git.sign
+// is set.
+func (c *Client) retag(moved map[plumbing.Hash]plumbing.Hash) error {
+	iter, err := c.repo.Tags()
+	if err != nil {
+		return fmt.Errorf("failed to get tags: %w", err)
+	}
+	var refs []*plumbing.Reference
+	if err := iter.ForEach(func(ref *plumbing.Reference) error {
+		refs = append(refs, ref)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to iterate tags: %w", err)
+	}
+
+	signer, err := c.signer()
+	if err != nil {
+		return err
+	}
+
+	for _, ref := range refs {
+		target, opts := ref.Hash(), (*git.CreateTagOptions)(nil)
+		if tag, err := c.repo.TagObject(ref.Hash()); err == nil {
+			target, opts = tag.Target, &git.CreateTagOptions{Message: tag.Message}
+		}
+		replacement, ok := moved[target]
+		if !ok {
+			continue
+		}
+
+		tag := ref.Name().Short()
+		if err := c.repo.Storer.RemoveReference(ref.Name()); err != nil {
+			return fmt.Errorf("failed to move tag %s: %w", tag, err)
+		}
+		if opts != nil && signer != nil {
+			err = c.createSignedTag(tag, opts.Message, replacement, signer)
+		} else {
+			_, err = c.repo.CreateTag(tag, replacement, opts)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to move tag %s: %w", tag, err)
+		}
+	}
+
+	return nil
+}
+
+// treeChanges returns the paths that differ between the trees of from and to.
+func treeChanges(from, to *object.Commit) (map[string]bool, error) {
+	fromTree, err := from.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree of %s: %w", from.Hash.String()[:7], err)
+	}
+	toTree, err := to.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree of %s: %w", to.Hash.String()[:7], err)
+	}
+
+	changes, err := object.DiffTree(fromTree, toTree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff %s and %s: %w", from.Hash.String()[:7], to.Hash.String()[:7], err)
+	}
+
+	paths := make(map[string]bool, len(changes))
+	for _, change := range changes {
+		paths[change.From.Name] = true
+		paths[change.To.Name] = true
+	}
+	delete(paths, "")
+	return paths, nil
+}
+
+// writeWorktreeFile writes contents to name in the worktree, creating the
+// directories it needs.
+func writeWorktreeFile(worktree *git.Worktree, name, contents string, mode os.FileMode) error {
+	file, err := worktree.Filesystem.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write([]byte(contents)); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
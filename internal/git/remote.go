@@ -0,0 +1,233 @@
+package git
+
+import (
+	"fmt"
+	neturl "net/url"
+	"os"
+	"strings"
+
+	"github.com/yendefrr/commet/internal/config"
+	"github.com/yendefrr/commet/internal/version"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// initialRemoteDepth is the first clone depth tried by NewRemoteClient; it
+// grows fourfold until the base commit is part of the clone.
+const initialRemoteDepth = 64
+
+// RemoteRange is a commit range resolved against a remote repository.
+type RemoteRange struct {
+	// From is the base tag and FromHash the commit it points to
+	From     string
+	FromHash string
+	// To is the cloned ref, "HEAD" for the default branch
+	To string
+}
+
+// NewRemoteClient clones url into memory, without a worktree and only as
+// deep as needed to reach from, so the range can be analyzed without a local
+// checkout. An empty from selects the highest tag matching the tag pattern.
+// See remoteAuth for how the remote is authenticated.
+func NewRemoteClient(url, from, to string, cfg *config.Config) (*Client, *RemoteRange, error) {
+	auth := remoteAuth(url, cfg)
+
+	refs, err := git.NewRemote(memory.NewStorage(), &gitconfig.RemoteConfig{
+		Name: "origin",
+		URLs: []string{url},
+	}).List(&git.ListOptions{Auth: auth, PeelingOption: git.AppendPeeled})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list refs of %s: %w", url, err)
+	}
+
+	client := &Client{config: cfg}
+
+	remoteRange, err := client.resolveRemoteRange(refs, from, to)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Without a base tag the whole history is needed
+	depth := initialRemoteDepth
+	if remoteRange.FromHash == "" {
+		depth = 0
+	}
+
+	for ; ; depth *= 4 {
+		repo, err := git.Clone(memory.NewStorage(), nil, &git.CloneOptions{
+			URL:           url,
+			Auth:          auth,
+			ReferenceName: remoteRefName(refs, remoteRange.To),
+			SingleBranch:  true,
+			NoCheckout:    true,
+			Depth:         depth,
+			Tags:          git.NoTags,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to clone %s: %w", url, err)
+		}
+
+		client.repo = repo
+
+		if depth == 0 {
+			return client, remoteRange, nil
+		}
+
+		if _, err := repo.CommitObject(plumbing.NewHash(remoteRange.FromHash)); err == nil {
+			return client, remoteRange, nil
+		}
+
+		complete, err := historyComplete(repo)
+		if err != nil {
+			return nil, nil, err
+		}
+		if complete {
+			return nil, nil, fmt.Errorf("%s is not an ancestor of %s", remoteRange.From, remoteRange.To)
+		}
+	}
+}
+
+// resolveRemoteRange finds the commit of the from tag, or the highest version
+// tag when from is empty, in the advertised refs.
+func (c *Client) resolveRemoteRange(refs []*plumbing.Reference, from, to string) (*RemoteRange, error) {
+	tags := make(map[string]plumbing.Hash)
+	for _, ref := range refs {
+		name := ref.Name().String()
+		if !strings.HasPrefix(name, "refs/tags/") {
+			continue
+		}
+
+		tag := strings.TrimPrefix(name, "refs/tags/")
+		if peeled, ok := strings.CutSuffix(tag, "^{}"); ok {
+			tags[peeled] = ref.Hash() // annotated tag: the peeled entry names the commit
+			continue
+		}
+		if _, ok := tags[tag]; !ok {
+			tags[tag] = ref.Hash()
+		}
+	}
+
+	if from == "" {
+		best := ""
+		scheme := version.SchemeFor(c.config)
+		for tag := range tags {
+			v, err := c.ExtractVersionFromTag(tag)
+			if err != nil {
+				continue
+			}
+			if _, err := scheme.Parse(v); err != nil {
+				continue
+			}
+			if best == "" {
+				best = tag
+				continue
+			}
+			bestVersion, _ := c.ExtractVersionFromTag(best)
+			if result, err := scheme.Compare(v, bestVersion); err == nil && result > 0 {
+				best = tag
+			}
+		}
+
+		if best == "" {
+			return &RemoteRange{To: to}, nil
+		}
+		from = best
+	}
+
+	hash, ok := tags[from]
+	if !ok {
+		return nil, fmt.Errorf("tag %s not found on the remote", from)
+	}
+
+	return &RemoteRange{From: from, FromHash: hash.String(), To: to}, nil
+}
+
+// remoteRefName maps to, a branch or tag name or HEAD, to an advertised ref.
+func remoteRefName(refs []*plumbing.Reference, to string) plumbing.ReferenceName {
+	if to == "" || to == "HEAD" {
+		return plumbing.HEAD
+	}
+
+	for _, candidate := range []plumbing.ReferenceName{
+		plumbing.NewBranchReferenceName(to),
+		plumbing.NewTagReferenceName(to),
+		plumbing.ReferenceName(to),
+	} {
+		for _, ref := range refs {
+			if ref.Name() == candidate {
+				return candidate
+			}
+		}
+	}
+
+	return plumbing.NewBranchReferenceName(to)
+}
+
+// historyComplete reports whether a depth-limited clone holds the whole
+// history, i.e. the remote has no more commits to fetch.
+func historyComplete(repo *git.Repository) (bool, error) {
+	shallow, err := repo.Storer.Shallow()
+	if err != nil {
+		return false, fmt.Errorf("failed to read shallow commits: %w", err)
+	}
+	return len(shallow) == 0, nil
+}
+
+// remoteAuth authenticates to url. HTTPS remotes get GITHUB_TOKEN on GitHub
+// hosts and GITLAB_TOKEN on GitLab hosts (see tokenHosts) and nothing
+// elsewhere, so tokens never reach third-party servers. SSH remotes use the
+// keys of the SSH agent. Without them the connection is anonymous.
+func remoteAuth(url string, cfg *config.Config) transport.AuthMethod {
+	endpoint, err := transport.NewEndpoint(url)
+	if err != nil {
+		return nil
+	}
+
+	switch endpoint.Protocol {
+	case "https":
+		github, gitlab := tokenHosts(cfg)
+		host := strings.ToLower(endpoint.Host)
+		if token := os.Getenv("GITHUB_TOKEN"); token != "" && github[host] {
+			return &http.BasicAuth{Username: "x-access-token", Password: token}
+		}
+		if token := os.Getenv("GITLAB_TOKEN"); token != "" && gitlab[host] {
+			return &http.BasicAuth{Username: "oauth2", Password: token}
+		}
+	case "ssh":
+		if auth, err := ssh.NewSSHAgentAuth(endpoint.User); err == nil {
+			return auth
+		}
+	}
+	return nil
+}
+
+// tokenHosts returns the hosts trusted with GITHUB_TOKEN and GITLAB_TOKEN:
+// github.com and gitlab.com, the servers of the CI run (GITHUB_SERVER_URL,
+// CI_SERVER_HOST) and git.github_hosts and git.gitlab_hosts.
+func tokenHosts(cfg *config.Config) (github, gitlab map[string]bool) {
+	github = map[string]bool{"github.com": true}
+	gitlab = map[string]bool{"gitlab.com": true}
+
+	if server, err := neturl.Parse(os.Getenv("GITHUB_SERVER_URL")); err == nil && server.Hostname() != "" {
+		github[strings.ToLower(server.Hostname())] = true
+	}
+	if host := os.Getenv("CI_SERVER_HOST"); host != "" {
+		gitlab[strings.ToLower(host)] = true
+	}
+
+	if cfg != nil {
+		for _, host := range cfg.Git.GitHubHosts {
+			github[strings.ToLower(host)] = true
+		}
+		for _, host := range cfg.Git.GitLabHosts {
+			gitlab[strings.ToLower(host)] = true
+		}
+	}
+	return github, gitlab
+}
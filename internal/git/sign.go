@@ -0,0 +1,166 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// gpgSigner signs with the gpg program and a key of its keyring, as git does
+// for user.signingkey.
+type gpgSigner struct {
+	program string
+	keyID   string
+}
+
+func (s gpgSigner) Sign(message io.Reader) ([]byte, error) {
+	cmd := exec.Command(s.program, "--detach-sign", "--armor", "--local-user", s.keyID)
+	cmd.Stdin = message
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	signature, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign with %s key %s: %w: %s", s.program, s.keyID, err, strings.TrimSpace(stderr.String()))
+	}
+	return signature, nil
+}
+
+// entitySigner signs with a private key held in memory, e.g. read from a CI
+// secret.
+type entitySigner struct {
+	entity *openpgp.Entity
+}
+
+func (s entitySigner) Sign(message io.Reader) ([]byte, error) {
+	var signature bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&signature, s.entity, message, nil); err != nil {
+		return nil, fmt.Errorf("failed to sign: %w", err)
+	}
+	return signature.Bytes(), nil
+}
+
+// signer returns what signs the release commits and tags when git.sign is
+// set, nil otherwise. An armored private key in git.signing_key_env is used
+// directly; otherwise gpg signs with git.signing_key or user.signingkey.
+func (c *Client) signer() (git.Signer, error) {
+	if !c.config.Git.Sign {
+		return nil, nil
+	}
+
+	keyID := c.config.Git.SigningKey
+	program := "gpg"
+	if cfg, err := c.repo.ConfigScoped(gitconfig.SystemScope); err == nil {
+		if keyID == "" {
+			keyID = cfg.Raw.Section("user").Option("signingkey")
+		}
+		if gpg := cfg.Raw.Section("gpg").Option("program"); gpg != "" {
+			program = gpg
+		}
+	}
+
+	if name := c.config.Git.SigningKeyEnv; name != "" {
+		if armored := os.Getenv(name); armored != "" {
+			entity, err := readSigningKey(armored, keyID, os.Getenv(c.config.Git.SigningPassphraseEnv))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read the signing key in %s: %w", name, err)
+			}
+			return entitySigner{entity}, nil
+		}
+	}
+
+	if keyID == "" {
+		return nil, fmt.Errorf("git.sign needs git.signing_key, user.signingkey in the git config or a key in git.signing_key_env")
+	}
+	return gpgSigner{program: program, keyID: keyID}, nil
+}
+
+// readSigningKey reads the private key keyID, or the first private key when
+// keyID is empty, from an armored keyring and unlocks it with passphrase.
+func readSigningKey(armored, keyID, passphrase string) (*openpgp.Entity, error) {
+	entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(armored))
+	if err != nil {
+		return nil, err
+	}
+
+	keyID = strings.ToUpper(strings.TrimPrefix(keyID, "0x"))
+	for _, entity := range entities {
+		if entity.PrivateKey == nil || keyID != "" && !hasKeyID(entity, keyID) {
+			continue
+		}
+
+		if err := entity.DecryptPrivateKeys([]byte(passphrase)); err != nil {
+			return nil, fmt.Errorf("failed to unlock the key: %w", err)
+		}
+		return entity, nil
+	}
+
+	if keyID != "" {
+		return nil, fmt.Errorf("no private key %s", keyID)
+	}
+	return nil, fmt.Errorf("no private key")
+}
+
+// hasKeyID reports whether the fingerprint of the primary key or a subkey of
+// entity ends with keyID, a long or short key ID or a fingerprint.
+func hasKeyID(entity *openpgp.Entity, keyID string) bool {
+	if strings.HasSuffix(fmt.Sprintf("%X", entity.PrimaryKey.Fingerprint), keyID) {
+		return true
+	}
+	for _, subkey := range entity.Subkeys {
+		if strings.HasSuffix(fmt.Sprintf("%X", subkey.PublicKey.Fingerprint), keyID) {
+			return true
+		}
+	}
+	return false
+}
+
+// createSignedTag writes an annotated tag of target signed by signer; go-git
+// itself only signs tags with in-memory keys.
+func (c *Client) createSignedTag(tag, message string, target plumbing.Hash, signer git.Signer) error {
+	if _, err := c.repo.Tag(tag); err == nil {
+		return git.ErrTagExists
+	}
+
+	tagObject := &object.Tag{
+		Name:       tag,
+		Tagger:     c.signature(),
+		Message:    strings.TrimSpace(message) + "\n",
+		TargetType: plumbing.CommitObject,
+		Target:     target,
+	}
+
+	unsigned := &plumbing.MemoryObject{}
+	if err := tagObject.EncodeWithoutSignature(unsigned); err != nil {
+		return err
+	}
+	reader, err := unsigned.Reader()
+	if err != nil {
+		return err
+	}
+	signature, err := signer.Sign(reader)
+	if err != nil {
+		return err
+	}
+	tagObject.PGPSignature = string(signature)
+
+	encoded := c.repo.Storer.NewEncodedObject()
+	if err := tagObject.Encode(encoded); err != nil {
+		return err
+	}
+	hash, err := c.repo.Storer.SetEncodedObject(encoded)
+	if err != nil {
+		return err
+	}
+
+	return c.repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewTagReferenceName(tag), hash))
+}
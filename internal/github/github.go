@@ -0,0 +1,408 @@
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const defaultAPIURL = "https://api.github.com"
+
+type Client struct {
+	apiURL string
+	token  string
+	http   *http.Client
+}
+
+// NewClient creates a GitHub API client. The API URL and token are read from
+// GITHUB_API_URL and GITHUB_TOKEN when set.
+func NewClient() *Client {
+	apiURL := os.Getenv("GITHUB_API_URL")
+	if apiURL == "" {
+		apiURL = defaultAPIURL
+	}
+
+	return &Client{
+		apiURL: strings.TrimSuffix(apiURL, "/"),
+		token:  os.Getenv("GITHUB_TOKEN"),
+		http:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type Comparison struct {
+	Owner string
+	Repo  string
+	Base  string
+	Head  string
+}
+
+// ParseCompareURL parses URLs like https://github.com/org/repo/compare/v1.2.0...main.
+func ParseCompareURL(rawURL string) (*Comparison, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid compare URL: %w", err)
+	}
+
+	parts := strings.SplitN(strings.Trim(u.Path, "/"), "/", 4)
+	if len(parts) != 4 || parts[2] != "compare" {
+		return nil, fmt.Errorf("invalid compare URL %s: expected /<owner>/<repo>/compare/<base>...<head>", rawURL)
+	}
+
+	base, head, ok := strings.Cut(parts[3], "...")
+	if !ok {
+		base, head, ok = strings.Cut(parts[3], "..")
+	}
+	if !ok || base == "" || head == "" {
+		return nil, fmt.Errorf("invalid compare range %s: expected <base>...<head>", parts[3])
+	}
+
+	return &Comparison{
+		Owner: parts[0],
+		Repo:  parts[1],
+		Base:  base,
+		Head:  head,
+	}, nil
+}
+
+// ParseRepoURL extracts owner and repository from a GitHub remote URL in
+// HTTPS (https://github.com/org/repo.git) or SSH (git@github.com:org/repo.git) form.
+func ParseRepoURL(remote string) (string, string, error) {
+	path := remote
+	if u, err := url.Parse(remote); err == nil && u.Host != "" {
+		path = u.Path
+	} else if _, after, ok := strings.Cut(remote, ":"); ok {
+		path = after
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimSuffix(path, ".git"), "/"), "/")
+	if len(parts) < 2 || parts[len(parts)-2] == "" || parts[len(parts)-1] == "" {
+		return "", "", fmt.Errorf("cannot parse repository from remote %s", remote)
+	}
+
+	return parts[len(parts)-2], parts[len(parts)-1], nil
+}
+
+type Commit struct {
+	SHA     string
+	Message string
+	Author  string
+	Date    time.Time
+	Parents int
+}
+
+type compareResponse struct {
+	TotalCommits int `json:"total_commits"`
+	Commits      []struct {
+		SHA    string `json:"sha"`
+		Commit struct {
+			Message string `json:"message"`
+			Author  struct {
+				Name string    `json:"name"`
+				Date time.Time `json:"date"`
+			} `json:"author"`
+		} `json:"commit"`
+		Parents []struct {
+			SHA string `json:"sha"`
+		} `json:"parents"`
+	} `json:"commits"`
+}
+
+// Compare returns the commits between base and head, newest first, to match git log order.
+func (c *Client) Compare(cmp *Comparison) ([]*Commit, error) {
+	var commits []*Commit
+
+	for page := 1; ; page++ {
+		endpoint := fmt.Sprintf("%s/repos/%s/%s/compare/%s...%s?per_page=100&page=%d",
+			c.apiURL, cmp.Owner, cmp.Repo, url.PathEscape(cmp.Base), url.PathEscape(cmp.Head), page)
+
+		var resp compareResponse
+		if err := c.get(endpoint, &resp); err != nil {
+			return nil, err
+		}
+
+		for _, item := range resp.Commits {
+			commits = append(commits, &Commit{
+				SHA:     item.SHA,
+				Message: item.Commit.Message,
+				Author:  item.Commit.Author.Name,
+				Date:    item.Commit.Author.Date,
+				Parents: len(item.Parents),
+			})
+		}
+
+		if len(resp.Commits) == 0 || len(commits) >= resp.TotalCommits {
+			break
+		}
+	}
+
+	// The API lists oldest first
+	for i, j := 0, len(commits)-1; i < j; i, j = i+1, j-1 {
+		commits[i], commits[j] = commits[j], commits[i]
+	}
+
+	return commits, nil
+}
+
+type Repository struct {
+	Name          string `json:"name"`
+	DefaultBranch string `json:"default_branch"`
+	Archived      bool   `json:"archived"`
+}
+
+// ListOrgRepos returns every repository of org visible to the token.
+func (c *Client) ListOrgRepos(org string) ([]*Repository, error) {
+	var repos []*Repository
+
+	for page := 1; ; page++ {
+		endpoint := fmt.Sprintf("%s/orgs/%s/repos?per_page=100&page=%d", c.apiURL, url.PathEscape(org), page)
+
+		var batch []*Repository
+		if err := c.get(endpoint, &batch); err != nil {
+			return nil, err
+		}
+
+		repos = append(repos, batch...)
+		if len(batch) < 100 {
+			break
+		}
+	}
+
+	return repos, nil
+}
+
+// ListTags returns the names of every tag in the repository.
+func (c *Client) ListTags(owner, repo string) ([]string, error) {
+	var tags []string
+
+	for page := 1; ; page++ {
+		endpoint := fmt.Sprintf("%s/repos/%s/%s/tags?per_page=100&page=%d", c.apiURL, owner, repo, page)
+
+		var batch []struct {
+			Name string `json:"name"`
+		}
+		if err := c.get(endpoint, &batch); err != nil {
+			return nil, err
+		}
+
+		for _, tag := range batch {
+			tags = append(tags, tag.Name)
+		}
+		if len(batch) < 100 {
+			break
+		}
+	}
+
+	return tags, nil
+}
+
+type Release struct {
+	ID         int64  `json:"id"`
+	TagName    string `json:"tag_name"`
+	Name       string `json:"name"`
+	Body       string `json:"body"`
+	Draft      bool   `json:"draft"`
+	Prerelease bool   `json:"prerelease"`
+	UploadURL  string `json:"upload_url"`
+}
+
+// GetLatestRelease returns the most recent published, non-prerelease release.
+func (c *Client) GetLatestRelease(owner, repo string) (*Release, error) {
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/releases/latest", c.apiURL, owner, repo)
+
+	var release Release
+	if err := c.get(endpoint, &release); err != nil {
+		return nil, err
+	}
+
+	return &release, nil
+}
+
+// GetReleaseByTag returns the release published for tag.
+func (c *Client) GetReleaseByTag(owner, repo, tag string) (*Release, error) {
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/releases/tags/%s", c.apiURL, owner, repo, url.PathEscape(tag))
+
+	var release Release
+	if err := c.get(endpoint, &release); err != nil {
+		return nil, err
+	}
+
+	return &release, nil
+}
+
+// UpdateRelease saves the name, body and flags of release.
+func (c *Client) UpdateRelease(owner, repo string, release *Release) error {
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/releases/%d", c.apiURL, owner, repo, release.ID)
+
+	payload := map[string]interface{}{
+		"name":       release.Name,
+		"body":       release.Body,
+		"draft":      release.Draft,
+		"prerelease": release.Prerelease,
+	}
+
+	return c.send(http.MethodPatch, endpoint, payload, nil)
+}
+
+type PullRequest struct {
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	HTMLURL string `json:"html_url"`
+}
+
+// FindPullRequest returns the open pull request from branch, or nil when
+// there is none.
+func (c *Client) FindPullRequest(owner, repo, branch string) (*PullRequest, error) {
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/pulls?state=open&head=%s", c.apiURL, owner, repo, url.QueryEscape(owner+":"+branch))
+
+	var pulls []*PullRequest
+	if err := c.get(endpoint, &pulls); err != nil {
+		return nil, err
+	}
+	if len(pulls) == 0 {
+		return nil, nil
+	}
+
+	return pulls[0], nil
+}
+
+// CreatePullRequest opens a pull request merging head into base.
+func (c *Client) CreatePullRequest(owner, repo, head, base, title, body string) (*PullRequest, error) {
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/pulls", c.apiURL, owner, repo)
+
+	payload := map[string]interface{}{
+		"head":  head,
+		"base":  base,
+		"title": title,
+		"body":  body,
+	}
+
+	var pull PullRequest
+	if err := c.send(http.MethodPost, endpoint, payload, &pull); err != nil {
+		return nil, err
+	}
+
+	return &pull, nil
+}
+
+// UpdatePullRequest saves the title and body of pull.
+func (c *Client) UpdatePullRequest(owner, repo string, pull *PullRequest) error {
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/pulls/%d", c.apiURL, owner, repo, pull.Number)
+
+	payload := map[string]interface{}{
+		"title": pull.Title,
+		"body":  pull.Body,
+	}
+
+	return c.send(http.MethodPatch, endpoint, payload, nil)
+}
+
+// uploadTimeout bounds a single release asset upload.
+const uploadTimeout = 10 * time.Minute
+
+// UploadAsset attaches the file at path to release under its base name.
+func (c *Client) UploadAsset(release *Release, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open asset: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat asset: %w", err)
+	}
+
+	// upload_url is a URI template: .../assets{?name,label}
+	base, _, _ := strings.Cut(release.UploadURL, "{")
+	if base == "" {
+		return fmt.Errorf("release %s has no upload URL", release.TagName)
+	}
+	endpoint := base + "?name=" + url.QueryEscape(filepath.Base(path))
+
+	req, err := http.NewRequest(http.MethodPost, endpoint, file)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.ContentLength = info.Size()
+
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	client := &http.Client{Timeout: uploadTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("GitHub upload failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("GitHub API returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	return nil
+}
+
+func (c *Client) get(endpoint string, v interface{}) error {
+	return c.send(http.MethodGet, endpoint, nil, v)
+}
+
+// send performs an API request, encoding payload as JSON when set and
+// decoding the response into v when set.
+func (c *Client) send(method, endpoint string, payload, v interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, endpoint, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("GitHub request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("GitHub API returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	if v == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode GitHub response: %w", err)
+	}
+
+	return nil
+}
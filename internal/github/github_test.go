@@ -0,0 +1,131 @@
+package github
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseRepoURL(t *testing.T) {
+	tests := []struct {
+		remote, owner, repo string
+	}{
+		{"https://github.com/org/app.git", "org", "app"},
+		{"https://github.com/org/app", "org", "app"},
+		{"git@github.com:org/app.git", "org", "app"},
+		{"ssh://git@github.example.com/org/app.git", "org", "app"},
+	}
+	for _, tt := range tests {
+		owner, repo, err := ParseRepoURL(tt.remote)
+		if err != nil || owner != tt.owner || repo != tt.repo {
+			t.Errorf("ParseRepoURL(%q) = %q, %q, %v; want %q, %q", tt.remote, owner, repo, err, tt.owner, tt.repo)
+		}
+	}
+
+	if _, _, err := ParseRepoURL("https://github.com/app"); err == nil {
+		t.Error("ParseRepoURL() of a URL without owner succeeded")
+	}
+}
+
+func TestUpdateRelease(t *testing.T) {
+	var method, path, auth string
+	var payload map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path, auth = r.Method, r.URL.Path, r.Header.Get("Authorization")
+		switch r.URL.Path {
+		case "/repos/org/app/releases/tags/v1.3.0":
+			io.WriteString(w, `{"id": 42, "tag_name": "v1.3.0", "name": "1.3.0", "body": "notes", "draft": true, "upload_url": "https://uploads.example.com/assets{?name,label}"}`)
+		case "/repos/org/app/releases/42":
+			json.NewDecoder(r.Body).Decode(&payload)
+			io.WriteString(w, `{}`)
+		default:
+			http.Error(w, `{"message": "Not Found"}`, http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("GITHUB_API_URL", server.URL)
+	t.Setenv("GITHUB_TOKEN", "gh-token")
+	client := NewClient()
+
+	release, err := client.GetReleaseByTag("org", "app", "v1.3.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Release{ID: 42, TagName: "v1.3.0", Name: "1.3.0", Body: "notes", Draft: true, UploadURL: "https://uploads.example.com/assets{?name,label}"}
+	if *release != want {
+		t.Fatalf("GetReleaseByTag() = %+v, want %+v", release, want)
+	}
+
+	release.Body, release.Draft, release.Prerelease = "new notes", false, true
+	if err := client.UpdateRelease("org", "app", release); err != nil {
+		t.Fatal(err)
+	}
+	if method != http.MethodPatch || path != "/repos/org/app/releases/42" {
+		t.Errorf("UpdateRelease() sent %s %s, want PATCH /repos/org/app/releases/42", method, path)
+	}
+	if auth != "Bearer gh-token" {
+		t.Errorf("Authorization = %q, want the token", auth)
+	}
+	wantPayload := map[string]interface{}{"name": "1.3.0", "body": "new notes", "draft": false, "prerelease": true}
+	if len(payload) != len(wantPayload) {
+		t.Errorf("UpdateRelease() sent %v, want %v", payload, wantPayload)
+	}
+	for key, value := range wantPayload {
+		if payload[key] != value {
+			t.Errorf("UpdateRelease() sent %s = %v, want %v", key, payload[key], value)
+		}
+	}
+
+	if _, err := client.GetReleaseByTag("org", "app", "v9.9.9"); err == nil {
+		t.Error("GetReleaseByTag() of a missing release succeeded")
+	}
+}
+
+func TestUploadAsset(t *testing.T) {
+	var query, contentType string
+	var contentLength int64
+	var uploaded []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/org/app/releases/42/assets" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		query, contentType, contentLength = r.URL.RawQuery, r.Header.Get("Content-Type"), r.ContentLength
+		uploaded, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	asset := filepath.Join(t.TempDir(), "app 1.3.0.tar.gz")
+	if err := os.WriteFile(asset, []byte("archive"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	client := NewClient()
+	release := &Release{TagName: "v1.3.0", UploadURL: server.URL + "/repos/org/app/releases/42/assets{?name,label}"}
+	if err := client.UploadAsset(release, asset); err != nil {
+		t.Fatal(err)
+	}
+	if query != "name=app+1.3.0.tar.gz" {
+		t.Errorf("UploadAsset() query = %q, want name=app+1.3.0.tar.gz", query)
+	}
+	if contentType != "application/octet-stream" || contentLength != 7 {
+		t.Errorf("UploadAsset() sent %s of %d bytes, want application/octet-stream of 7", contentType, contentLength)
+	}
+	if string(uploaded) != "archive" {
+		t.Errorf("UploadAsset() uploaded %q, want %q", uploaded, "archive")
+	}
+
+	release.UploadURL = server.URL + "/elsewhere{?name,label}"
+	if err := client.UploadAsset(release, asset); err == nil {
+		t.Error("UploadAsset() to a failing server succeeded")
+	}
+	release.UploadURL = ""
+	if err := client.UploadAsset(release, asset); err == nil {
+		t.Error("UploadAsset() without an upload URL succeeded")
+	}
+}
@@ -0,0 +1,202 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/smtp"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/yendefrr/commet/internal/changelog"
+	"github.com/yendefrr/commet/internal/config"
+	"github.com/yendefrr/commet/internal/parser"
+)
+
+// Default templates per channel: Slack gets a short highlight, email and
+// webhooks carry the full notes.
+var defaultTemplates = map[string]string{
+	config.ChannelSlack:   "*{{.Project}} {{.Version}}* released{{range .Groups}}\n• {{len .Commits}} {{.Description}}{{end}}",
+	config.ChannelEmail:   "{{.Notes}}",
+	config.ChannelWebhook: `{"version": {{json .Version}}, "tag": {{json .Tag}}, "notes": {{json .Notes}}}`,
+}
+
+const defaultSubject = "{{.Project}} {{.Version}} released"
+
+// Release describes a finished release for notification templates.
+type Release struct {
+	Project string
+	Version string
+	Tag     string
+	Commits []*parser.Commit
+}
+
+// Data is what a channel template is rendered with. Notes is the changelog
+// entry and Groups the grouped commits, both limited to the channel's include_types.
+type Data struct {
+	Project string
+	Version string
+	Tag     string
+	Notes   string
+	Groups  []*changelog.CommitGroup
+	Commits []*parser.Commit
+}
+
+// Notifier sends release notifications to the configured channels.
+type Notifier struct {
+	changelog config.ChangelogConfig
+	http      *http.Client
+}
+
+func NewNotifier(changelogCfg config.ChangelogConfig) *Notifier {
+	return &Notifier{
+		changelog: changelogCfg,
+		http:      &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Send renders the channel's template for release and delivers it.
+func (n *Notifier) Send(channel config.NotificationConfig, release *Release) error {
+	data, err := n.data(channel, release)
+	if err != nil {
+		return err
+	}
+
+	text := channel.Template
+	if text == "" {
+		text = defaultTemplates[channel.Channel]
+	}
+
+	body, err := render(channel.Channel, text, data)
+	if err != nil {
+		return err
+	}
+
+	switch channel.Channel {
+	case config.ChannelSlack:
+		payload, err := json.Marshal(map[string]string{"text": body})
+		if err != nil {
+			return fmt.Errorf("failed to encode slack message: %w", err)
+		}
+		return n.post(os.ExpandEnv(channel.URL), "application/json", payload)
+
+	case config.ChannelWebhook:
+		contentType := channel.ContentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		return n.post(os.ExpandEnv(channel.URL), contentType, []byte(body))
+
+	case config.ChannelEmail:
+		subjectText := channel.Subject
+		if subjectText == "" {
+			subjectText = defaultSubject
+		}
+		subject, err := render("subject", subjectText, data)
+		if err != nil {
+			return err
+		}
+		return sendMail(channel, subject, body)
+	}
+
+	return fmt.Errorf("unknown notification channel %s", channel.Channel)
+}
+
+// data collects the template data for release, filtered by the channel's types.
+func (n *Notifier) data(channel config.NotificationConfig, release *Release) (*Data, error) {
+	cfg := n.changelog
+	cfg.IncludeTypes = channel.IncludeTypes
+	generator := changelog.NewGenerator("", cfg)
+
+	notes, err := generator.Render(release.Version, release.Commits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render notes: %w", err)
+	}
+
+	groups := generator.Groups(release.Commits)
+
+	var commits []*parser.Commit
+	for _, group := range groups {
+		commits = append(commits, group.Commits...)
+	}
+
+	return &Data{
+		Project: release.Project,
+		Version: release.Version,
+		Tag:     release.Tag,
+		Notes:   notes,
+		Groups:  groups,
+		Commits: commits,
+	}, nil
+}
+
+func render(name, text string, data *Data) (string, error) {
+	tmpl, err := template.New(name).Funcs(template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			encoded, err := json.Marshal(v)
+			return string(encoded), err
+		},
+	}).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template: %w", name, err)
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to render %s template: %w", name, err)
+	}
+
+	return sb.String(), nil
+}
+
+func (n *Notifier) post(url, contentType string, body []byte) error {
+	if url == "" {
+		return fmt.Errorf("url is empty")
+	}
+
+	resp, err := n.http.Post(url, contentType, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("server returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	return nil
+}
+
+func sendMail(channel config.NotificationConfig, subject, body string) error {
+	if channel.SMTPHost == "" || channel.From == "" || len(channel.To) == 0 {
+		return fmt.Errorf("email needs smtp_host, from and to")
+	}
+
+	var auth smtp.Auth
+	if user := os.ExpandEnv(channel.SMTPUser); user != "" {
+		host := channel.SMTPHost
+		if i := strings.LastIndex(host, ":"); i >= 0 {
+			host = host[:i]
+		}
+		auth = smtp.PlainAuth("", user, os.ExpandEnv(channel.SMTPPassword), host)
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", channel.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(channel.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", strings.ReplaceAll(subject, "\n", " "))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	if err := smtp.SendMail(channel.SMTPHost, auth, channel.From, channel.To, []byte(msg.String())); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,160 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/yendefrr/commet/internal/config"
+	"github.com/yendefrr/commet/internal/parser"
+)
+
+// request is what the test server received.
+type request struct {
+	contentType string
+	body        string
+}
+
+// newServer answers every request with status and records it in requests.
+func newServer(t *testing.T, status int, requests *[]request) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		*requests = append(*requests, request{contentType: r.Header.Get("Content-Type"), body: string(body)})
+		w.WriteHeader(status)
+		io.WriteString(w, "boom\n")
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func testRelease() *Release {
+	return &Release{
+		Project: "app",
+		Version: "1.3.0",
+		Tag:     "v1.3.0",
+		Commits: parser.ParseMultiple([]string{
+			"Feature: add export",
+			"Fix: handle empty input",
+			"Docs: explain export",
+		}),
+	}
+}
+
+func TestSendWebhook(t *testing.T) {
+	var requests []request
+	server := newServer(t, http.StatusNoContent, &requests)
+	t.Setenv("COMMET_TEST_WEBHOOK", server.URL)
+
+	notifier := NewNotifier(config.DefaultConfig().Changelog)
+	channel := config.NotificationConfig{Channel: config.ChannelWebhook, URL: "$COMMET_TEST_WEBHOOK"}
+	if err := notifier.Send(channel, testRelease()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(requests))
+	}
+	if requests[0].contentType != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", requests[0].contentType)
+	}
+
+	var payload struct {
+		Version string `json:"version"`
+		Tag     string `json:"tag"`
+		Notes   string `json:"notes"`
+	}
+	if err := json.Unmarshal([]byte(requests[0].body), &payload); err != nil {
+		t.Fatalf("the default webhook body is not JSON: %v\n%s", err, requests[0].body)
+	}
+	if payload.Version != "1.3.0" || payload.Tag != "v1.3.0" {
+		t.Errorf("version, tag = %q, %q; want 1.3.0, v1.3.0", payload.Version, payload.Tag)
+	}
+	if !strings.Contains(payload.Notes, "add export") || !strings.Contains(payload.Notes, "handle empty input") {
+		t.Errorf("notes lack the commits:\n%s", payload.Notes)
+	}
+}
+
+func TestSendIncludeTypes(t *testing.T) {
+	var requests []request
+	server := newServer(t, http.StatusOK, &requests)
+
+	notifier := NewNotifier(config.DefaultConfig().Changelog)
+	channel := config.NotificationConfig{
+		Channel:      config.ChannelWebhook,
+		URL:          server.URL,
+		ContentType:  "text/plain",
+		Template:     "{{.Project}} {{.Version}}:{{range .Commits}} {{.Description}};{{end}}",
+		IncludeTypes: []string{"Feature"},
+	}
+	if err := notifier.Send(channel, testRelease()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(requests))
+	}
+	if requests[0].contentType != "text/plain" {
+		t.Errorf("Content-Type = %q, want text/plain", requests[0].contentType)
+	}
+	if want := "app 1.3.0: add export;"; requests[0].body != want {
+		t.Errorf("body = %q, want %q", requests[0].body, want)
+	}
+}
+
+func TestSendSlack(t *testing.T) {
+	var requests []request
+	server := newServer(t, http.StatusOK, &requests)
+
+	notifier := NewNotifier(config.DefaultConfig().Changelog)
+	channel := config.NotificationConfig{Channel: config.ChannelSlack, URL: server.URL, Template: "*{{.Project}}* {{.Tag}}"}
+	if err := notifier.Send(channel, testRelease()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(requests))
+	}
+	var payload map[string]string
+	if err := json.Unmarshal([]byte(requests[0].body), &payload); err != nil {
+		t.Fatalf("the slack body is not JSON: %v\n%s", err, requests[0].body)
+	}
+	if payload["text"] != "*app* v1.3.0" {
+		t.Errorf("text = %q, want %q", payload["text"], "*app* v1.3.0")
+	}
+}
+
+func TestSendErrors(t *testing.T) {
+	var requests []request
+	server := newServer(t, http.StatusInternalServerError, &requests)
+	notifier := NewNotifier(config.DefaultConfig().Changelog)
+
+	tests := []struct {
+		name    string
+		channel config.NotificationConfig
+		want    string
+	}{
+		{"server error", config.NotificationConfig{Channel: config.ChannelWebhook, URL: server.URL}, "500 Internal Server Error: boom"},
+		{"empty url", config.NotificationConfig{Channel: config.ChannelSlack, URL: "$COMMET_TEST_UNSET"}, "url is empty"},
+		{"invalid template", config.NotificationConfig{Channel: config.ChannelWebhook, URL: server.URL, Template: "{{.Version"}, "invalid webhook template"},
+		{"email without host", config.NotificationConfig{Channel: config.ChannelEmail, From: "ci@example.com"}, "email needs smtp_host"},
+		{"unknown channel", config.NotificationConfig{Channel: "pager"}, "unknown notification channel pager"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := notifier.Send(tt.channel, testRelease())
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Send() = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+
+	if len(requests) != 1 {
+		t.Errorf("got %d requests, want 1", len(requests))
+	}
+}
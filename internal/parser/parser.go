@@ -1,49 +1,529 @@
 package parser
 
 import (
+	"fmt"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 )
 
 type Commit struct {
 	Hash        string
+	// FullHash is the unabbreviated commit SHA
+	FullHash    string
+	Author      string
 	Message     string
 	Type        string
 	Scope       string
 	Board       string
+	// Boards lists every board ID referenced, e.g. "B-123 B-456(api): ..."; Board is the first
+	Boards      []string
 	Description string
 	ForceMajor  bool
+	// Files changed by the commit, used by path-based bump rules
+	Files       []string
+	// Bump overrides the bump derived from the type, e.g. "minor" from a
+	// Version-Bump trailer or a commet note
+	Bump        string
+	// Body is the message between the subject and the footers
+	Body        string
+	// Bullets are the bullet points of Body, "- add cursor" or "* add cursor",
+	// with wrapped lines joined
+	Bullets     []string
+	// Footers maps git trailer tokens to their values in order, e.g.
+	// "Reviewed-by" or "Closes" for "Closes #12", whose value keeps the "#"
+	Footers     map[string][]string
+	// BreakingChange is the migration text of a BREAKING CHANGE footer
+	BreakingChange string
+	// PR is the pull request number a squash merge appends, "add SSO (#482)"
+	PR          int
+	// Reverts is the subject of the commit undone by a Revert "..." commit,
+	// and RevertsHash its hash from the "This reverts commit" line
+	Reverts     string
+	RevertsHash string
+	// Excluded commits are left out of the release, e.g. by a commet note
+	Excluded    bool
+	// References are the issues the description and body mention
+	References  []Reference
+	// CoAuthors are the names of the Co-authored-by trailers
+	CoAuthors   []string
 }
 
-var (
-	// Pattern 1: J-123456(parser,regex): <Fix> syntax issue
-	pattern1 = regexp.MustCompile(`^(?P<board>[A-Z]+-\d+)(?:\((?P<scope>[^)]+)\))?: <(?P<type>[^>]+)>\s*(?P<desc>.+)$`)
+// Reference is an issue mentioned by a commit, "#123" or "JIRA-456". Closes
+// is set when a closing keyword precedes it, as in "Closes #123".
+type Reference struct {
+	Issue  string
+	Closes bool
+}
+
+// DefaultBoardPattern matches board IDs such as "J-123456" and "U-1234".
+const DefaultBoardPattern = `[A-Z]+-\d+`
+
+// Pattern 4: Feature!(log): added logger, or feat(log)!: added logger (Conventional Commits)
+var pattern4 = regexp.MustCompile(`^(?P<type>\w+)(?P<force>!)?(?:\((?P<scope>[^)]+)\))?(?P<force>!)?: (?P<desc>.+)$`)
 
-	// Pattern 2: U-1234(config): Feature new section
-	pattern2 = regexp.MustCompile(`^(?P<board>[A-Z]+-\d+)\((?P<scope>[^)]+)\): (?P<type>\w+)\s+(?P<desc>.+)$`)
+// boardPatterns returns the built-in patterns opening with one or more board
+// IDs matching board.
+func boardPatterns(board string) []*regexp.Regexp {
+	boards := `(?P<board>(?:` + board + `)(?:[ ,]+(?:` + board + `))*)`
+	return []*regexp.Regexp{
+		// Pattern 1: J-123456(parser,regex): <Fix> syntax issue
+		regexp.MustCompile(`^` + boards + `(?:\((?P<scope>[^)]+)\))?: <(?P<type>[^>]+)>\s*(?P<desc>.+)$`),
+		// Pattern 2: U-1234(config): Feature new section
+		regexp.MustCompile(`^` + boards + `\((?P<scope>[^)]+)\): (?P<type>\w+)\s+(?P<desc>.+)$`),
+		// Pattern 3: U-1234: Tests added for parser
+		regexp.MustCompile(`^` + boards + `: (?P<type>\w+)\s+(?P<desc>.+)$`),
+	}
+}
 
-	// Pattern 3: U-1234: Tests added for parser
-	pattern3 = regexp.MustCompile(`^(?P<board>[A-Z]+-\d+): (?P<type>\w+)\s+(?P<desc>.+)$`)
+// Options configure a Parser. The zero value parses with the built-in
+// patterns and board pattern, without gitmojis, type aliases or skip markers.
+type Options struct {
+	// Patterns are regular expressions with the named groups type, scope,
+	// board, desc and force, tried in order before the built-in patterns, or
+	// instead of them with Replace. Every pattern needs a type group.
+	Patterns []string
+	Replace  bool
 
-	// Pattern 4: Feature!(log): added logger
-	pattern4 = regexp.MustCompile(`^(?P<type>\w+)(?P<force>!)?(?:\((?P<scope>[^)]+)\))?: (?P<desc>.+)$`)
+	// BoardPattern matches board IDs, e.g. `[a-z]+_\d+` for "proj_1234",
+	// instead of DefaultBoardPattern. It must not be anchored. With
+	// DisableBoards nothing is read as a board: the built-in board patterns
+	// are dropped and the board groups of custom patterns ignored.
+	BoardPattern  string
+	DisableBoards bool
 
-	// All patterns in order of priority
-	patterns = []*regexp.Regexp{pattern1, pattern2, pattern3, pattern4}
-)
+	// Gitmoji reads gitmoji subjects, "✨ add search" or ":sparkles: add
+	// search", as the type of their emoji. GitmojiTypes adds to or overrides
+	// the built-in mapping, e.g. "🚀" = "Feature".
+	Gitmoji      bool
+	GitmojiTypes map[string]string
+
+	// TypeAliases rename parsed types, e.g. feat to Feature and bugfix to
+	// Fix. With FoldTypeAliases, "FEAT" matches feat too.
+	TypeAliases     map[string]string
+	FoldTypeAliases bool
+
+	// SkipMarkers exclude the commits whose subject contains one, in any case.
+	SkipMarkers []string
+}
+
+// Parser parses commit messages with the settings of its Options.
+type Parser struct {
+	// patterns are all patterns in order of priority
+	patterns []*regexp.Regexp
+
+	// boardIDs matches a board ID, nil with boards disabled
+	boardIDs *regexp.Regexp
+
+	// boardExpr is the board pattern, "" with boards disabled
+	boardExpr string
+
+	// issueReference matches the issue references of referencePattern
+	issueReference *regexp.Regexp
+
+	// gitmojiTypes maps gitmojis to types when gitmoji subjects are enabled
+	gitmojiTypes map[string]string
+
+	typeAliases     map[string]string
+	foldTypeAliases bool
+
+	skipMarkers []*regexp.Regexp
+}
+
+// New returns a Parser for opts, or an error when a pattern or the board
+// pattern is not a valid regular expression or a pattern has no type group.
+func New(opts Options) (*Parser, error) {
+	p := &Parser{
+		typeAliases:     opts.TypeAliases,
+		foldTypeAliases: opts.FoldTypeAliases,
+		skipMarkers:     markerPatterns(opts.SkipMarkers),
+	}
+
+	for _, expr := range opts.Patterns {
+		pattern, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", expr, err)
+		}
+		if pattern.SubexpIndex("type") < 0 {
+			return nil, fmt.Errorf("pattern %q has no (?P<type>...) group", expr)
+		}
+		p.patterns = append(p.patterns, pattern)
+	}
+
+	builtins := []*regexp.Regexp{pattern4}
+	if !opts.DisableBoards {
+		expr := opts.BoardPattern
+		if expr == "" {
+			expr = DefaultBoardPattern
+		}
+		board, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid board pattern %q: %w", expr, err)
+		}
+		p.boardIDs, p.boardExpr = board, expr
+		builtins = append(boardPatterns(expr), pattern4)
+	}
+	if !opts.Replace {
+		p.patterns = append(p.patterns, builtins...)
+	}
+	p.issueReference = referencePattern(p.boardExpr)
+
+	if opts.Gitmoji {
+		p.gitmojiTypes = make(map[string]string, len(defaultGitmojiTypes)+len(opts.GitmojiTypes))
+		for emoji, commitType := range defaultGitmojiTypes {
+			p.gitmojiTypes[emoji] = commitType
+		}
+		for emoji, commitType := range opts.GitmojiTypes {
+			p.gitmojiTypes[strings.ReplaceAll(emoji, "\uFE0F", "")] = commitType
+		}
+	}
+
+	return p, nil
+}
+
+// defaultParser parses with the built-in patterns and the default skip
+// markers; see Default.
+var defaultParser = func() *Parser {
+	p, err := New(Options{SkipMarkers: DefaultSkipMarkers})
+	if err != nil {
+		panic(err)
+	}
+	return p
+}()
+
+// Default returns the Parser of the built-in patterns and board pattern and
+// DefaultSkipMarkers, which Parse uses.
+func Default() *Parser {
+	return defaultParser
+}
+
+// BoardPattern returns the board pattern of p, or "" with boards disabled.
+func (p *Parser) BoardPattern() string {
+	return p.boardExpr
+}
+
+// gitmojiSubject matches gitmoji subjects, "✨ add search" or
+// ":bug: (api): fix crash", capturing the emoji or its shortcode.
+var gitmojiSubject = regexp.MustCompile(`^(:[a-z0-9_+-]+:|[^\x00-\x7F]+)\s*(?:\(([^)]+)\))?:?\s+(.+)$`)
+
+// defaultGitmojiTypes maps gitmojis, as emoji and shortcode, to commit types.
+var defaultGitmojiTypes = map[string]string{
+	"✨": "Feature", ":sparkles:": "Feature",
+	"🐛": "Fix", ":bug:": "Fix",
+	"🚑": "Fix", ":ambulance:": "Fix",
+	"🔒": "Security", ":lock:": "Security",
+	"💥": "Breaking", ":boom:": "Breaking",
+	"⚡": "Refactor", ":zap:": "Refactor",
+	"♻": "Refactor", ":recycle:": "Refactor",
+	"🔥": "Refactor", ":fire:": "Refactor",
+	"📝": "Docs", ":memo:": "Docs",
+	"🎨": "Style", ":art:": "Style",
+	"💄": "Style", ":lipstick:": "Style",
+	"✅": "Tests", ":white_check_mark:": "Tests",
+	"🧪": "Tests", ":test_tube:": "Tests",
+	"👷": "Build", ":construction_worker:": "Build",
+	"📦": "Build", ":package:": "Build",
+	"⬆": "Build", ":arrow_up:": "Build",
+	"⬇": "Build", ":arrow_down:": "Build",
+	"🔧": "Conf", ":wrench:": "Conf",
+	"🔖": "Conf", ":bookmark:": "Conf",
+	"🗃": "Migrations", ":card_file_box:": "Migrations",
+	"⏪": "Revert", ":rewind:": "Revert",
+}
+
+// aliasType returns the alias of commitType, or commitType without one.
+func (p *Parser) aliasType(commitType string) string {
+	if alias, ok := p.typeAliases[commitType]; ok {
+		return alias
+	}
+	if p.foldTypeAliases {
+		names := make([]string, 0, len(p.typeAliases))
+		for name := range p.typeAliases {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if strings.EqualFold(name, commitType) {
+				return p.typeAliases[name]
+			}
+		}
+	}
+	return commitType
+}
+
+// DefaultSkipMarkers exclude a commit from releases when its subject
+// contains one, like "[skip ci]" skips CI.
+var DefaultSkipMarkers = []string{"[skip version]", "[no bump]"}
+
+func markerPatterns(markers []string) []*regexp.Regexp {
+	var patterns []*regexp.Regexp
+	for _, marker := range markers {
+		if marker != "" {
+			patterns = append(patterns, regexp.MustCompile(`(?i)`+regexp.QuoteMeta(marker)))
+		}
+	}
+	return patterns
+}
+
+// skipMarker returns the skip marker in subject as written, or "" without one.
+func (p *Parser) skipMarker(subject string) string {
+	for _, marker := range p.skipMarkers {
+		if found := marker.FindString(subject); found != "" {
+			return found
+		}
+	}
+	return ""
+}
+
+// AdvisoryPattern matches security advisory IDs, "CVE-2024-3094" or
+// "GHSA-xxxx-xxxx-xxxx", which are always read as references.
+const AdvisoryPattern = `CVE-\d{4}-\d{4,}|GHSA(?:-[0-9a-z]{4}){3}`
+
+// referencePattern matches issue references, "#123", "JIRA-456" or an
+// advisory ID, with an optional closing keyword as GitHub reads them: "Closes
+// #123", "fixes: #7". With boards disabled, board is "" and only "#123" and
+// advisories match.
+func referencePattern(board string) *regexp.Regexp {
+	issue := AdvisoryPattern + `|#\d+`
+	switch board {
+	case "":
+	case DefaultBoardPattern:
+		issue += `|[A-Z][A-Z0-9]*-\d+`
+	default:
+		issue += `|(?:` + board + `)`
+	}
+	return regexp.MustCompile(`(?:^|[^\w#/-])(?:((?i:close[sd]?|fix(?:e[sd])?|resolve[sd]?)):?\s+)?(` + issue + `)\b`)
+}
+
+// references returns the issues referenced in text, each once, in order of
+// first mention; an issue closed anywhere counts as closed.
+func (p *Parser) references(text string) []Reference {
+	var refs []Reference
+	index := make(map[string]int)
+	for _, matches := range p.issueReference.FindAllStringSubmatch(text, -1) {
+		issue, closes := matches[2], matches[1] != ""
+		if i, ok := index[issue]; ok {
+			refs[i].Closes = refs[i].Closes || closes
+			continue
+		}
+		index[issue] = len(refs)
+		refs = append(refs, Reference{Issue: issue, Closes: closes})
+	}
+	return refs
+}
+
+// squashPR matches the pull request number GitHub appends to squash merges.
+var squashPR = regexp.MustCompile(`\s*\(#(\d+)\)$`)
+
+// revertSubject matches the subject git gives a revert, Revert "Fix: typo".
+var revertSubject = regexp.MustCompile(`^Revert "(.+)"$`)
+
+// revertedCommit matches the line naming the reverted commit in its body.
+var revertedCommit = regexp.MustCompile(`This reverts commit ([0-9a-f]{7,40})`)
+
+// footerLine matches the first line of a footer, e.g. "Refs: #12",
+// "Closes #12" or "BREAKING CHANGE: page is gone".
+var footerLine = regexp.MustCompile(`^([\w-]+|BREAKING CHANGE)(: | #)(.*)$`)
+
+// splitFooters separates the footers at the end of body from the text above
+// them. The footers are the trailing paragraphs that each open with a footer
+// line; other lines continue the footer above them.
+func splitFooters(body string) (string, map[string][]string) {
+	lines := strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n")
+
+	start := len(lines)
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.TrimSpace(lines[i]) != "" && (i == 0 || strings.TrimSpace(lines[i-1]) == "") {
+			if !footerLine.MatchString(lines[i]) {
+				break
+			}
+			start = i
+		}
+	}
+	if start == len(lines) {
+		return strings.TrimSpace(body), nil
+	}
+
+	footers := make(map[string][]string)
+	var token string
+	var value []string
+	flush := func() {
+		if token != "" {
+			footers[token] = append(footers[token], strings.TrimSpace(strings.Join(value, "\n")))
+		}
+	}
+	for _, line := range lines[start:] {
+		if matches := footerLine.FindStringSubmatch(line); matches != nil {
+			flush()
+			token, value = matches[1], []string{matches[3]}
+			if matches[2] == " #" {
+				value[0] = "#" + value[0]
+			}
+			continue
+		}
+		value = append(value, line)
+	}
+	flush()
+
+	return strings.TrimSpace(strings.Join(lines[:start], "\n")), footers
+}
 
+// Footer returns the values of the footer token, matched case-insensitively;
+// "BREAKING CHANGE" and "BREAKING-CHANGE" are the same token.
+func (c *Commit) Footer(token string) []string {
+	normalize := func(t string) string {
+		return strings.ToLower(strings.ReplaceAll(t, " ", "-"))
+	}
+
+	var keys []string
+	for key := range c.Footers {
+		if normalize(key) == normalize(token) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var values []string
+	for _, key := range keys {
+		values = append(values, c.Footers[key]...)
+	}
+	return values
+}
+
+// Parse parses a commit message with the Default parser.
 func Parse(message string) (*Commit, error) {
+	return defaultParser.Parse(message)
+}
+
+// Parse parses a commit message. Only the subject line is matched against the
+// patterns; the body is split into text and footers, and a BREAKING CHANGE
+// footer forces a major bump. Reverts made by git, Revert "Feature: x", get
+// the type Revert and never force a major bump themselves. With
+// Options.Gitmoji, gitmoji subjects are tried before the patterns. Types are
+// renamed with the type aliases last, and the issues the description and
+// body mention are collected into References, Co-authored-by trailers into
+// CoAuthors and the bullet points of the body into Bullets. A Version-Bump
+// trailer, "Version-Bump: minor", sets Bump, and a skip marker,
+// "[skip version]", sets Excluded and is dropped from the description.
+func (p *Parser) Parse(message string) (*Commit, error) {
+	commit, err := p.parseMessage(message)
+	if err != nil {
+		return nil, err
+	}
+
+	subject, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	if marker := p.skipMarker(subject); marker != "" {
+		commit.Excluded = true
+		commit.Description = strings.Join(strings.Fields(strings.Replace(commit.Description, marker, "", 1)), " ")
+	}
+	if commit.Type != "" {
+		commit.Type = p.aliasType(commit.Type)
+	}
+
+	_, body, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	commit.References = p.references(commit.Description + "\n" + body)
+
+	commit.Bump = commit.versionBump()
+	commit.Bullets = bullets(commit.Body)
+
+	for _, coAuthor := range commit.Footer("Co-authored-by") {
+		name, _, _ := strings.Cut(coAuthor, "<")
+		if name = strings.TrimSpace(name); name != "" {
+			commit.CoAuthors = append(commit.CoAuthors, name)
+		}
+	}
+
+	return commit, nil
+}
+
+// bulletMarker matches the marker opening a bullet point, "- ", "* ", "+ "
+// or "• ", at any indentation.
+var bulletMarker = regexp.MustCompile(`^\s*[-*+•]\s+`)
+
+// bullets returns the bullet points of body. Indented lines below a bullet
+// continue it; other lines end it.
+func bullets(body string) []string {
+	var points []string
+	open := false
+	for _, line := range strings.Split(body, "\n") {
+		if loc := bulletMarker.FindStringIndex(line); loc != nil {
+			if point := strings.TrimSpace(line[loc[1]:]); point != "" {
+				points = append(points, point)
+				open = true
+				continue
+			}
+		}
+		if open && strings.TrimSpace(line) != "" && strings.TrimLeft(line, " \t") != line {
+			points[len(points)-1] += " " + strings.TrimSpace(line)
+			continue
+		}
+		open = false
+	}
+	return points
+}
+
+// versionBump returns the bump of the last Version-Bump trailer, one of
+// none, build, patch, minor and major in any case, or "" without a trailer
+// or with another value.
+func (c *Commit) versionBump() string {
+	values := c.Footer("Version-Bump")
+	if len(values) == 0 {
+		return ""
+	}
+
+	bump := strings.ToLower(strings.TrimSpace(values[len(values)-1]))
+	switch bump {
+	case "none", "build", "patch", "minor", "major":
+		return bump
+	}
+	return ""
+}
+
+func (p *Parser) parseMessage(message string) (*Commit, error) {
 	commit := &Commit{
 		Message: message,
 	}
 
-	message = strings.TrimSpace(message)
+	subject, body, multiline := strings.Cut(strings.TrimSpace(message), "\n")
+	if multiline {
+		commit.Message = strings.TrimSpace(subject)
+	}
+	message = strings.TrimSpace(subject)
+
+	if matches := revertSubject.FindStringSubmatch(message); matches != nil {
+		commit.Type = "Revert"
+		commit.Description = matches[1]
+		commit.Reverts = matches[1]
+		if hash := revertedCommit.FindStringSubmatch(body); hash != nil {
+			commit.RevertsHash = hash[1]
+		}
+		commit.Body, commit.Footers = splitFooters(body)
+		return commit, nil
+	}
 
 	if strings.Contains(message, "Breaking") || strings.Contains(message, "BREAKING") {
 		commit.ForceMajor = true
 	}
 
-	for _, pattern := range patterns {
+	commit.Body, commit.Footers = splitFooters(body)
+	if changes := commit.Footer("BREAKING CHANGE"); len(changes) > 0 {
+		commit.ForceMajor = true
+		commit.BreakingChange = strings.Join(changes, "\n")
+	}
+
+	if p.gitmojiTypes != nil {
+		if matches := gitmojiSubject.FindStringSubmatch(message); matches != nil {
+			if commitType, ok := p.gitmojiTypes[strings.ReplaceAll(matches[1], "\uFE0F", "")]; ok {
+				commit.Type = commitType
+				commit.Scope = matches[2]
+				commit.setDescription(matches[3])
+				return commit, nil
+			}
+		}
+	}
+
+	for _, pattern := range p.patterns {
 		if matches := pattern.FindStringSubmatch(message); matches != nil {
 			names := pattern.SubexpNames()
 			for i, name := range names {
@@ -57,9 +537,19 @@ func Parse(message string) (*Commit, error) {
 				case "scope":
 					commit.Scope = value
 				case "board":
-					commit.Board = value
+					if p.boardIDs == nil {
+						continue
+					}
+					commit.Boards = p.boardIDs.FindAllString(value, -1)
+					if len(commit.Boards) == 0 && strings.TrimSpace(value) != "" {
+						// Custom patterns may capture boards in other formats
+						commit.Boards = []string{strings.TrimSpace(value)}
+					}
+					if len(commit.Boards) > 0 {
+						commit.Board = commit.Boards[0]
+					}
 				case "desc":
-					commit.Description = value
+					commit.setDescription(value)
 				case "force":
 					if value == "!" {
 						commit.ForceMajor = true
@@ -87,16 +577,133 @@ func Parse(message string) (*Commit, error) {
 	return commit, nil
 }
 
+// setDescription sets the description, moving the pull request number a
+// squash merge appends into PR.
+func (c *Commit) setDescription(value string) {
+	c.Description = value
+	if matches := squashPR.FindStringSubmatch(value); matches != nil && squashPR.ReplaceAllString(value, "") != "" {
+		c.PR, _ = strconv.Atoi(matches[1])
+		c.Description = squashPR.ReplaceAllString(value, "")
+	}
+}
+
+// ParseMultiple parses messages with the Default parser; see Parser.ParseMultiple.
 func ParseMultiple(messages []string) []*Commit {
+	return defaultParser.ParseMultiple(messages)
+}
+
+// ParseMultiple parses messages, dropping those without a type.
+func (p *Parser) ParseMultiple(messages []string) []*Commit {
 	commits := make([]*Commit, 0, len(messages))
 	for _, msg := range messages {
-		if commit, err := Parse(msg); err == nil && commit.Type != "" {
+		if commit, err := p.Parse(msg); err == nil && commit.Type != "" {
 			commits = append(commits, commit)
 		}
 	}
 	return commits
 }
 
+// Contributors returns the author and co-authors of the commit, each once.
+func (c *Commit) Contributors() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, name := range append([]string{c.Author}, c.CoAuthors...) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// HasBoard reports whether the commit references the board ID.
+func (c *Commit) HasBoard(board string) bool {
+	if c.Board == board {
+		return true
+	}
+	for _, b := range c.Boards {
+		if b == board {
+			return true
+		}
+	}
+	return false
+}
+
+// RenameScopes maps each scope of a comma-separated scope list through
+// mapping, dropping scopes that become duplicates. Spacing after commas is kept.
+func RenameScopes(scope string, mapping map[string]string) string {
+	separator := ","
+	if strings.Contains(scope, ", ") {
+		separator = ", "
+	}
+
+	seen := make(map[string]bool)
+	var renamed []string
+	for _, s := range strings.Split(scope, ",") {
+		s = strings.TrimSpace(s)
+		if to, ok := mapping[s]; ok {
+			s = to
+		}
+		if s == "" || seen[s] {
+			continue
+		}
+		seen[s] = true
+		renamed = append(renamed, s)
+	}
+
+	return strings.Join(renamed, separator)
+}
+
+// Annotate applies the overrides of a commet note, one "Key: value" per line:
+// Type, Scope, Description, Breaking and Exclude (true or false) and Bump
+// (none, build, patch, minor or major). Keys are case-insensitive.
+func (c *Commit) Annotate(note string) error {
+	for _, line := range strings.Split(note, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return fmt.Errorf("invalid note line %q, expected \"Key: value\"", line)
+		}
+		value = strings.TrimSpace(value)
+
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "type":
+			c.Type = value
+		case "scope":
+			c.Scope = value
+		case "description":
+			c.Description = value
+		case "breaking":
+			breaking, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid Breaking value %q in note", value)
+			}
+			c.ForceMajor = breaking
+		case "exclude":
+			exclude, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid Exclude value %q in note", value)
+			}
+			c.Excluded = exclude
+		case "bump":
+			switch value {
+			case "none", "build", "patch", "minor", "major":
+				c.Bump = value
+			default:
+				return fmt.Errorf("invalid Bump value %q in note", value)
+			}
+		default:
+			return fmt.Errorf("unknown note key %q", key)
+		}
+	}
+
+	return nil
+}
+
 func (c *Commit) IsValidCommit() bool {
 	return c.Type != ""
 }
@@ -104,7 +711,9 @@ func (c *Commit) IsValidCommit() bool {
 func (c *Commit) String() string {
 	var parts []string
 
-	if c.Board != "" {
+	if len(c.Boards) > 0 {
+		parts = append(parts, strings.Join(c.Boards, " "))
+	} else if c.Board != "" {
 		parts = append(parts, c.Board)
 	}
 
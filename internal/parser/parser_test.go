@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -98,6 +99,30 @@ func TestParse(t *testing.T) {
 			expectedDesc: "added for parser",
 			expectedForce: false,
 		},
+		{
+			name:         "conventional commit",
+			message:      "feat(api): add export endpoint",
+			expectedType: "feat",
+			expectedScope: "api",
+			expectedDesc: "add export endpoint",
+			expectedForce: false,
+		},
+		{
+			name:         "conventional breaking after scope",
+			message:      "feat(api)!: drop v1 endpoints",
+			expectedType: "feat",
+			expectedScope: "api",
+			expectedDesc: "drop v1 endpoints",
+			expectedForce: true,
+		},
+		{
+			name:         "conventional breaking without scope",
+			message:      "chore!: drop Node 16",
+			expectedType: "chore",
+			expectedScope: "",
+			expectedDesc: "drop Node 16",
+			expectedForce: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -131,6 +156,51 @@ func TestParse(t *testing.T) {
 	}
 }
 
+func TestParseMultipleBoards(t *testing.T) {
+	tests := []struct {
+		message string
+		boards  []string
+		scope   string
+		desc    string
+	}{
+		{"B-123 B-456(api): Fix timeout", []string{"B-123", "B-456"}, "api", "timeout"},
+		{"B-123,U-7: <Feature> export", []string{"B-123", "U-7"}, "", "export"},
+		{"J-1 J-2 J-3: Docs update readme", []string{"J-1", "J-2", "J-3"}, "", "update readme"},
+		{"U-1234(user): Feature some feat", []string{"U-1234"}, "user", "some feat"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			result, err := Parse(tt.message)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			if strings.Join(result.Boards, " ") != strings.Join(tt.boards, " ") {
+				t.Errorf("Boards = %v, want %v", result.Boards, tt.boards)
+			}
+
+			if result.Board != tt.boards[0] {
+				t.Errorf("Board = %v, want %v", result.Board, tt.boards[0])
+			}
+
+			if result.Scope != tt.scope {
+				t.Errorf("Scope = %v, want %v", result.Scope, tt.scope)
+			}
+
+			if result.Description != tt.desc {
+				t.Errorf("Description = %v, want %v", result.Description, tt.desc)
+			}
+
+			for _, board := range tt.boards {
+				if !result.HasBoard(board) {
+					t.Errorf("HasBoard(%s) = false", board)
+				}
+			}
+		})
+	}
+}
+
 func TestParseMultiple(t *testing.T) {
 	messages := []string{
 		"Feature(auth): add OAuth support",
@@ -213,3 +283,448 @@ func containsMiddle(s, substr string) bool {
 	}
 	return false
 }
+
+func TestRenameScopes(t *testing.T) {
+	mapping := map[string]string{"auth": "identity", "db": "storage"}
+
+	tests := []struct {
+		scope    string
+		expected string
+	}{
+		{"auth", "identity"},
+		{"api", "api"},
+		{"parser,db", "parser,storage"},
+		{"auth, db", "identity, storage"},
+		{"identity,auth", "identity"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.scope, func(t *testing.T) {
+			if got := RenameScopes(tt.scope, mapping); got != tt.expected {
+				t.Errorf("RenameScopes(%q) = %q, want %q", tt.scope, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	tests := []struct {
+		name    string
+		note    string
+		want    Commit
+		wantErr bool
+	}{
+		{"reclassify", "Type: Breaking\n", Commit{Type: "Breaking", Description: "drop endpoint"}, false},
+		{"mark breaking", "breaking: true\nDescription: drop the v1 endpoint", Commit{Type: "Fix", Description: "drop the v1 endpoint", ForceMajor: true}, false},
+		{"override bump", "Bump: minor\n# comments are skipped\n", Commit{Type: "Fix", Description: "drop endpoint", Bump: "minor"}, false},
+		{"scope", "Scope: api", Commit{Type: "Fix", Scope: "api", Description: "drop endpoint"}, false},
+		{"invalid bump", "Bump: huge", Commit{}, true},
+		{"unknown key", "Owner: me", Commit{}, true},
+		{"not a trailer", "breaking", Commit{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commit, _ := Parse("Fix: drop endpoint")
+			err := commit.Annotate(tt.note)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Annotate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			tt.want.Message = "Fix: drop endpoint"
+			if commit.String() != tt.want.String() || commit.Bump != tt.want.Bump {
+				t.Errorf("Annotate() = %q (bump %q), want %q (bump %q)", commit.String(), commit.Bump, tt.want.String(), tt.want.Bump)
+			}
+		})
+	}
+}
+
+func TestParseBreakingFooter(t *testing.T) {
+	tests := []struct {
+		name      string
+		message   string
+		wantForce bool
+		wantText  string
+	}{
+		{"footer", "feat(api): cursor pagination\n\nBREAKING CHANGE: page is gone", true, "page is gone"},
+		{"hyphenated footer", "fix: trim input\n\nBREAKING-CHANGE: whitespace is significant now", true, "whitespace is significant now"},
+		{"continued up to next footer", "feat: new config\n\nBREAKING CHANGE: rename keys\nrun commet migrate first\nRefs: #12", true, "rename keys\nrun commet migrate first"},
+		{"body mentioning breaking", "fix: handle nil\n\nNot Breaking anything, BREAKING changes come later.", false, ""},
+		{"no body", "fix: handle nil", false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commit, err := Parse(tt.message)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if commit.ForceMajor != tt.wantForce {
+				t.Errorf("ForceMajor = %v, want %v", commit.ForceMajor, tt.wantForce)
+			}
+			if commit.BreakingChange != tt.wantText {
+				t.Errorf("BreakingChange = %q, want %q", commit.BreakingChange, tt.wantText)
+			}
+			if strings.Contains(commit.Message, "\n") {
+				t.Errorf("Message = %q, want the subject only", commit.Message)
+			}
+		})
+	}
+}
+
+func TestParseFooters(t *testing.T) {
+	tests := []struct {
+		name        string
+		message     string
+		wantBody    string
+		wantFooters map[string][]string
+	}{
+		{"body and trailers", "Fix: handle nil\n\nThe client returned nil on timeouts.\n\nReviewed-by: Ann <ann@example.com>\nRefs: #12\nRefs: #14", "The client returned nil on timeouts.", map[string][]string{"Reviewed-by": {"Ann <ann@example.com>"}, "Refs": {"#12", "#14"}}},
+		{"hash separator", "fix: trim input\n\nCloses #7", "", map[string][]string{"Closes": {"#7"}}},
+		{"continuation line", "feat: new config\n\nBREAKING CHANGE: rename keys\nrun commet migrate first\nRefs: #12", "", map[string][]string{"BREAKING CHANGE": {"rename keys\nrun commet migrate first"}, "Refs": {"#12"}}},
+		{"prose is not a footer", "Fix: handle nil\n\nNote: this only affects v1.\n\nMore details follow.", "Note: this only affects v1.\n\nMore details follow.", nil},
+		{"no body", "Fix: handle nil", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commit, err := Parse(tt.message)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if commit.Body != tt.wantBody {
+				t.Errorf("Body = %q, want %q", commit.Body, tt.wantBody)
+			}
+			if len(commit.Footers) != len(tt.wantFooters) {
+				t.Fatalf("Footers = %q, want %q", commit.Footers, tt.wantFooters)
+			}
+			for token, want := range tt.wantFooters {
+				if got := commit.Footer(strings.ToLower(token)); strings.Join(got, "|") != strings.Join(want, "|") {
+					t.Errorf("Footer(%q) = %q, want %q", token, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseRevert(t *testing.T) {
+	tests := []struct {
+		name        string
+		message     string
+		wantReverts string
+		wantHash    string
+	}{
+		{"git revert", "Revert \"Feature(api): add export\"\n\nThis reverts commit 1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b.", "Feature(api): add export", "1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b"},
+		{"breaking revert", "Revert \"Breaking: drop v1\"", "Breaking: drop v1", ""},
+		{"revert of a revert", "Revert \"Revert \"Fix: typo\"\"\n\nThis reverts commit abc1234.", "Revert \"Fix: typo\"", "abc1234"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commit, err := Parse(tt.message)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if commit.Type != "Revert" || commit.ForceMajor {
+				t.Errorf("Type = %q, ForceMajor = %v, want Revert without a forced major", commit.Type, commit.ForceMajor)
+			}
+			if commit.Reverts != tt.wantReverts || commit.RevertsHash != tt.wantHash {
+				t.Errorf("Reverts = %q, %q, want %q, %q", commit.Reverts, commit.RevertsHash, tt.wantReverts, tt.wantHash)
+			}
+		})
+	}
+}
+
+func TestParseSquashPR(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		wantDesc string
+		wantPR   int
+	}{
+		{"squash merge", "Feature(auth): add SSO (#482)", "add SSO", 482},
+		{"conventional", "fix: handle timeouts (#7)", "handle timeouts", 7},
+		{"no suffix", "Fix: handle (#482) in the middle", "handle (#482) in the middle", 0},
+		{"only a number", "Fix: (#482)", "(#482)", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commit, err := Parse(tt.message)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if commit.Description != tt.wantDesc || commit.PR != tt.wantPR {
+				t.Errorf("Description, PR = %q, %d, want %q, %d", commit.Description, commit.PR, tt.wantDesc, tt.wantPR)
+			}
+		})
+	}
+}
+
+func TestNewPatterns(t *testing.T) {
+	custom := []string{`^\[(?P<scope>[^\]]+)\] (?P<type>\w+): (?P<desc>.+)$`, `^(?P<board>#\d+) (?P<type>\w+) (?P<desc>.+)$`}
+	p, err := New(Options{Patterns: custom})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		message   string
+		wantType  string
+		wantScope string
+		wantBoard string
+		wantDesc  string
+	}{
+		{"[api] Feature: add export", "Feature", "api", "", "add export"},
+		{"#42 Fix handle timeouts", "Fix", "", "#42", "handle timeouts"},
+		{"U-1234(config): Feature new section", "Feature", "config", "U-1234", "new section"},
+	}
+	for _, tt := range tests {
+		commit, err := p.Parse(tt.message)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", tt.message, err)
+		}
+		if commit.Type != tt.wantType || commit.Scope != tt.wantScope || commit.Board != tt.wantBoard || commit.Description != tt.wantDesc {
+			t.Errorf("Parse(%q) = %q, %q, %q, %q, want %q, %q, %q, %q", tt.message,
+				commit.Type, commit.Scope, commit.Board, commit.Description,
+				tt.wantType, tt.wantScope, tt.wantBoard, tt.wantDesc)
+		}
+	}
+
+	replaced, err := New(Options{Patterns: custom[:1], Replace: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if commit, _ := replaced.Parse("U-1234(config): Feature new section"); commit.Board != "" {
+		t.Errorf("built-in pattern still applied with replace: board %q", commit.Board)
+	}
+	if commit, _ := Parse("[api] Feature: add export"); commit.Scope == "api" {
+		t.Errorf("custom pattern applied to the default parser")
+	}
+
+	if _, err := New(Options{Patterns: []string{`^(?P<desc>.+)$`}}); err == nil {
+		t.Error("New() accepted a pattern without a type group")
+	}
+	if _, err := New(Options{Patterns: []string{`^(?P<type>\w+`}}); err == nil {
+		t.Error("New() accepted an invalid regular expression")
+	}
+}
+
+func TestParseGitmoji(t *testing.T) {
+	if commit, _ := Parse(":bug: fix crash"); commit.Type == "Fix" {
+		t.Fatalf("gitmoji parsed without Options.Gitmoji")
+	}
+
+	p, err := New(Options{Gitmoji: true, GitmojiTypes: map[string]string{"🚀": "Feature"}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		message   string
+		wantType  string
+		wantScope string
+		wantDesc  string
+	}{
+		{"✨ add user search", "Feature", "", "add user search"},
+		{":bug: fix crash", "Fix", "", "fix crash"},
+		{"♻️ (api): simplify handlers", "Refactor", "api", "simplify handlers"},
+		{":boom: drop v1 (#12)", "Breaking", "", "drop v1"},
+		{"🚀 launch", "Feature", "", "launch"},
+		{"Fix: handle timeouts", "Fix", "", "handle timeouts"},
+	}
+	for _, tt := range tests {
+		commit, err := p.Parse(tt.message)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", tt.message, err)
+		}
+		if commit.Type != tt.wantType || commit.Scope != tt.wantScope || commit.Description != tt.wantDesc {
+			t.Errorf("Parse(%q) = %q, %q, %q, want %q, %q, %q", tt.message,
+				commit.Type, commit.Scope, commit.Description, tt.wantType, tt.wantScope, tt.wantDesc)
+		}
+	}
+}
+
+func TestTypeAliases(t *testing.T) {
+	aliases := map[string]string{"feat": "Feature", "bugfix": "Fix"}
+	tests := []struct {
+		message  string
+		foldCase bool
+		wantType string
+	}{
+		{"feat: add export", false, "Feature"},
+		{"bugfix(api): handle nil", false, "Fix"},
+		{"BUGFIX: handle nil", false, "BUGFIX"},
+		{"BUGFIX: handle nil", true, "Fix"},
+		{"Docs: typo", true, "Docs"},
+	}
+
+	for _, tt := range tests {
+		p, err := New(Options{TypeAliases: aliases, FoldTypeAliases: tt.foldCase})
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		commit, err := p.Parse(tt.message)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", tt.message, err)
+		}
+		if commit.Type != tt.wantType {
+			t.Errorf("Parse(%q) with foldCase %v: Type = %q, want %q", tt.message, tt.foldCase, commit.Type, tt.wantType)
+		}
+	}
+}
+
+func TestParseReferences(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    []Reference
+	}{
+		{"none", "Fix: handle nil", nil},
+		{"mention in subject", "Fix: handle nil from #12", []Reference{{Issue: "#12"}}},
+		{"closing footer", "Fix: handle nil\n\nCloses #12", []Reference{{Issue: "#12", Closes: true}}},
+		{"jira key", "Fix: handle nil\n\nfixes JIRA-456, see #7", []Reference{{Issue: "JIRA-456", Closes: true}, {Issue: "#7"}}},
+		{"mentioned then closed", "Fix: handle #3\n\nResolves: #3", []Reference{{Issue: "#3", Closes: true}}},
+		{"squash pr is no reference", "Fix: handle nil (#482)", nil},
+		{"not in words or paths", "Fix: bump x-1 and a/b#4\n\nabc#5", nil},
+		{"advisories", "Security: escape titles (CVE-2024-3094)\n\nSee GHSA-2c8m-4xqh-9p6f", []Reference{{Issue: "CVE-2024-3094"}, {Issue: "GHSA-2c8m-4xqh-9p6f"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commit, err := Parse(tt.message)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if len(commit.References) != len(tt.want) {
+				t.Fatalf("References = %v, want %v", commit.References, tt.want)
+			}
+			for i, ref := range commit.References {
+				if ref != tt.want[i] {
+					t.Errorf("References[%d] = %v, want %v", i, ref, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseCoAuthors(t *testing.T) {
+	commit, err := Parse("Feature: pair on search\n\nCo-authored-by: Ben Ortiz <ben@example.com>\nco-authored-by: Ana <ana@example.com>\nCo-authored-by: Carla <carla@example.com>")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	commit.Author = "Carla"
+
+	want := []string{"Carla", "Ben Ortiz", "Ana"}
+	got := commit.Contributors()
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("Contributors() = %v, want %v", got, want)
+	}
+}
+
+func TestParseVersionBump(t *testing.T) {
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{"Fix: handle nil\n\nVersion-Bump: minor", "minor"},
+		{"Feature: add export\n\nversion-bump: None", "none"},
+		{"Fix: handle nil\n\nVersion-Bump: patch\nVersion-Bump: major", "major"},
+		{"Fix: handle nil\n\nVersion-Bump: huge", ""},
+		{"Fix: handle nil", ""},
+	}
+
+	for _, tt := range tests {
+		commit, err := Parse(tt.message)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", tt.message, err)
+		}
+		if commit.Bump != tt.want {
+			t.Errorf("Parse(%q).Bump = %q, want %q", tt.message, commit.Bump, tt.want)
+		}
+	}
+}
+
+func TestParseSkipMarkers(t *testing.T) {
+	tests := []struct {
+		message  string
+		excluded bool
+		desc     string
+	}{
+		{"Feature: add export [skip version]", true, "add export"},
+		{"Fix(api): [No Bump] handle nil", true, "handle nil"},
+		{"Fix: skip version checks", false, "skip version checks"},
+	}
+
+	for _, tt := range tests {
+		commit, err := Parse(tt.message)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", tt.message, err)
+		}
+		if commit.Excluded != tt.excluded || commit.Description != tt.desc {
+			t.Errorf("Parse(%q) = excluded %v, description %q, want %v, %q", tt.message, commit.Excluded, commit.Description, tt.excluded, tt.desc)
+		}
+	}
+
+	p, err := New(Options{SkipMarkers: []string{"[release skip]"}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if commit, _ := p.Parse("Fix: handle nil [skip version]"); commit.Excluded {
+		t.Errorf("default marker still skips with custom markers")
+	}
+	if commit, _ := p.Parse("Fix: handle nil [release skip]"); !commit.Excluded {
+		t.Errorf("custom marker does not skip")
+	}
+}
+
+func TestNewBoardPattern(t *testing.T) {
+	p, err := New(Options{BoardPattern: `[a-z]+_\d+`})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	commit, _ := p.Parse("proj_1234 proj_77(api): Fix timeout\n\nRefs ops_5")
+	if commit.Type != "Fix" || commit.Scope != "api" || strings.Join(commit.Boards, " ") != "proj_1234 proj_77" {
+		t.Errorf("Parse() = type %q, scope %q, boards %v", commit.Type, commit.Scope, commit.Boards)
+	}
+	if len(commit.References) != 1 || commit.References[0].Issue != "ops_5" {
+		t.Errorf("References = %v, want ops_5", commit.References)
+	}
+	if commit, _ := p.Parse("U-1234: Fix timeout"); commit.Board != "" {
+		t.Errorf("default board %q still parsed with a custom pattern", commit.Board)
+	}
+
+	disabled, err := New(Options{DisableBoards: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if disabled.BoardPattern() != "" {
+		t.Errorf("BoardPattern() with boards disabled = %q", disabled.BoardPattern())
+	}
+	commit, _ = disabled.Parse("U-1234: Fix timeout, see OPS-7 and #12")
+	if commit.Board != "" || commit.Type != "U-1234" {
+		t.Errorf("Parse() with boards disabled = type %q, board %q", commit.Type, commit.Board)
+	}
+	if len(commit.References) != 1 || commit.References[0].Issue != "#12" {
+		t.Errorf("References with boards disabled = %v, want #12", commit.References)
+	}
+
+	if _, err := New(Options{BoardPattern: `[a-z`}); err == nil {
+		t.Errorf("New() accepted an invalid pattern")
+	}
+}
+
+func TestParseBullets(t *testing.T) {
+	commit, err := Parse("Feature: cursor pagination\n\nDetails:\n- add the cursor parameter to every list\n  endpoint\n* return next_cursor\n\nNot a bullet.\n  - nested item\n\nRefs: #42")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := []string{"add the cursor parameter to every list endpoint", "return next_cursor", "nested item"}
+	if strings.Join(commit.Bullets, "|") != strings.Join(want, "|") {
+		t.Errorf("Bullets = %q, want %q", commit.Bullets, want)
+	}
+}
@@ -0,0 +1,109 @@
+package policy
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/yendefrr/commet/internal/config"
+	"github.com/yendefrr/commet/internal/parser"
+)
+
+// Violation is a releasable commit that breaks the policy.
+type Violation struct {
+	Commit *parser.Commit
+	Reason string
+}
+
+// Checker enforces the required-board policy.
+type Checker struct {
+	config config.PolicyConfig
+	http   *http.Client
+}
+
+func NewChecker(cfg config.PolicyConfig) *Checker {
+	return &Checker{
+		config: cfg,
+		http:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Applies reports whether branch matches one of the require_board_branches patterns.
+func (c *Checker) Applies(branch string) bool {
+	for _, pattern := range c.config.RequireBoardBranches {
+		if ok, _ := path.Match(pattern, branch); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckBoards returns the commits without a board ID and, when board_check_url
+// is set, those referencing a board the tracker does not know.
+func (c *Checker) CheckBoards(commits []*parser.Commit) []Violation {
+	var violations []Violation
+	known := make(map[string]error)
+
+	for _, commit := range commits {
+		if commit.Board == "" {
+			violations = append(violations, Violation{Commit: commit, Reason: "no board ID"})
+			continue
+		}
+
+		if c.config.BoardCheckURL == "" {
+			continue
+		}
+
+		boards := commit.Boards
+		if len(boards) == 0 {
+			boards = []string{commit.Board}
+		}
+
+		for _, board := range boards {
+			err, checked := known[board]
+			if !checked {
+				err = c.checkBoard(board)
+				known[board] = err
+			}
+			if err != nil {
+				violations = append(violations, Violation{Commit: commit, Reason: err.Error()})
+			}
+		}
+	}
+
+	return violations
+}
+
+// checkBoard requests board_check_url for board; any non-2xx response means
+// the board does not exist.
+func (c *Checker) checkBoard(board string) error {
+	endpoint := strings.ReplaceAll(c.config.BoardCheckURL, "{board}", board)
+
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request for %s: %w", board, err)
+	}
+
+	if c.config.BoardCheckTokenEnv != "" {
+		if token := os.Getenv(c.config.BoardCheckTokenEnv); token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to check board %s: %w", board, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("board %s not found in tracker (%s)", board, resp.Status)
+	}
+
+	return nil
+}
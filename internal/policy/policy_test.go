@@ -0,0 +1,84 @@
+package policy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/yendefrr/commet/internal/config"
+	"github.com/yendefrr/commet/internal/parser"
+)
+
+func TestApplies(t *testing.T) {
+	checker := NewChecker(config.PolicyConfig{RequireBoardBranches: []string{"main", "release/*"}})
+
+	tests := map[string]bool{
+		"main":          true,
+		"release/1.x":   true,
+		"release/1/fix": false,
+		"develop":       false,
+	}
+	for branch, want := range tests {
+		if got := checker.Applies(branch); got != want {
+			t.Errorf("Applies(%q) = %v, want %v", branch, got, want)
+		}
+	}
+}
+
+func TestCheckBoards(t *testing.T) {
+	var paths []string
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		auth = r.Header.Get("Authorization")
+		if r.URL.Path != "/boards/B-1" {
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("COMMET_TEST_TRACKER_TOKEN", "secret")
+
+	checker := NewChecker(config.PolicyConfig{
+		BoardCheckURL:      server.URL + "/boards/{board}",
+		BoardCheckTokenEnv: "COMMET_TEST_TRACKER_TOKEN",
+	})
+	commits := parser.ParseMultiple([]string{
+		"B-1(api): <Feature> add export",
+		"B-1: <Fix> handle empty input",
+		"B-2: <Fix> typo",
+		"Fix: no board",
+	})
+
+	violations := checker.CheckBoards(commits)
+
+	var reasons []string
+	for _, violation := range violations {
+		reasons = append(reasons, violation.Commit.Description+": "+violation.Reason)
+	}
+	want := []string{
+		"typo: board B-2 not found in tracker (404 Not Found)",
+		"no board: no board ID",
+	}
+	if strings.Join(reasons, "\n") != strings.Join(want, "\n") {
+		t.Errorf("violations:\n%s\nwant\n%s", strings.Join(reasons, "\n"), strings.Join(want, "\n"))
+	}
+
+	// Every board is requested once
+	if strings.Join(paths, " ") != "/boards/B-1 /boards/B-2" {
+		t.Errorf("requested %v, want /boards/B-1 and /boards/B-2 once", paths)
+	}
+	if auth != "Bearer secret" {
+		t.Errorf("Authorization = %q, want %q", auth, "Bearer secret")
+	}
+}
+
+func TestCheckBoardsWithoutURL(t *testing.T) {
+	checker := NewChecker(config.PolicyConfig{})
+	commits := parser.ParseMultiple([]string{"B-9: <Fix> unknown board", "Fix: no board"})
+
+	violations := checker.CheckBoards(commits)
+	if len(violations) != 1 || violations[0].Reason != "no board ID" {
+		t.Errorf("CheckBoards() = %+v, want only the commit without a board", violations)
+	}
+}
@@ -0,0 +1,161 @@
+package stats
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// Record is one command invocation as stored in the stats file.
+type Record struct {
+	Time     time.Time                `json:"time"`
+	Command  string                   `json:"command"`
+	Duration time.Duration            `json:"duration"`
+	Phases   map[string]time.Duration `json:"phases,omitempty"`
+	Failed   bool                     `json:"failed,omitempty"`
+}
+
+// Recorder measures the phases of a single invocation.
+type Recorder struct {
+	phases map[string]time.Duration
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{phases: make(map[string]time.Duration)}
+}
+
+// Start begins timing phase and returns the function that stops it.
+// Phases that run several times accumulate.
+func (r *Recorder) Start(phase string) func() {
+	started := time.Now()
+	return func() {
+		r.phases[phase] += time.Since(started)
+	}
+}
+
+// Record builds the record of command, which began at start.
+func (r *Recorder) Record(command string, start time.Time, failed bool) Record {
+	return Record{
+		Time:     start,
+		Command:  command,
+		Duration: time.Since(start),
+		Phases:   r.phases,
+		Failed:   failed,
+	}
+}
+
+// Append adds record as a JSON line to the stats file at path.
+func Append(path string, record Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode stats: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open stats file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write stats file: %w", err)
+	}
+
+	return nil
+}
+
+// Load reads all records from the stats file at path.
+func Load(path string) ([]Record, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stats file: %w", err)
+	}
+	defer file.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		records = append(records, record)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read stats file: %w", err)
+	}
+
+	return records, nil
+}
+
+// Summary aggregates the durations recorded under one name.
+type Summary struct {
+	Name   string
+	Runs   int
+	Failed int
+	Total  time.Duration
+	Max    time.Duration
+}
+
+func (s Summary) Average() time.Duration {
+	if s.Runs == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Runs)
+}
+
+// Summarize groups records by command and by phase, slowest total first.
+func Summarize(records []Record) (commands, phases []Summary) {
+	byCommand := make(map[string]*Summary)
+	byPhase := make(map[string]*Summary)
+
+	for _, record := range records {
+		add(byCommand, record.Command, record.Duration, record.Failed)
+		for phase, duration := range record.Phases {
+			add(byPhase, phase, duration, false)
+		}
+	}
+
+	return sorted(byCommand), sorted(byPhase)
+}
+
+func add(summaries map[string]*Summary, name string, duration time.Duration, failed bool) {
+	summary, ok := summaries[name]
+	if !ok {
+		summary = &Summary{Name: name}
+		summaries[name] = summary
+	}
+
+	summary.Runs++
+	summary.Total += duration
+	if duration > summary.Max {
+		summary.Max = duration
+	}
+	if failed {
+		summary.Failed++
+	}
+}
+
+func sorted(summaries map[string]*Summary) []Summary {
+	result := make([]Summary, 0, len(summaries))
+	for _, summary := range summaries {
+		result = append(result, *summary)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Total != result[j].Total {
+			return result[i].Total > result[j].Total
+		}
+		return result[i].Name < result[j].Name
+	})
+
+	return result
+}
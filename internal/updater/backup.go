@@ -0,0 +1,94 @@
+package updater
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/yendefrr/commet/internal/fsutil"
+)
+
+type backupEntry struct {
+	content []byte
+	info    os.FileInfo
+	existed bool
+}
+
+// Backup keeps the original contents of files touched during a release so
+// they can be restored if a later step fails.
+type Backup struct {
+	entries map[string]*backupEntry
+	order   []string
+}
+
+func NewBackup() *Backup {
+	return &Backup{entries: make(map[string]*backupEntry)}
+}
+
+// Save records the current state of path. Only the first call per path is kept.
+func (b *Backup) Save(path string) error {
+	if _, ok := b.entries[path]; ok {
+		return nil
+	}
+
+	entry := &backupEntry{}
+
+	info, err := os.Stat(path)
+	switch {
+	case err == nil:
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to back up %s: %w", path, err)
+		}
+		entry.content = content
+		entry.info = info
+		entry.existed = true
+	case errors.Is(err, os.ErrNotExist):
+		entry.existed = false
+	default:
+		return fmt.Errorf("failed to back up %s: %w", path, err)
+	}
+
+	b.entries[path] = entry
+	b.order = append(b.order, path)
+
+	return nil
+}
+
+// Restore writes back every saved file, removing the ones that did not exist before.
+func (b *Backup) Restore() error {
+	var errs []error
+
+	for i := len(b.order) - 1; i >= 0; i-- {
+		path := b.order[i]
+		entry := b.entries[path]
+
+		if !entry.existed {
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, fmt.Errorf("failed to remove %s: %w", path, err))
+			}
+			continue
+		}
+
+		if err := os.WriteFile(path, entry.content, entry.info.Mode().Perm()); err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore %s: %w", path, err))
+			continue
+		}
+
+		if err := os.Chmod(path, entry.info.Mode().Perm()); err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore permissions on %s: %w", path, err))
+			continue
+		}
+
+		if err := fsutil.RestoreOwner(path, entry.info); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Files returns the saved paths in the order they were recorded.
+func (b *Backup) Files() []string {
+	return append([]string(nil), b.order...)
+}
@@ -0,0 +1,102 @@
+package updater
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBackupRestore(t *testing.T) {
+	dir := t.TempDir()
+	changed := filepath.Join(dir, "package.json")
+	replaced := filepath.Join(dir, "build.sh")
+	created := filepath.Join(dir, "VERSION")
+	narrowed := filepath.Join(dir, "Cargo.toml")
+
+	if err := os.WriteFile(changed, []byte(`{"version": "1.2.3"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(replaced, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(narrowed, []byte("[package]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	backup := NewBackup()
+	for _, path := range []string{changed, replaced, created, narrowed} {
+		if err := backup.Save(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := os.WriteFile(changed, []byte(`{"version": "1.3.0"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(replaced); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(created, []byte("1.3.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(narrowed, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := backup.Restore(); err != nil {
+		t.Fatal(err)
+	}
+
+	assertBackupFile(t, changed, `{"version": "1.2.3"}`, 0644)
+	assertBackupFile(t, replaced, "#!/bin/sh\n", 0755)
+	assertBackupFile(t, narrowed, "[package]\n", 0644)
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Errorf("%s was created during the release and should be removed, stat: %v", created, err)
+	}
+}
+
+func TestBackupKeepsFirstSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "package.json")
+	if err := os.WriteFile(path, []byte("original"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	backup := NewBackup()
+	if err := backup.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("updated"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// A second step touching the same file must not replace the original
+	if err := backup.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if files := backup.Files(); len(files) != 1 || files[0] != path {
+		t.Errorf("Files() = %v, want only %s", files, path)
+	}
+
+	if err := backup.Restore(); err != nil {
+		t.Fatal(err)
+	}
+	assertBackupFile(t, path, "original", 0644)
+}
+
+func assertBackupFile(t *testing.T, path, content string, mode os.FileMode) {
+	t.Helper()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("%s was not restored: %v", path, err)
+	}
+	if info.Mode().Perm() != mode {
+		t.Errorf("%s has mode %v, want %v", path, info.Mode().Perm(), mode)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != content {
+		t.Errorf("%s = %q, want %q", path, got, content)
+	}
+}
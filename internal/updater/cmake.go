@@ -0,0 +1,44 @@
+package updater
+
+import (
+	"regexp"
+	"strings"
+)
+
+// cmakeProjectPattern matches the VERSION argument of project(), which may
+// span several lines. Command names are case-insensitive in CMake, keywords are not.
+var cmakeProjectPattern = regexp.MustCompile(`(?m)^[ \t]*(?i:project)\s*\([^)]*?\sVERSION\s+"?(?P<version>[0-9][0-9.]*)"?`)
+
+// CMakeUpdater handles project(Foo VERSION 1.2.3) in CMakeLists.txt. The key
+// is ignored. CMake only accepts numeric versions, so pre-release and build
+// metadata are dropped when writing. Commented-out project() calls are skipped.
+type CMakeUpdater struct {
+	pattern *PatternUpdater
+}
+
+func NewCMakeUpdater(path string) *CMakeUpdater {
+	return &CMakeUpdater{pattern: NewPatternUpdater(path, func(string) (*regexp.Regexp, error) {
+		return cmakeProjectPattern, nil
+	})}
+}
+
+func (u *CMakeUpdater) GetVersion(keyPath string) (string, error) {
+	return u.pattern.GetVersion(keyPath)
+}
+
+func (u *CMakeUpdater) SetVersion(keyPath, version string) error {
+	return u.pattern.SetVersion(keyPath, toCMakeVersion(version))
+}
+
+func (u *CMakeUpdater) Preview(keyPath, version string) ([]byte, error) {
+	return u.pattern.Preview(keyPath, toCMakeVersion(version))
+}
+
+// toCMakeVersion reduces 1.2.3-rc.1+build to 1.2.3.
+func toCMakeVersion(version string) string {
+	core := strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(core, "-+"); i >= 0 {
+		core = core[:i]
+	}
+	return core
+}
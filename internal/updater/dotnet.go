@@ -0,0 +1,121 @@
+package updater
+
+import (
+	"regexp"
+	"strings"
+)
+
+// fourPartKeys are .NET version properties that only accept major.minor.build.revision.
+var fourPartKeys = map[string]bool{
+	"AssemblyVersion":     true,
+	"AssemblyFileVersion": true,
+	"FileVersion":         true,
+}
+
+// AssemblyInfoUpdater handles [assembly: AssemblyVersion("1.2.3.0")] attributes
+// in AssemblyInfo.cs. The key is the attribute name; "version" means AssemblyVersion.
+// Attributes commented out with // are skipped.
+type AssemblyInfoUpdater struct {
+	pattern *PatternUpdater
+}
+
+func NewAssemblyInfoUpdater(path string) *AssemblyInfoUpdater {
+	pattern := NewPatternUpdater(path, assemblyAttributePattern)
+	pattern.comments = []string{"//"}
+	return &AssemblyInfoUpdater{pattern: pattern}
+}
+
+func (u *AssemblyInfoUpdater) GetVersion(keyPath string) (string, error) {
+	keyPath = assemblyAttribute(keyPath)
+	version, err := u.pattern.GetVersion(keyPath)
+	if err != nil {
+		return "", err
+	}
+	return fromDotNetVersion(keyPath, version), nil
+}
+
+func (u *AssemblyInfoUpdater) SetVersion(keyPath, version string) error {
+	keyPath = assemblyAttribute(keyPath)
+	return u.pattern.SetVersion(keyPath, toDotNetVersion(keyPath, version))
+}
+
+func (u *AssemblyInfoUpdater) Preview(keyPath, version string) ([]byte, error) {
+	keyPath = assemblyAttribute(keyPath)
+	return u.pattern.Preview(keyPath, toDotNetVersion(keyPath, version))
+}
+
+func assemblyAttribute(keyPath string) string {
+	if keyPath == "" || keyPath == "version" {
+		return "AssemblyVersion"
+	}
+	return keyPath
+}
+
+func assemblyAttributePattern(keyPath string) (*regexp.Regexp, error) {
+	name := regexp.QuoteMeta(strings.TrimSuffix(keyPath, "Attribute"))
+	return regexp.Compile(`\[\s*assembly\s*:\s*(?:System\.Reflection\.)?` + name + `(?:Attribute)?\s*\(\s*"(?P<version>[^"]*)"\s*\)\s*\]`)
+}
+
+// MSBuildUpdater handles MSBuild XML files such as Directory.Build.props and
+// .csproj. The key is the element path, e.g. "Project.PropertyGroup.Version".
+type MSBuildUpdater struct {
+	xml *XMLUpdater
+}
+
+func NewMSBuildUpdater(path string) *MSBuildUpdater {
+	return &MSBuildUpdater{xml: NewXMLUpdater(path)}
+}
+
+func (u *MSBuildUpdater) GetVersion(keyPath string) (string, error) {
+	version, err := u.xml.GetVersion(keyPath)
+	if err != nil {
+		return "", err
+	}
+	return fromDotNetVersion(lastKey(keyPath), version), nil
+}
+
+func (u *MSBuildUpdater) SetVersion(keyPath, version string) error {
+	return u.xml.SetVersion(keyPath, toDotNetVersion(lastKey(keyPath), version))
+}
+
+func (u *MSBuildUpdater) Preview(keyPath, version string) ([]byte, error) {
+	return u.xml.Preview(keyPath, toDotNetVersion(lastKey(keyPath), version))
+}
+
+func lastKey(keyPath string) string {
+	return keyPath[strings.LastIndex(keyPath, ".")+1:]
+}
+
+// toDotNetVersion maps a semantic version to the four-part form required by
+// key, dropping pre-release and build metadata: 1.2.3-rc.1 becomes 1.2.3.0.
+func toDotNetVersion(key, version string) string {
+	if !fourPartKeys[key] {
+		return version
+	}
+
+	core := strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(core, "-+"); i >= 0 {
+		core = core[:i]
+	}
+
+	parts := strings.Split(core, ".")
+	for len(parts) < 4 {
+		parts = append(parts, "0")
+	}
+
+	return strings.Join(parts[:4], ".")
+}
+
+// fromDotNetVersion reduces a four-part version to major.minor.patch.
+func fromDotNetVersion(key, version string) string {
+	if !fourPartKeys[key] {
+		return version
+	}
+
+	parts := strings.Split(version, ".")
+	if len(parts) > 3 {
+		parts = parts[:3]
+	}
+
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,361 @@
+package updater
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUpdaters(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		updater func(path string) Updater
+		content string
+		key     string
+		version string
+		set     string
+		want    string
+		// reread is what GetVersion returns after SetVersion, set when not set
+		reread string
+	}{
+		{
+			name:    "json",
+			file:    "package.json",
+			content: "{\n  \"name\": \"app\",\n  \"version\": \"1.2.3\",\n  \"private\": true\n}\n",
+			key:     "version",
+			version: "1.2.3",
+			set:     "1.3.0",
+			want:    "{\n  \"name\": \"app\",\n  \"version\": \"1.3.0\",\n  \"private\": true\n}\n",
+		},
+		{
+			name:    "json nested",
+			file:    "app.json",
+			content: "{\"expo\": {\"version\": \"1.2.3\"}}\n",
+			key:     "expo.version",
+			version: "1.2.3",
+			set:     "2.0.0-rc.1",
+			want:    "{\"expo\": {\"version\": \"2.0.0-rc.1\"}}\n",
+		},
+		{
+			name:    "jsonc",
+			file:    "deno.jsonc",
+			content: "{\n  // the version\n  \"version\": \"1.2.3\", /* released */\n  \"tasks\": {},\n}\n",
+			key:     "version",
+			version: "1.2.3",
+			set:     "1.3.0",
+			want:    "{\n  // the version\n  \"version\": \"1.3.0\", /* released */\n  \"tasks\": {},\n}\n",
+		},
+		{
+			name:    "yaml",
+			file:    "pubspec.yaml",
+			content: "# app\nname: app\nversion: 1.2.3 # bumped by commet\nflutter:\n  uses-material-design: true\n",
+			key:     "version",
+			version: "1.2.3",
+			set:     "1.3.0",
+			want:    "# app\nname: app\nversion: 1.3.0 # bumped by commet\nflutter:\n  uses-material-design: true\n",
+		},
+		{
+			name:    "yaml quoted",
+			file:    "Chart.yaml",
+			content: "apiVersion: v2\nappVersion: \"1.2.3\"\n",
+			key:     "appVersion",
+			version: "1.2.3",
+			set:     "1.3.0",
+			want:    "apiVersion: v2\nappVersion: \"1.3.0\"\n",
+		},
+		{
+			name:    "toml",
+			file:    "Cargo.toml",
+			content: "[package]\nname = \"app\"\n# version = \"0.0.0\"\nversion = \"1.2.3\" # keep in sync\n\n[dependencies]\nserde = { version = \"1.0\" }\n",
+			key:     "package.version",
+			version: "1.2.3",
+			set:     "1.3.0",
+			want:    "[package]\nname = \"app\"\n# version = \"0.0.0\"\nversion = \"1.3.0\" # keep in sync\n\n[dependencies]\nserde = { version = \"1.0\" }\n",
+		},
+		{
+			name:    "pyproject",
+			file:    "pyproject.toml",
+			content: "[project]\nname = \"app\"\nversion = '1.2.3'\n",
+			key:     "project.version",
+			version: "1.2.3",
+			set:     "1.3.0",
+			want:    "[project]\nname = \"app\"\nversion = '1.3.0'\n",
+		},
+		{
+			name:    "cargo lock",
+			file:    "Cargo.lock",
+			updater: func(path string) Updater { return NewCargoLockUpdater(path, "app") },
+			content: "version = 3\n\n[[package]]\nname = \"anyhow\"\nversion = \"1.0.0\"\n\n[[package]]\nname = \"app\"\nversion = \"1.2.3\"\ndependencies = [\n \"anyhow\",\n]\n",
+			version: "1.2.3",
+			set:     "1.3.0",
+			want:    "version = 3\n\n[[package]]\nname = \"anyhow\"\nversion = \"1.0.0\"\n\n[[package]]\nname = \"app\"\nversion = \"1.3.0\"\ndependencies = [\n \"anyhow\",\n]\n",
+		},
+		{
+			name:    "plain",
+			file:    "VERSION",
+			content: "1.2.3\n",
+			version: "1.2.3",
+			set:     "1.3.0",
+			want:    "1.3.0\n",
+		},
+		{
+			name:    "setup.py",
+			file:    "setup.py",
+			content: "from setuptools import setup\n\nsetup(\n    name=\"app\",\n    version=\"1.2.3\",  # released\n)\n",
+			key:     "version",
+			version: "1.2.3",
+			set:     "1.3.0",
+			want:    "from setuptools import setup\n\nsetup(\n    name=\"app\",\n    version=\"1.3.0\",  # released\n)\n",
+		},
+		{
+			name:    "python module",
+			file:    "__init__.py",
+			content: "\"\"\"App.\"\"\"\n\n__version__ = '1.2.3'\n",
+			key:     "__version__",
+			version: "1.2.3",
+			set:     "1.3.0rc1",
+			want:    "\"\"\"App.\"\"\"\n\n__version__ = '1.3.0rc1'\n",
+		},
+		{
+			name:    "setup.cfg",
+			file:    "setup.cfg",
+			content: "[metadata]\nname = app\nversion = 1.2.3\n\n[options]\npackages = find:\n",
+			key:     "version",
+			version: "1.2.3",
+			set:     "1.3.0",
+			want:    "[metadata]\nname = app\nversion = 1.3.0\n\n[options]\npackages = find:\n",
+		},
+		{
+			name:    "nix",
+			file:    "default.nix",
+			content: "{ stdenv }:\nstdenv.mkDerivation {\n  pname = \"app\";\n  version = \"1.2.3\";\n}\n",
+			key:     "version",
+			version: "1.2.3",
+			set:     "1.3.0",
+			want:    "{ stdenv }:\nstdenv.mkDerivation {\n  pname = \"app\";\n  version = \"1.3.0\";\n}\n",
+		},
+		{
+			name:    "lua",
+			file:    "app.lua",
+			content: "local M = {}\n-- bumped on release\nM._VERSION = \"1.2.3\"\nreturn M\n",
+			key:     "M._VERSION",
+			version: "1.2.3",
+			set:     "1.3.0",
+			want:    "local M = {}\n-- bumped on release\nM._VERSION = \"1.3.0\"\nreturn M\n",
+		},
+		{
+			name:    "starlark",
+			file:    "MODULE.bazel",
+			content: "module(\n    name = \"app\",\n    version = \"1.2.3\",\n)\n",
+			key:     "version",
+			version: "1.2.3",
+			set:     "1.3.0",
+			want:    "module(\n    name = \"app\",\n    version = \"1.3.0\",\n)\n",
+		},
+		{
+			name:    "rockspec",
+			file:    "app-1.2.3-1.rockspec",
+			content: "package = \"app\"\nversion = \"1.2.3-1\"\nsource = { tag = \"v1.2.3\" }\n",
+			key:     "version",
+			version: "1.2.3",
+			set:     "1.3.0",
+			want:    "package = \"app\"\nversion = \"1.3.0-1\"\nsource = { tag = \"v1.2.3\" }\n",
+		},
+		{
+			name:    "cmake",
+			file:    "CMakeLists.txt",
+			content: "cmake_minimum_required(VERSION 3.20)\n# project(app VERSION 0.0.1)\nproject(app\n  VERSION 1.2.3\n  LANGUAGES CXX)\n",
+			version: "1.2.3",
+			set:     "1.3.0-rc.1",
+			want:    "cmake_minimum_required(VERSION 3.20)\n# project(app VERSION 0.0.1)\nproject(app\n  VERSION 1.3.0\n  LANGUAGES CXX)\n",
+			reread:  "1.3.0",
+		},
+		{
+			name:    "sbt",
+			file:    "build.sbt",
+			content: "// version := \"0.0.1\"\nThisBuild / version := \"1.2.3\"\nThisBuild / scalaVersion := \"3.3.1\"\n",
+			key:     "version",
+			version: "1.2.3",
+			set:     "1.3.0",
+			want:    "// version := \"0.0.1\"\nThisBuild / version := \"1.3.0\"\nThisBuild / scalaVersion := \"3.3.1\"\n",
+		},
+		{
+			name:    "marker",
+			file:    "Makefile",
+			updater: func(path string) Updater { return NewMarkerUpdater(path, "") },
+			content: "# commet:version\nAPP_VERSION ?= v1.2.3 # default\n",
+			version: "1.2.3",
+			set:     "1.3.0",
+			want:    "# commet:version\nAPP_VERSION ?= v1.3.0 # default\n",
+		},
+		{
+			name:    "assembly info",
+			file:    "AssemblyInfo.cs",
+			content: "using System.Reflection;\n// [assembly: AssemblyVersion(\"0.0.0.0\")]\n[assembly: AssemblyVersion(\"1.2.3.0\")]\n[assembly: AssemblyInformationalVersion(\"1.2.3\")]\n",
+			key:     "version",
+			version: "1.2.3",
+			set:     "1.3.0-rc.1",
+			want:    "using System.Reflection;\n// [assembly: AssemblyVersion(\"0.0.0.0\")]\n[assembly: AssemblyVersion(\"1.3.0.0\")]\n[assembly: AssemblyInformationalVersion(\"1.2.3\")]\n",
+			reread:  "1.3.0",
+		},
+		{
+			name:    "msbuild",
+			file:    "Directory.Build.props",
+			content: "<Project>\n  <!-- shared -->\n  <PropertyGroup>\n    <Version>1.2.3</Version>\n  </PropertyGroup>\n</Project>\n",
+			key:     "Project.PropertyGroup.Version",
+			version: "1.2.3",
+			set:     "1.3.0",
+			want:    "<Project>\n  <!-- shared -->\n  <PropertyGroup>\n    <Version>1.3.0</Version>\n  </PropertyGroup>\n</Project>\n",
+		},
+		{
+			name:    "nuspec",
+			file:    "app.nuspec",
+			content: "<?xml version=\"1.0\"?>\n<package>\n  <metadata>\n    <id>App</id>\n    <version>1.2.3</version>\n  </metadata>\n</package>\n",
+			key:     "package.metadata.version",
+			version: "1.2.3",
+			set:     "1.3.0",
+			want:    "<?xml version=\"1.0\"?>\n<package>\n  <metadata>\n    <id>App</id>\n    <version>1.3.0</version>\n  </metadata>\n</package>\n",
+		},
+		{
+			name:    "wordpress plugin",
+			file:    "app.php",
+			content: "<?php\n/**\n * Plugin Name: App\n * Version: 1.2.3\n * Requires PHP: 7.4\n */\n",
+			key:     "version",
+			version: "1.2.3",
+			set:     "1.3.0",
+			want:    "<?php\n/**\n * Plugin Name: App\n * Version: 1.3.0\n * Requires PHP: 7.4\n */\n",
+		},
+		{
+			name:    "wordpress theme",
+			file:    "style.css",
+			content: "/*\nTheme Name: App\nVersion: 1.2.3\n*/\nbody { margin: 0; }\n",
+			key:     "version",
+			version: "1.2.3",
+			set:     "1.3.0",
+			want:    "/*\nTheme Name: App\nVersion: 1.3.0\n*/\nbody { margin: 0; }\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTestFile(t, tt.file, tt.content)
+			u := newTestUpdater(t, path, tt.updater)
+
+			if got, err := u.GetVersion(tt.key); err != nil || got != tt.version {
+				t.Fatalf("GetVersion(%q) = %q, %v; want %q", tt.key, got, err, tt.version)
+			}
+
+			preview, err := u.Preview(tt.key, tt.set)
+			if err != nil {
+				t.Fatalf("Preview(%q, %q): %v", tt.key, tt.set, err)
+			}
+			if string(preview) != tt.want {
+				t.Errorf("Preview(%q, %q) =\n%s\nwant\n%s", tt.key, tt.set, preview, tt.want)
+			}
+			if content := readTestFile(t, path); content != tt.content {
+				t.Errorf("Preview changed the file:\n%s", content)
+			}
+
+			if err := u.SetVersion(tt.key, tt.set); err != nil {
+				t.Fatalf("SetVersion(%q, %q): %v", tt.key, tt.set, err)
+			}
+			if content := readTestFile(t, path); content != tt.want {
+				t.Errorf("SetVersion(%q, %q) wrote\n%s\nwant\n%s", tt.key, tt.set, content, tt.want)
+			}
+
+			reread := tt.reread
+			if reread == "" {
+				reread = tt.set
+			}
+			if got, err := u.GetVersion(tt.key); err != nil || got != reread {
+				t.Errorf("GetVersion(%q) after SetVersion = %q, %v; want %q", tt.key, got, err, reread)
+			}
+		})
+	}
+}
+
+func TestUpdatersMissingVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		updater func(path string) Updater
+		content string
+		key     string
+		// writes is set for formats whose Preview sets the key regardless
+		writes bool
+	}{
+		{"json missing key", "package.json", nil, "{\"name\": \"app\"}\n", "version", true},
+		{"json number", "package.json", nil, "{\"version\": 1}\n", "version", true},
+		{"jsonc missing key", "deno.jsonc", nil, "{\n  // \"version\": \"1.2.3\"\n}\n", "version", false},
+		{"yaml mapping", "pubspec.yaml", nil, "version:\n  major: 1\n", "version", false},
+		{"toml other table", "Cargo.toml", nil, "[workspace]\nversion = \"1.2.3\"\n", "package.version", false},
+		{"toml commented", "Cargo.toml", nil, "[package]\n# version = \"1.2.3\"\n", "package.version", false},
+		{"cargo lock other crate", "Cargo.lock", func(path string) Updater { return NewCargoLockUpdater(path, "app") }, "[[package]]\nname = \"other\"\nversion = \"1.2.3\"\n", "", false},
+		{"plain empty", "VERSION", nil, "\n", "", true},
+		{"python commented", "setup.py", nil, "setup(\n    # version=\"1.2.3\",\n)\n", "version", false},
+		{"setup.cfg other section", "setup.cfg", nil, "[options]\nversion = 1.2.3\n", "version", false},
+		{"setup.cfg missing module", "setup.cfg", nil, "[metadata]\nversion = attr: missing.__version__\n", "version", false},
+		{"lua commented", "app.lua", nil, "-- version = \"1.2.3\"\n", "version", false},
+		{"rockspec missing", "app-dev-1.rockspec", nil, "package = \"app\"\n", "version", false},
+		{"cmake commented", "CMakeLists.txt", nil, "# project(app VERSION 1.2.3)\nproject(app)\n", "", false},
+		{"sbt commented", "build.sbt", nil, "// version := \"1.2.3\"\n", "version", false},
+		{"marker missing", "Makefile", func(path string) Updater { return NewMarkerUpdater(path, "") }, "APP_VERSION ?= 1.2.3\n", "", false},
+		{"assembly info commented", "AssemblyInfo.cs", nil, "// [assembly: AssemblyVersion(\"1.2.3.0\")]\n", "version", false},
+		{"msbuild missing", "app.csproj", nil, "<Project>\n  <PropertyGroup />\n</Project>\n", "Project.PropertyGroup.Version", false},
+		{"nuspec missing", "app.nuspec", nil, "<package>\n  <metadata />\n</package>\n", "package.metadata.version", false},
+		{"wordpress missing", "app.php", nil, "<?php\n/**\n * Plugin Name: App\n */\n", "version", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTestFile(t, tt.file, tt.content)
+			u := newTestUpdater(t, path, tt.updater)
+
+			if got, err := u.GetVersion(tt.key); err == nil {
+				t.Errorf("GetVersion(%q) = %q, want an error", tt.key, got)
+			}
+			if _, err := u.Preview(tt.key, "1.3.0"); err == nil && !tt.writes {
+				t.Errorf("Preview(%q) found a version to replace", tt.key)
+			}
+			if content := readTestFile(t, path); content != tt.content {
+				t.Errorf("the file changed:\n%s", content)
+			}
+		})
+	}
+}
+
+// writeTestFile writes content to name in a new temporary directory.
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+// newTestUpdater returns newUpdater(path), or the updater New picks for path.
+func newTestUpdater(t *testing.T, path string, newUpdater func(string) Updater) Updater {
+	t.Helper()
+
+	if newUpdater != nil {
+		return newUpdater(path)
+	}
+	u, err := New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
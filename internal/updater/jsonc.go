@@ -0,0 +1,174 @@
+package updater
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/yendefrr/commet/internal/fsutil"
+
+	"github.com/tidwall/gjson"
+)
+
+// JSONCUpdater handles JSON with comments and trailing commas (deno.jsonc,
+// VS Code settings, tsconfig-style manifests). Comments are blanked out only
+// to locate the key; the value is then replaced in the original text, so
+// comments and formatting are written back untouched.
+type JSONCUpdater struct {
+	filePath string
+}
+
+func NewJSONCUpdater(path string) *JSONCUpdater {
+	return &JSONCUpdater{filePath: path}
+}
+
+func (u *JSONCUpdater) GetVersion(keyPath string) (string, error) {
+	content, err := os.ReadFile(u.filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	result, err := u.lookup(content, keyPath)
+	if err != nil {
+		return "", err
+	}
+
+	return result.String(), nil
+}
+
+func (u *JSONCUpdater) SetVersion(keyPath, version string) error {
+	updated, err := u.Preview(keyPath, version)
+	if err != nil {
+		return err
+	}
+
+	if err := fsutil.WriteFile(u.filePath, updated); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
+
+func (u *JSONCUpdater) Preview(keyPath, version string) ([]byte, error) {
+	content, err := os.ReadFile(u.filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	result, err := u.lookup(content, keyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	quoted, err := json.Marshal(version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode version: %w", err)
+	}
+
+	updated := make([]byte, 0, len(content)+len(quoted))
+	updated = append(updated, content[:result.Index]...)
+	updated = append(updated, quoted...)
+	updated = append(updated, content[result.Index+len(result.Raw):]...)
+
+	return updated, nil
+}
+
+func (u *JSONCUpdater) lookup(content []byte, keyPath string) (gjson.Result, error) {
+	clean := stripJSONC(content)
+	if !gjson.ValidBytes(clean) {
+		return gjson.Result{}, fmt.Errorf("failed to parse JSONC")
+	}
+
+	result := gjson.GetBytes(clean, keyPath)
+	if !result.Exists() {
+		return gjson.Result{}, fmt.Errorf("version key '%s' not found", keyPath)
+	}
+
+	if result.Type != gjson.String {
+		return gjson.Result{}, fmt.Errorf("version key '%s' is not a string", keyPath)
+	}
+
+	if result.Index <= 0 {
+		return gjson.Result{}, fmt.Errorf("version key '%s' could not be located", keyPath)
+	}
+
+	return result, nil
+}
+
+// stripJSONC returns a copy of content where comments and trailing commas are
+// replaced by spaces. Byte offsets are unchanged so they map back to content.
+func stripJSONC(content []byte) []byte {
+	out := make([]byte, len(content))
+	copy(out, content)
+
+	inString := false
+	for i := 0; i < len(out); i++ {
+		c := out[i]
+
+		if inString {
+			switch c {
+			case '\\':
+				i++
+			case '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch {
+		case c == '"':
+			inString = true
+		case c == '/' && i+1 < len(out) && out[i+1] == '/':
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				out[i] = ' '
+			}
+		case c == '/' && i+1 < len(out) && out[i+1] == '*':
+			out[i], out[i+1] = ' ', ' '
+			for i += 2; i < len(out); i++ {
+				if out[i] == '*' && i+1 < len(out) && out[i+1] == '/' {
+					out[i], out[i+1] = ' ', ' '
+					i++
+					break
+				}
+				if out[i] != '\n' {
+					out[i] = ' '
+				}
+			}
+		}
+	}
+
+	// Trailing commas, now that comments are gone
+	inString = false
+	lastComma := -1
+	for i := 0; i < len(out); i++ {
+		c := out[i]
+
+		if inString {
+			switch c {
+			case '\\':
+				i++
+			case '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+			lastComma = -1
+		case ',':
+			lastComma = i
+		case '}', ']':
+			if lastComma >= 0 {
+				out[lastComma] = ' '
+			}
+			lastComma = -1
+		case ' ', '\t', '\r', '\n':
+		default:
+			lastComma = -1
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,30 @@
+package updater
+
+import (
+	"regexp"
+)
+
+// DefaultMarker is the marker comment used when none is configured.
+const DefaultMarker = "commet:version"
+
+// NewMarkerUpdater handles any text file by replacing the version on the
+// line following a marker comment, e.g.
+//
+//	# commet:version
+//	APP_VERSION=1.2.3
+//
+// The marker may sit in any comment syntax (#, //, --, <!-- -->). Only the
+// first version-looking token on the next line is replaced, so a "v" prefix
+// and the rest of the line are kept. The key is ignored.
+func NewMarkerUpdater(path, marker string) *PatternUpdater {
+	if marker == "" {
+		marker = DefaultMarker
+	}
+
+	pattern := regexp.MustCompile(`(?m)^[^\r\n]*` + regexp.QuoteMeta(marker) + `[^\r\n]*\r?\n[^\r\n]*?` +
+		`(?P<version>\d+\.\d+(?:\.\d+)*(?:-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?)`)
+
+	return NewPatternUpdater(path, func(string) (*regexp.Regexp, error) {
+		return pattern, nil
+	})
+}
@@ -0,0 +1,121 @@
+package updater
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/yendefrr/commet/internal/fsutil"
+)
+
+// PatternUpdater edits the first match of a regular expression in a text
+// file. The pattern must have a group named "version"; only that group is
+// replaced, so the surrounding text keeps its formatting.
+type PatternUpdater struct {
+	filePath string
+	pattern  func(keyPath string) (*regexp.Regexp, error)
+	// comments start line comments; matches on such lines are skipped
+	comments []string
+}
+
+// NewAssignmentUpdater handles `<key> = "1.2.3"` string assignments, as found
+// in Nix, Lua, Starlark (BUILD, .bzl) and Python sources. The key is the
+// assigned name and the first assignment wins; commented-out lines starting
+// with #, -- or // are skipped.
+func NewAssignmentUpdater(path string) *PatternUpdater {
+	u := NewPatternUpdater(path, assignmentPattern)
+	u.comments = []string{"#", "--", "//"}
+	return u
+}
+
+func assignmentPattern(keyPath string) (*regexp.Regexp, error) {
+	name := regexp.QuoteMeta(keyPath)
+	return regexp.Compile(`(?m)(?:^|[\s({;,])` + name + `\s*=\s*["'](?P<version>[^"'\r\n]*)["']`)
+}
+
+// NewPatternUpdater creates an updater whose pattern is built from the key path.
+func NewPatternUpdater(path string, pattern func(keyPath string) (*regexp.Regexp, error)) *PatternUpdater {
+	return &PatternUpdater{filePath: path, pattern: pattern}
+}
+
+func (u *PatternUpdater) GetVersion(keyPath string) (string, error) {
+	content, err := os.ReadFile(u.filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	start, end, err := u.locate(content, keyPath)
+	if err != nil {
+		return "", err
+	}
+
+	return string(content[start:end]), nil
+}
+
+func (u *PatternUpdater) SetVersion(keyPath, version string) error {
+	updated, err := u.Preview(keyPath, version)
+	if err != nil {
+		return err
+	}
+
+	if err := fsutil.WriteFile(u.filePath, updated); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
+
+func (u *PatternUpdater) Preview(keyPath, version string) ([]byte, error) {
+	content, err := os.ReadFile(u.filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	start, end, err := u.locate(content, keyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := make([]byte, 0, len(content)+len(version))
+	updated = append(updated, content[:start]...)
+	updated = append(updated, version...)
+	updated = append(updated, content[end:]...)
+
+	return updated, nil
+}
+
+// locate returns the byte range of the "version" group in content.
+func (u *PatternUpdater) locate(content []byte, keyPath string) (int, int, error) {
+	pattern, err := u.pattern(keyPath)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	group := pattern.SubexpIndex("version")
+	if group < 0 {
+		return 0, 0, fmt.Errorf("pattern %s has no version group", pattern)
+	}
+
+	for _, match := range pattern.FindAllSubmatchIndex(content, -1) {
+		start, end := match[2*group], match[2*group+1]
+		if start >= 0 && !u.commented(content, start) {
+			return start, end, nil
+		}
+	}
+
+	return 0, 0, fmt.Errorf("version key '%s' not found", keyPath)
+}
+
+// commented reports whether the line holding offset pos of content starts
+// with one of the comment prefixes of u.
+func (u *PatternUpdater) commented(content []byte, pos int) bool {
+	lineStart := bytes.LastIndexByte(content[:pos], '\n') + 1
+	line := bytes.TrimLeft(content[lineStart:pos], " \t")
+	for _, prefix := range u.comments {
+		if bytes.HasPrefix(line, []byte(prefix)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package updater
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/yendefrr/commet/internal/fsutil"
+)
+
+// PlainUpdater handles VERSION files, whose whole content is the version.
+// The key path is ignored.
+type PlainUpdater struct {
+	filePath string
+}
+
+func NewPlainUpdater(path string) *PlainUpdater {
+	return &PlainUpdater{filePath: path}
+}
+
+func (u *PlainUpdater) GetVersion(keyPath string) (string, error) {
+	content, err := os.ReadFile(u.filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	version := strings.TrimSpace(string(content))
+	if version == "" {
+		return "", fmt.Errorf("version file is empty")
+	}
+
+	return version, nil
+}
+
+func (u *PlainUpdater) SetVersion(keyPath, version string) error {
+	updated, err := u.Preview(keyPath, version)
+	if err != nil {
+		return err
+	}
+
+	if err := fsutil.WriteFile(u.filePath, updated); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
+
+func (u *PlainUpdater) Preview(keyPath, version string) ([]byte, error) {
+	if _, err := os.Stat(u.filePath); err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return []byte(version + "\n"), nil
+}
+
+func (u *PlainUpdater) Create(keyPath, version string) error {
+	if err := fsutil.WriteFile(u.filePath, []byte(version+"\n")); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package updater
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// NewPythonUpdater handles string assignments in Python sources. The key is
+// the assigned name: "version" matches setup(version="1.2.3") in setup.py,
+// "__version__" matches a module-level __version__ = "1.2.3".
+func NewPythonUpdater(path string) *PatternUpdater {
+	return NewAssignmentUpdater(path)
+}
+
+// SetupCfgUpdater handles setup.cfg. The key is "section.option" and defaults
+// to the metadata section. Dynamic versions declared with "attr: pkg.__version__"
+// or "file: VERSION" are followed to the file that actually holds the version.
+type SetupCfgUpdater struct {
+	filePath string
+}
+
+func NewSetupCfgUpdater(path string) *SetupCfgUpdater {
+	return &SetupCfgUpdater{filePath: path}
+}
+
+func (u *SetupCfgUpdater) GetVersion(keyPath string) (string, error) {
+	target, targetKey, _, err := u.resolve(keyPath)
+	if err != nil {
+		return "", err
+	}
+	return target.GetVersion(targetKey)
+}
+
+func (u *SetupCfgUpdater) SetVersion(keyPath, version string) error {
+	target, targetKey, _, err := u.resolve(keyPath)
+	if err != nil {
+		return err
+	}
+	return target.SetVersion(targetKey, version)
+}
+
+func (u *SetupCfgUpdater) Preview(keyPath, version string) ([]byte, error) {
+	target, targetKey, _, err := u.resolve(keyPath)
+	if err != nil {
+		return nil, err
+	}
+	return target.Preview(targetKey, version)
+}
+
+// TargetFile returns the file that holds the version, which differs from
+// setup.cfg for dynamic versions.
+func (u *SetupCfgUpdater) TargetFile(keyPath string) (string, error) {
+	_, _, path, err := u.resolve(keyPath)
+	return path, err
+}
+
+// resolve returns the updater, key and file that hold the version for keyPath.
+func (u *SetupCfgUpdater) resolve(keyPath string) (Updater, string, string, error) {
+	option := NewPatternUpdater(u.filePath, iniOptionPattern)
+
+	value, err := option.GetVersion(keyPath)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	dir := filepath.Dir(u.filePath)
+
+	switch {
+	case strings.HasPrefix(value, "attr:"):
+		ref := strings.TrimSpace(strings.TrimPrefix(value, "attr:"))
+		dot := strings.LastIndex(ref, ".")
+		if dot < 0 {
+			return nil, "", "", fmt.Errorf("invalid attr reference '%s'", ref)
+		}
+
+		module, name := ref[:dot], ref[dot+1:]
+		modulePath, err := findPythonModule(dir, module)
+		if err != nil {
+			return nil, "", "", err
+		}
+
+		return NewPythonUpdater(modulePath), name, modulePath, nil
+
+	case strings.HasPrefix(value, "file:"):
+		file := strings.TrimSpace(strings.TrimPrefix(value, "file:"))
+		if strings.Contains(file, ",") {
+			return nil, "", "", fmt.Errorf("version from multiple files is not supported: %s", file)
+		}
+
+		path := filepath.Join(dir, file)
+		return NewPlainUpdater(path), keyPath, path, nil
+	}
+
+	return option, keyPath, u.filePath, nil
+}
+
+func iniOptionPattern(keyPath string) (*regexp.Regexp, error) {
+	section, option := "metadata", keyPath
+	if i := strings.LastIndex(keyPath, "."); i >= 0 {
+		section, option = keyPath[:i], keyPath[i+1:]
+	}
+
+	return regexp.Compile(`(?ms)^\[` + regexp.QuoteMeta(section) + `\][^\[]*?^` +
+		regexp.QuoteMeta(option) + `[ \t]*[=:][ \t]*(?P<version>[^\r\n]*?)[ \t]*$`)
+}
+
+// findPythonModule locates the source file of a dotted module name relative to dir.
+func findPythonModule(dir, module string) (string, error) {
+	rel := filepath.Join(strings.Split(module, ".")...)
+
+	candidates := []string{
+		filepath.Join(dir, rel+".py"),
+		filepath.Join(dir, rel, "__init__.py"),
+		filepath.Join(dir, "src", rel+".py"),
+		filepath.Join(dir, "src", rel, "__init__.py"),
+	}
+
+	for _, candidate := range candidates {
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+	}
+
+	return "", fmt.Errorf("module '%s' not found (tried %s)", module, strings.Join(candidates, ", "))
+}
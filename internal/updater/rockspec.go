@@ -0,0 +1,75 @@
+package updater
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RockspecUpdater handles LuaRocks specs, whose version carries a rockspec
+// revision (version = "1.2.3-1"). A new upstream version starts again at
+// revision 1; rewriting the same version keeps the revision.
+type RockspecUpdater struct {
+	assignment *PatternUpdater
+}
+
+func NewRockspecUpdater(path string) *RockspecUpdater {
+	return &RockspecUpdater{assignment: NewAssignmentUpdater(path)}
+}
+
+func (u *RockspecUpdater) GetVersion(keyPath string) (string, error) {
+	version, err := u.assignment.GetVersion(keyPath)
+	if err != nil {
+		return "", err
+	}
+
+	upstream, _ := splitRockspecRevision(version)
+	return upstream, nil
+}
+
+func (u *RockspecUpdater) SetVersion(keyPath, version string) error {
+	full, err := u.withRevision(keyPath, version)
+	if err != nil {
+		return err
+	}
+	return u.assignment.SetVersion(keyPath, full)
+}
+
+func (u *RockspecUpdater) Preview(keyPath, version string) ([]byte, error) {
+	full, err := u.withRevision(keyPath, version)
+	if err != nil {
+		return nil, err
+	}
+	return u.assignment.Preview(keyPath, full)
+}
+
+func (u *RockspecUpdater) withRevision(keyPath, version string) (string, error) {
+	current, err := u.assignment.GetVersion(keyPath)
+	if err != nil {
+		return "", err
+	}
+
+	version = strings.TrimPrefix(version, "v")
+	upstream, revision := splitRockspecRevision(current)
+	if revision == "" || upstream != version {
+		revision = "1"
+	}
+
+	return fmt.Sprintf("%s-%s", version, revision), nil
+}
+
+// splitRockspecRevision splits "1.2.3-1" into "1.2.3" and "1".
+func splitRockspecRevision(version string) (string, string) {
+	i := strings.LastIndex(version, "-")
+	if i < 0 {
+		return version, ""
+	}
+
+	revision := version[i+1:]
+	for _, r := range revision {
+		if r < '0' || r > '9' {
+			return version, ""
+		}
+	}
+
+	return version[:i], revision
+}
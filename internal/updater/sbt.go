@@ -0,0 +1,35 @@
+package updater
+
+import (
+	"regexp"
+	"strings"
+)
+
+// NewSbtUpdater handles `version := "1.2.3"` settings in build.sbt. The key
+// "version" matches both `version := ...` and `ThisBuild / version := ...`;
+// a scoped key such as "ThisBuild / version" matches only that form.
+// Settings in // comments are skipped.
+func NewSbtUpdater(path string) *PatternUpdater {
+	return NewPatternUpdater(path, sbtSettingPattern)
+}
+
+func sbtSettingPattern(keyPath string) (*regexp.Regexp, error) {
+	if keyPath == "" {
+		keyPath = "version"
+	}
+
+	var scope, name string
+	if i := strings.LastIndex(keyPath, "/"); i >= 0 {
+		scope, name = strings.TrimSpace(keyPath[:i]), strings.TrimSpace(keyPath[i+1:])
+	} else {
+		name = strings.TrimSpace(keyPath)
+	}
+
+	prefix := `(?:\w+\s*/\s*)?`
+	if scope != "" {
+		prefix = regexp.QuoteMeta(scope) + `\s*/\s*`
+	}
+
+	return regexp.Compile(`(?m)(?:^|[(,])[ \t]*` + prefix + regexp.QuoteMeta(name) +
+		`\s*:=\s*"(?P<version>[^"\r\n]*)"`)
+}
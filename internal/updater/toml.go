@@ -0,0 +1,82 @@
+package updater
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// NewTOMLUpdater handles quoted string values in TOML files such as
+// Cargo.toml and pyproject.toml. The key is "table.option", e.g.
+// "package.version" or "workspace.package.version"; a key without a dot
+// refers to the top level. Only the value is replaced, so comments and
+// formatting are kept.
+func NewTOMLUpdater(path string) *PatternUpdater {
+	return NewPatternUpdater(path, tomlKeyPattern)
+}
+
+func tomlKeyPattern(keyPath string) (*regexp.Regexp, error) {
+	table, option := "", keyPath
+	if i := strings.LastIndex(keyPath, "."); i >= 0 {
+		table, option = keyPath[:i], keyPath[i+1:]
+	}
+
+	start := `\A`
+	if table != "" {
+		start = `(?m)^\[` + regexp.QuoteMeta(table) + `\][ \t]*(?:#[^\r\n]*)?\r?\n`
+	}
+
+	// Skip the lines of the table up to the option, stopping at the next table header
+	return regexp.Compile(start + `(?m)(?:(?:[^\[\r\n][^\r\n]*)?\r?\n)*?[ \t]*` +
+		regexp.QuoteMeta(option) + `[ \t]*=[ \t]*["'](?P<version>[^"'\r\n]*)["']`)
+}
+
+// CargoLockFile returns the Cargo.lock that belongs to the Cargo.toml at
+// manifest: the one next to it, or the workspace's in a parent directory.
+func CargoLockFile(manifest string) (string, error) {
+	dir, err := filepath.Abs(filepath.Dir(manifest))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", manifest, err)
+	}
+
+	rel := filepath.Dir(manifest)
+	for {
+		candidate := filepath.Join(rel, "Cargo.lock")
+		if _, err := os.Stat(filepath.Join(dir, "Cargo.lock")); err == nil {
+			return candidate, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("Cargo.lock not found for %s", manifest)
+		}
+		dir, rel = parent, filepath.Join(rel, "..")
+	}
+}
+
+// NewCargoLockUpdater edits the [[package]] entry of the named crate in
+// Cargo.lock. The key is ignored.
+func NewCargoLockUpdater(path, crate string) *PatternUpdater {
+	return NewPatternUpdater(path, func(string) (*regexp.Regexp, error) {
+		return regexp.Compile(`(?m)^\[\[package\]\]\r?\nname = "` + regexp.QuoteMeta(crate) +
+			`"\r?\nversion = "(?P<version>[^"\r\n]*)"`)
+	})
+}
+
+// CargoLockFor returns an updater for the root package of the Cargo.toml at
+// manifest in its Cargo.lock, along with the lock file path.
+func CargoLockFor(manifest string) (*PatternUpdater, string, error) {
+	crate, err := NewTOMLUpdater(manifest).GetVersion("package.name")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read package name from %s: %w", manifest, err)
+	}
+
+	lockFile, err := CargoLockFile(manifest)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return NewCargoLockUpdater(lockFile, crate), lockFile, nil
+}
@@ -1,11 +1,15 @@
 package updater
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/yendefrr/commet/internal/fsutil"
+
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 	"gopkg.in/yaml.v3"
@@ -14,20 +18,91 @@ import (
 type Updater interface {
 	GetVersion(keyPath string) (string, error)
 	SetVersion(keyPath, version string) error
+	// Preview returns the file content SetVersion would write, without writing it.
+	Preview(keyPath, version string) ([]byte, error)
+}
+
+// Redirector is implemented by updaters whose version may live in another
+// file than the configured one, so Preview output can be compared to it.
+type Redirector interface {
+	TargetFile(keyPath string) (string, error)
+}
+
+// Creator is implemented by updaters that can write a minimal new file
+// containing only the version.
+type Creator interface {
+	Create(keyPath, version string) error
 }
 
 func New(filePath string) (Updater, error) {
+	switch filepath.Base(filePath) {
+	case "BUILD", "BUCK", "WORKSPACE":
+		return NewAssignmentUpdater(filePath), nil
+	case "CMakeLists.txt":
+		return NewCMakeUpdater(filePath), nil
+	}
+
+	// Only VERSION files are plain: a Makefile or Dockerfile is no version
+	if base := strings.ToUpper(filepath.Base(filePath)); base == "VERSION" || base == "VERSION.TXT" {
+		return NewPlainUpdater(filePath), nil
+	}
+
 	ext := strings.ToLower(filepath.Ext(filePath))
 	switch ext {
 	case ".json":
 		return NewJSONUpdater(filePath), nil
+	case ".jsonc", ".json5":
+		return NewJSONCUpdater(filePath), nil
 	case ".yaml", ".yml":
 		return NewYAMLUpdater(filePath), nil
+	case ".toml":
+		return NewTOMLUpdater(filePath), nil
+	case ".nuspec":
+		return NewXMLUpdater(filePath), nil
+	case ".props", ".csproj":
+		return NewMSBuildUpdater(filePath), nil
+	case ".cs":
+		return NewAssemblyInfoUpdater(filePath), nil
+	case ".py":
+		return NewPythonUpdater(filePath), nil
+	case ".cfg":
+		return NewSetupCfgUpdater(filePath), nil
+	case ".nix", ".lua", ".bzl", ".bazel", ".star":
+		return NewAssignmentUpdater(filePath), nil
+	case ".sbt":
+		return NewSbtUpdater(filePath), nil
+	case ".rockspec":
+		return NewRockspecUpdater(filePath), nil
+	case ".php", ".css":
+		return NewWordPressHeaderUpdater(filePath), nil
+	case "":
+		return nil, fmt.Errorf("unsupported version file %s: only files named VERSION hold just the version, set marker for others", filePath)
 	default:
 		return nil, fmt.Errorf("unsupported file extension: %s", ext)
 	}
 }
 
+// Create writes a minimal version file at filePath for formats that support it.
+func Create(filePath, keyPath, version string) error {
+	u, err := New(filePath)
+	if err != nil {
+		return err
+	}
+
+	creator, ok := u.(Creator)
+	if !ok {
+		return fmt.Errorf("cannot create %s: format does not support creating new files", filePath)
+	}
+
+	if dir := filepath.Dir(filePath); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create directory %s: %w", dir, err)
+		}
+	}
+
+	return creator.Create(keyPath, version)
+}
+
 type JSONUpdater struct {
 	filePath string
 }
@@ -55,9 +130,22 @@ func (u *JSONUpdater) GetVersion(keyPath string) (string, error) {
 }
 
 func (u *JSONUpdater) SetVersion(keyPath, version string) error {
+	updated, err := u.Preview(keyPath, version)
+	if err != nil {
+		return err
+	}
+
+	if err := fsutil.WriteFile(u.filePath, updated); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
+
+func (u *JSONUpdater) Preview(keyPath, version string) ([]byte, error) {
 	content, err := os.ReadFile(u.filePath)
 	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
+		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
 	// Use sjson to set the value while preserving order and formatting
@@ -67,10 +155,24 @@ func (u *JSONUpdater) SetVersion(keyPath, version string) error {
 
 	updated, err := sjson.SetBytesOptions(content, keyPath, version, &opts)
 	if err != nil {
-		return fmt.Errorf("failed to update JSON: %w", err)
+		return nil, fmt.Errorf("failed to update JSON: %w", err)
+	}
+
+	return updated, nil
+}
+
+func (u *JSONUpdater) Create(keyPath, version string) error {
+	data := make(map[string]interface{})
+	if err := setNestedValue(data, strings.Split(keyPath, "."), version); err != nil {
+		return err
+	}
+
+	content, err := json.MarshalIndent(data, "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
 	}
 
-	if err := os.WriteFile(u.filePath, updated, 0644); err != nil {
+	if err := fsutil.WriteFile(u.filePath, append(content, '\n')); err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
@@ -86,25 +188,68 @@ func NewYAMLUpdater(path string) *YAMLUpdater {
 }
 
 func (u *YAMLUpdater) GetVersion(keyPath string) (string, error) {
-	data, err := u.read()
+	content, err := os.ReadFile(u.filePath)
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("failed to read file: %w", err)
 	}
 
-	value := getNestedValue(data, strings.Split(keyPath, "."))
-	if str, ok := value.(string); ok {
-		return str, nil
+	node, err := findYAMLNode(content, keyPath)
+	if err != nil {
+		return "", err
 	}
 
-	return "", fmt.Errorf("version key '%s' not found or not a string", keyPath)
+	return yamlScalarVersion(node, keyPath)
 }
 
 func (u *YAMLUpdater) SetVersion(keyPath, version string) error {
-	data, err := u.read()
+	updated, err := u.Preview(keyPath, version)
 	if err != nil {
 		return err
 	}
 
+	if err := fsutil.WriteFile(u.filePath, updated); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
+
+func (u *YAMLUpdater) Preview(keyPath, version string) ([]byte, error) {
+	content, err := os.ReadFile(u.filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	// Replace the scalar in place so comments, ordering and quoting survive
+	if node, err := findYAMLNode(content, keyPath); err == nil {
+		if _, err := yamlScalarVersion(node, keyPath); err != nil {
+			return nil, err
+		}
+		if updated, ok := spliceYAMLScalar(content, node, version); ok {
+			return updated, nil
+		}
+	}
+
+	// Missing keys are created by re-encoding the whole document
+	data, err := u.read()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setNestedValue(data, strings.Split(keyPath, "."), version); err != nil {
+		return nil, err
+	}
+
+	file, err := yaml.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+
+	return file, nil
+}
+
+func (u *YAMLUpdater) Create(keyPath, version string) error {
+	data := make(map[string]interface{})
 	if err := setNestedValue(data, strings.Split(keyPath, "."), version); err != nil {
 		return err
 	}
@@ -132,27 +277,109 @@ func (u *YAMLUpdater) write(data map[string]interface{}) error {
 		return fmt.Errorf("failed to marshal YAML: %w", err)
 	}
 
-	if err := os.WriteFile(u.filePath, file, 0644); err != nil {
+	if err := fsutil.WriteFile(u.filePath, file); err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
 	return nil
 }
 
-func getNestedValue(data map[string]interface{}, keys []string) interface{} {
-	if len(keys) == 0 {
-		return nil
+// findYAMLNode returns the value node at the dotted keyPath of the first document.
+func findYAMLNode(content []byte, keyPath string) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
 	}
 
-	if len(keys) == 1 {
-		return data[keys[0]]
+	if len(doc.Content) == 0 {
+		return nil, fmt.Errorf("version key '%s' not found", keyPath)
 	}
 
-	if nested, ok := data[keys[0]].(map[string]interface{}); ok {
-		return getNestedValue(nested, keys[1:])
+	node := doc.Content[0]
+	for _, key := range strings.Split(keyPath, ".") {
+		if node.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("version key '%s' not found", keyPath)
+		}
+
+		var next *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				next = node.Content[i+1]
+				break
+			}
+		}
+		if next == nil {
+			return nil, fmt.Errorf("version key '%s' not found", keyPath)
+		}
+		node = next
 	}
 
-	return nil
+	return node, nil
+}
+
+// yamlScalarVersion returns the raw text of a string or numeric scalar, so
+// versions YAML reads as numbers (version: 1.10) keep their exact spelling.
+func yamlScalarVersion(node *yaml.Node, keyPath string) (string, error) {
+	if node.Kind != yaml.ScalarNode {
+		return "", fmt.Errorf("version key '%s' is a %s, expected a string", keyPath, yamlKindName(node.Kind))
+	}
+
+	switch node.ShortTag() {
+	case "!!str", "!!int", "!!float":
+		return node.Value, nil
+	default:
+		return "", fmt.Errorf("version key '%s' is %s (%q), expected a string or number", keyPath, node.ShortTag(), node.Value)
+	}
+}
+
+func yamlKindName(kind yaml.Kind) string {
+	switch kind {
+	case yaml.MappingNode:
+		return "mapping"
+	case yaml.SequenceNode:
+		return "sequence"
+	case yaml.AliasNode:
+		return "alias"
+	default:
+		return "document"
+	}
+}
+
+// spliceYAMLScalar replaces the scalar token of node in content, keeping its
+// quoting style. It reports false when the token cannot be located exactly.
+func spliceYAMLScalar(content []byte, node *yaml.Node, value string) ([]byte, bool) {
+	offset := 0
+	for line := 1; line < node.Line; line++ {
+		next := bytes.IndexByte(content[offset:], '\n')
+		if next < 0 {
+			return nil, false
+		}
+		offset += next + 1
+	}
+	offset += node.Column - 1
+
+	var token, replacement string
+	switch node.Style {
+	case 0:
+		token, replacement = node.Value, value
+	case yaml.DoubleQuotedStyle:
+		token, replacement = `"`+node.Value+`"`, `"`+value+`"`
+	case yaml.SingleQuotedStyle:
+		token, replacement = "'"+node.Value+"'", "'"+value+"'"
+	default:
+		return nil, false
+	}
+
+	if offset+len(token) > len(content) || string(content[offset:offset+len(token)]) != token {
+		return nil, false
+	}
+
+	updated := make([]byte, 0, len(content)+len(replacement))
+	updated = append(updated, content[:offset]...)
+	updated = append(updated, replacement...)
+	updated = append(updated, content[offset+len(token):]...)
+
+	return updated, true
 }
 
 func setNestedValue(data map[string]interface{}, keys []string, value string) error {
@@ -0,0 +1,113 @@
+package updater
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewPlainFiles(t *testing.T) {
+	tests := []struct {
+		file  string
+		plain bool
+	}{
+		{"VERSION", true},
+		{"version", true},
+		{"pkg/VERSION.txt", true},
+		{"Makefile", false},
+		{"Dockerfile", false},
+		{"notes.txt", false},
+		{"requirements.txt", false},
+	}
+
+	for _, tt := range tests {
+		u, err := New(filepath.Join(t.TempDir(), tt.file))
+		if _, plain := u.(*PlainUpdater); plain != tt.plain {
+			t.Errorf("New(%q) = %T, %v; want plain %v", tt.file, u, err, tt.plain)
+		}
+		if !tt.plain && err == nil {
+			t.Errorf("New(%q) accepted a file with no version format", tt.file)
+		}
+	}
+}
+
+func TestAssignmentSkipsComments(t *testing.T) {
+	tests := []struct {
+		file    string
+		key     string
+		content string
+		want    string
+	}{
+		{"flake.nix", "version", "{\n  # version = \"0.0.0\";\n  version = \"1.2.3\";\n}\n", "1.2.3"},
+		{"init.lua", "version", "-- version = \"0.0.0\"\nlocal version = \"1.2.3\"\n", "1.2.3"},
+		{"defs.bzl", "VERSION", "    # VERSION = \"0.0.0\"\nVERSION = \"1.2.3\"\n", "1.2.3"},
+		{"BUILD", "version", "// version = \"0.0.0\"\npkg(version = \"1.2.3\")\n", "1.2.3"},
+		{"setup.py", "version", "setup(\n    # version=\"0.0.0\",\n    version=\"1.2.3\",\n)\n", "1.2.3"},
+		{"default.nix", "version", "{\n  version = \"1.2.3\"; # version = \"0.0.0\";\n}\n", "1.2.3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			u, err := New(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, err := u.GetVersion(tt.key); err != nil || got != tt.want {
+				t.Errorf("GetVersion(%q) = %q, %v; want %q", tt.key, got, err, tt.want)
+			}
+
+			preview, err := u.Preview(tt.key, "2.0.0")
+			if err != nil {
+				t.Fatalf("Preview(%q): %v", tt.key, err)
+			}
+			want := strings.Replace(tt.content, tt.want, "2.0.0", 1)
+			if string(preview) != want {
+				t.Errorf("Preview(%q) =\n%s\nwant\n%s", tt.key, preview, want)
+			}
+		})
+	}
+
+	path := filepath.Join(t.TempDir(), "flake.nix")
+	if err := os.WriteFile(path, []byte("{\n  # version = \"1.2.3\";\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := NewAssignmentUpdater(path).GetVersion("version"); err == nil {
+		t.Errorf("GetVersion read %q from a commented-out assignment", got)
+	}
+}
+
+func TestRockspecRevision(t *testing.T) {
+	tests := []struct {
+		current string
+		version string
+		want    string
+	}{
+		{"1.2.3-2", "1.3.0", "1.3.0-1"},
+		{"1.2.3-2", "v1.3.0", "1.3.0-1"},
+		{"1.2.3-2", "1.2.3", "1.2.3-2"},
+		{"1.2.3", "1.3.0", "1.3.0-1"},
+	}
+
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "pkg-dev.rockspec")
+		content := "package = \"pkg\"\nversion = \"" + tt.current + "\"\n"
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		u := NewRockspecUpdater(path)
+		if err := u.SetVersion("version", tt.version); err != nil {
+			t.Fatalf("SetVersion(%q): %v", tt.version, err)
+		}
+		got, err := NewAssignmentUpdater(path).GetVersion("version")
+		if err != nil || got != tt.want {
+			t.Errorf("%s set to %s = %q, %v; want %q", tt.current, tt.version, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package updater
+
+import (
+	"regexp"
+)
+
+// NewWordPressHeaderUpdater handles the file header WordPress reads plugin
+// and theme metadata from: the doc comment of the main plugin PHP file or
+// the top comment of a theme's style.css. The key is the header name;
+// "version" means the "Version:" header.
+func NewWordPressHeaderUpdater(path string) *PatternUpdater {
+	return NewPatternUpdater(path, wordPressHeaderPattern)
+}
+
+func wordPressHeaderPattern(keyPath string) (*regexp.Regexp, error) {
+	header := keyPath
+	if header == "" || header == "version" {
+		header = "Version"
+	}
+
+	return regexp.Compile(`(?m)^[ \t/*#@]*` + regexp.QuoteMeta(header) + `:[ \t]*(?P<version>[^\s*]+)`)
+}
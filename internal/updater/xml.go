@@ -0,0 +1,126 @@
+package updater
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/yendefrr/commet/internal/fsutil"
+)
+
+// XMLUpdater edits the text of an element addressed by a dot path from the
+// root, e.g. "package.metadata.version" in a .nuspec. Only the element text
+// is replaced, so the rest of the document keeps its exact formatting.
+type XMLUpdater struct {
+	filePath string
+}
+
+func NewXMLUpdater(path string) *XMLUpdater {
+	return &XMLUpdater{filePath: path}
+}
+
+func (u *XMLUpdater) GetVersion(keyPath string) (string, error) {
+	content, err := os.ReadFile(u.filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	start, end, err := locateXMLElement(content, keyPath)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(content[start:end])), nil
+}
+
+func (u *XMLUpdater) SetVersion(keyPath, version string) error {
+	updated, err := u.Preview(keyPath, version)
+	if err != nil {
+		return err
+	}
+
+	if err := fsutil.WriteFile(u.filePath, updated); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
+
+func (u *XMLUpdater) Preview(keyPath, version string) ([]byte, error) {
+	content, err := os.ReadFile(u.filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	start, end, err := locateXMLElement(content, keyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return splice(content, start, end, version), nil
+}
+
+// locateXMLElement returns the byte range of the text inside the element at keyPath.
+func locateXMLElement(content []byte, keyPath string) (int, int, error) {
+	target := strings.Split(keyPath, ".")
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+
+	var stack []string
+	start := -1
+
+	for {
+		offset := int(decoder.InputOffset())
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to parse XML: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			stack = append(stack, t.Name.Local)
+			if start < 0 && pathEqual(stack, target) {
+				start = int(decoder.InputOffset())
+			}
+		case xml.EndElement:
+			if start >= 0 && pathEqual(stack, target) {
+				return start, offset, nil
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	return 0, 0, fmt.Errorf("version key '%s' not found", keyPath)
+}
+
+func pathEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// splice replaces the trimmed text in content[start:end] with value, keeping
+// surrounding whitespace.
+func splice(content []byte, start, end int, value string) []byte {
+	inner := string(content[start:end])
+	leading := inner[:len(inner)-len(strings.TrimLeft(inner, " \t\r\n"))]
+	trailing := inner[len(strings.TrimRight(inner, " \t\r\n")):]
+
+	var buf bytes.Buffer
+	buf.Write(content[:start])
+	buf.WriteString(leading + value + trailing)
+	buf.Write(content[end:])
+
+	return buf.Bytes()
+}
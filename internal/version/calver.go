@@ -0,0 +1,170 @@
+package version
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yendefrr/commet/internal/config"
+)
+
+// calverToken matches the segments of a CalVer pattern such as YYYY.0M.MICRO.
+var calverToken = regexp.MustCompile(`YYYY|YY|0Y|MM|0M|WW|0W|DD|0D|MICRO`)
+
+// ValidateCalVerPattern checks that pattern has at least one date segment
+// and at most one MICRO segment.
+func ValidateCalVerPattern(pattern string) error {
+	tokens := calverToken.FindAllString(pattern, -1)
+	micro := 0
+	for _, token := range tokens {
+		if token == "MICRO" {
+			micro++
+		}
+	}
+
+	if len(tokens)-micro == 0 {
+		return fmt.Errorf("calver pattern %s has no date segment", pattern)
+	}
+	if micro > 1 {
+		return fmt.Errorf("calver pattern %s has more than one MICRO segment", pattern)
+	}
+
+	return nil
+}
+
+// nextCalVer returns the CalVer for a release at now: the date segments of
+// pattern filled in, with MICRO incremented when current is from the same
+// period and reset to 0 otherwise.
+func nextCalVer(pattern, current string, now time.Time) (string, error) {
+	if err := ValidateCalVerPattern(pattern); err != nil {
+		return "", err
+	}
+
+	// Date segments for now, with MICRO left as a capture group to match current
+	var expr strings.Builder
+	expr.WriteString("^v?")
+	last := 0
+	for _, loc := range calverToken.FindAllStringIndex(pattern, -1) {
+		expr.WriteString(regexp.QuoteMeta(pattern[last:loc[0]]))
+		token := pattern[loc[0]:loc[1]]
+		if token == "MICRO" {
+			expr.WriteString(`(\d+)`)
+		} else {
+			expr.WriteString(regexp.QuoteMeta(calverSegment(token, now)))
+		}
+		last = loc[1]
+	}
+	expr.WriteString(regexp.QuoteMeta(pattern[last:]))
+	expr.WriteString("$")
+
+	micro := 0
+	if matches := regexp.MustCompile(expr.String()).FindStringSubmatch(current); matches != nil {
+		if len(matches) > 1 {
+			n, err := strconv.Atoi(matches[1])
+			if err != nil {
+				return "", fmt.Errorf("invalid MICRO segment in %s: %w", current, err)
+			}
+			micro = n + 1
+		} else {
+			return "", fmt.Errorf("version %s was already released this period and pattern %s has no MICRO segment", current, pattern)
+		}
+	}
+
+	return calverToken.ReplaceAllStringFunc(pattern, func(token string) string {
+		if token == "MICRO" {
+			return strconv.Itoa(micro)
+		}
+		return calverSegment(token, now)
+	}), nil
+}
+
+// calverSegment renders one date segment as defined by calver.org.
+func calverSegment(token string, now time.Time) string {
+	_, week := now.ISOWeek()
+
+	switch token {
+	case "YYYY":
+		return strconv.Itoa(now.Year())
+	case "YY":
+		return strconv.Itoa(now.Year() % 100)
+	case "0Y":
+		return fmt.Sprintf("%02d", now.Year()%100)
+	case "MM":
+		return strconv.Itoa(int(now.Month()))
+	case "0M":
+		return fmt.Sprintf("%02d", int(now.Month()))
+	case "WW":
+		return strconv.Itoa(week)
+	case "0W":
+		return fmt.Sprintf("%02d", week)
+	case "DD":
+		return strconv.Itoa(now.Day())
+	case "0D":
+		return fmt.Sprintf("%02d", now.Day())
+	}
+	return token
+}
+
+// calverNumber matches a version of numeric segments such as 2024.06.3.
+var calverNumber = regexp.MustCompile(`^[0-9]+([^0-9A-Za-z][0-9]+)*$`)
+
+// calverDigits matches the numeric segments of a calendar version.
+var calverDigits = regexp.MustCompile(`[0-9]+`)
+
+// calverScheme is Calendar Versioning: a release is dated, so any bump moves
+// the version to the current period.
+type calverScheme struct {
+	pattern string
+	now     func() time.Time
+}
+
+func (s calverScheme) Parse(v string) (string, error) {
+	v = strings.TrimPrefix(v, "v")
+	if !calverNumber.MatchString(v) {
+		return "", fmt.Errorf("%s is not a calendar version", v)
+	}
+	return v, nil
+}
+
+func (s calverScheme) Bump(current string, bump config.BumpType) (string, error) {
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+	return nextCalVer(s.pattern, current, now())
+}
+
+func (s calverScheme) Format(v string) string {
+	return v
+}
+
+// Compare orders calendar versions segment by segment, numerically.
+func (s calverScheme) Compare(a, b string) (int, error) {
+	if _, err := s.Parse(a); err != nil {
+		return 0, err
+	}
+	if _, err := s.Parse(b); err != nil {
+		return 0, err
+	}
+
+	sa := calverDigits.FindAllString(a, -1)
+	sb := calverDigits.FindAllString(b, -1)
+	for i := 0; i < len(sa) || i < len(sb); i++ {
+		var na, nb uint64
+		if i < len(sa) {
+			na, _ = strconv.ParseUint(sa[i], 10, 64)
+		}
+		if i < len(sb) {
+			nb, _ = strconv.ParseUint(sb[i], 10, 64)
+		}
+		switch {
+		case na < nb:
+			return -1, nil
+		case na > nb:
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
@@ -0,0 +1,110 @@
+package version
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yendefrr/commet/internal/config"
+)
+
+// fourPart is a major.minor.patch.build version as used by .NET assemblies.
+type fourPart [4]uint64
+
+// parseFourPart reads a version with three or four numeric segments; a
+// missing build segment is 0.
+func parseFourPart(s string) (fourPart, error) {
+	var v fourPart
+
+	segments := strings.Split(strings.TrimPrefix(s, "v"), ".")
+	if len(segments) != 3 && len(segments) != 4 {
+		return v, fmt.Errorf("%s is not a major.minor.patch[.build] version", s)
+	}
+
+	for i, segment := range segments {
+		n, err := strconv.ParseUint(segment, 10, 64)
+		if err != nil {
+			return v, fmt.Errorf("%s is not a major.minor.patch[.build] version", s)
+		}
+		v[i] = n
+	}
+
+	return v, nil
+}
+
+func (v fourPart) String() string {
+	return fmt.Sprintf("%d.%d.%d.%d", v[0], v[1], v[2], v[3])
+}
+
+// next applies bump. The build segment always moves up by one; on patch,
+// minor and major bumps it restarts at 0 when mode is "reset".
+func (v fourPart) next(bump config.BumpType, mode string) fourPart {
+	build := v[3] + 1
+
+	switch bump {
+	case config.BumpMajor:
+		v = fourPart{v[0] + 1, 0, 0, build}
+	case config.BumpMinor:
+		v = fourPart{v[0], v[1] + 1, 0, build}
+	case config.BumpPatch:
+		v = fourPart{v[0], v[1], v[2] + 1, build}
+	default:
+		v[3] = build
+		return v
+	}
+
+	if mode != "increment" {
+		v[3] = 0
+	}
+	return v
+}
+
+func (v fourPart) compare(o fourPart) int {
+	for i := range v {
+		switch {
+		case v[i] < o[i]:
+			return -1
+		case v[i] > o[i]:
+			return 1
+		}
+	}
+	return 0
+}
+
+// fourPartScheme is major.minor.patch.build; build_segment decides whether
+// the build number restarts on higher bumps.
+type fourPartScheme struct {
+	buildSegment string
+}
+
+func (s fourPartScheme) Parse(v string) (string, error) {
+	ver, err := parseFourPart(v)
+	if err != nil {
+		return "", err
+	}
+	return ver.String(), nil
+}
+
+func (s fourPartScheme) Bump(current string, bump config.BumpType) (string, error) {
+	ver, err := parseFourPart(current)
+	if err != nil {
+		return "", err
+	}
+	return ver.next(bump, s.buildSegment).String(), nil
+}
+
+func (s fourPartScheme) Format(v string) string {
+	return v
+}
+
+func (s fourPartScheme) Compare(a, b string) (int, error) {
+	va, err := parseFourPart(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := parseFourPart(b)
+	if err != nil {
+		return 0, err
+	}
+	return va.compare(vb), nil
+}
@@ -0,0 +1,45 @@
+package version
+
+import (
+	"regexp"
+	"strings"
+)
+
+// linePattern matches a version line such as "1.2.x" or "1.x".
+var linePattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)*\.x$`)
+
+// IsLine reports whether line names a version line such as "1.2.x".
+func IsLine(line string) bool {
+	return linePattern.MatchString(line)
+}
+
+// InLine reports whether ver belongs to line: 1.2.8 and 1.2.9-rc.1 are in
+// "1.2.x", 1.3.0 and 1.20.0 are not.
+func InLine(ver, line string) bool {
+	core, _, _ := strings.Cut(strings.TrimPrefix(ver, "v"), "+")
+	core, _, _ = strings.Cut(core, "-")
+	return strings.HasPrefix(core+".", strings.TrimSuffix(line, "x"))
+}
+
+// Aliases returns the floating major and minor versions of a stable release,
+// e.g. "1" and "1.4" for 1.4.2 (build metadata is dropped). Pre-releases and
+// versions that are not major.minor.patch have none.
+func Aliases(ver string) []string {
+	prefix := ""
+	if strings.HasPrefix(ver, "v") {
+		prefix, ver = "v", ver[1:]
+	}
+
+	core, _, _ := strings.Cut(ver, "+")
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return nil
+	}
+	for _, part := range parts {
+		if part == "" || strings.Trim(part, "0123456789") != "" {
+			return nil
+		}
+	}
+
+	return []string{prefix + parts[0], prefix + parts[0] + "." + parts[1]}
+}
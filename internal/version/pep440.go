@@ -0,0 +1,237 @@
+package version
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/yendefrr/commet/internal/config"
+)
+
+// pep440Pattern matches a normalized PEP 440 version:
+// [N!]N(.N)*[{a|b|rc}N][.postN][.devN].
+var pep440Pattern = regexp.MustCompile(`^(?:([0-9]+)!)?([0-9]+(?:\.[0-9]+)*)(?:(a|b|rc)([0-9]+))?(?:\.post([0-9]+))?(?:\.dev([0-9]+))?$`)
+
+// pep440Phases maps pre-release identifiers to PEP 440 phases.
+var pep440Phases = map[string]string{
+	"a": "a", "alpha": "a",
+	"b": "b", "beta": "b",
+	"rc": "rc", "c": "rc",
+}
+
+// pep440Version is a parsed PEP 440 version. Missing post and dev releases are -1.
+type pep440Version struct {
+	epoch   uint64
+	release []uint64
+	pre     string
+	preN    uint64
+	post    int64
+	dev     int64
+}
+
+func parsePEP440(s string) (*pep440Version, error) {
+	m := pep440Pattern.FindStringSubmatch(strings.ToLower(strings.TrimPrefix(s, "v")))
+	if m == nil {
+		return nil, fmt.Errorf("%s is not a PEP 440 version", s)
+	}
+
+	v := &pep440Version{pre: m[3], post: -1, dev: -1}
+	v.epoch, _ = strconv.ParseUint(m[1], 10, 64)
+	for _, segment := range strings.Split(m[2], ".") {
+		n, _ := strconv.ParseUint(segment, 10, 64)
+		v.release = append(v.release, n)
+	}
+	v.preN, _ = strconv.ParseUint(m[4], 10, 64)
+	if m[5] != "" {
+		v.post, _ = strconv.ParseInt(m[5], 10, 64)
+	}
+	if m[6] != "" {
+		v.dev, _ = strconv.ParseInt(m[6], 10, 64)
+	}
+
+	return v, nil
+}
+
+func (v *pep440Version) String() string {
+	var b strings.Builder
+	if v.epoch > 0 {
+		fmt.Fprintf(&b, "%d!", v.epoch)
+	}
+	for i, n := range v.release {
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(strconv.FormatUint(n, 10))
+	}
+	if v.pre != "" {
+		fmt.Fprintf(&b, "%s%d", v.pre, v.preN)
+	}
+	if v.post >= 0 {
+		fmt.Fprintf(&b, ".post%d", v.post)
+	}
+	if v.dev >= 0 {
+		fmt.Fprintf(&b, ".dev%d", v.dev)
+	}
+	return b.String()
+}
+
+// segment returns release segment i, 0 when missing.
+func (v *pep440Version) segment(i int) uint64 {
+	if i < len(v.release) {
+		return v.release[i]
+	}
+	return 0
+}
+
+// unreleased reports whether v comes before its release: a pre-release or
+// a development release such as 1.3.0.dev2.
+func (v *pep440Version) unreleased() bool {
+	return v.pre != "" || (v.dev >= 0 && v.post < 0)
+}
+
+// compare orders by epoch, release, then dev < a < b < rc < final < post.
+func (v *pep440Version) compare(o *pep440Version) int {
+	if c := compareUint(v.epoch, o.epoch); c != 0 {
+		return c
+	}
+
+	for i := 0; i < len(v.release) || i < len(o.release); i++ {
+		if c := compareUint(v.segment(i), o.segment(i)); c != 0 {
+			return c
+		}
+	}
+
+	if c := compareInt(v.phaseRank(), o.phaseRank()); c != 0 {
+		return c
+	}
+	if c := compareUint(v.preN, o.preN); c != 0 {
+		return c
+	}
+	if c := compareInt(v.post, o.post); c != 0 {
+		return c
+	}
+
+	// No dev release sorts after any dev release
+	vd, od := v.dev, o.dev
+	if vd < 0 {
+		vd = 1<<63 - 1
+	}
+	if od < 0 {
+		od = 1<<63 - 1
+	}
+	return compareInt(vd, od)
+}
+
+// phaseRank orders the pre-release phase: a bare dev release first, then
+// a, b, rc and finally releases without a phase.
+func (v *pep440Version) phaseRank() int64 {
+	switch {
+	case v.pre == "a":
+		return 1
+	case v.pre == "b":
+		return 2
+	case v.pre == "rc":
+		return 3
+	case v.dev >= 0 && v.post < 0:
+		return 0
+	default:
+		return 4
+	}
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// pep440Scheme is Python's PEP 440, e.g. 1.3.0, 1.3.0rc1 and 1.3.0.post1.
+// Pre-releases use the a, b and rc phases; alpha and beta are accepted.
+type pep440Scheme struct {
+	prerelease string
+}
+
+func (s pep440Scheme) Parse(v string) (string, error) {
+	ver, err := parsePEP440(v)
+	if err != nil {
+		return "", err
+	}
+	return ver.String(), nil
+}
+
+// Bump works like semver: a pre-release or dev release is finalized unless
+// the bump needs a higher segment, and with a pre-release phase configured
+// 1.3.0rc1 is followed by 1.3.0rc2.
+func (s pep440Scheme) Bump(current string, bump config.BumpType) (string, error) {
+	ver, err := parsePEP440(current)
+	if err != nil {
+		return "", err
+	}
+
+	major, minor, patch := ver.segment(0), ver.segment(1), ver.segment(2)
+	next := &pep440Version{epoch: ver.epoch, post: -1, dev: -1}
+
+	switch {
+	case ver.unreleased() && bump == config.BumpMajor && (minor != 0 || patch != 0):
+		major, minor, patch = major+1, 0, 0
+	case ver.unreleased() && bump == config.BumpMinor && patch != 0:
+		minor, patch = minor+1, 0
+	case ver.unreleased():
+	case bump == config.BumpMajor:
+		major, minor, patch = major+1, 0, 0
+	case bump == config.BumpMinor:
+		minor, patch = minor+1, 0
+	default:
+		patch++
+	}
+	next.release = []uint64{major, minor, patch}
+
+	if s.prerelease != "" {
+		phase, ok := pep440Phases[s.prerelease]
+		if !ok {
+			return "", fmt.Errorf("PEP 440 pre-releases must be 'a', 'b' or 'rc', not %s", s.prerelease)
+		}
+
+		next.pre, next.preN = phase, 1
+		if ver.pre == phase && ver.segment(0) == major && ver.segment(1) == minor && ver.segment(2) == patch {
+			next.preN = ver.preN + 1
+		}
+
+		if next.compare(ver) <= 0 {
+			return "", fmt.Errorf("pre-release %s would not be newer than %s", next, ver)
+		}
+	}
+
+	return next.String(), nil
+}
+
+func (s pep440Scheme) Format(v string) string {
+	return v
+}
+
+func (s pep440Scheme) Compare(a, b string) (int, error) {
+	va, err := parsePEP440(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := parsePEP440(b)
+	if err != nil {
+		return 0, err
+	}
+	return va.compare(vb), nil
+}
@@ -0,0 +1,75 @@
+package version
+
+import (
+	"strings"
+
+	"github.com/yendefrr/commet/internal/config"
+)
+
+// Scheme is a version numbering scheme, selected with version.format. The
+// Calculator decides how much to bump; the scheme decides what that means
+// for a version string.
+//
+// Versions passed between the methods are canonical, as returned by Parse;
+// Format turns them into what is written to version files and tags.
+type Scheme interface {
+	// Parse checks that v is a version of the scheme and returns it in
+	// canonical form, e.g. without a "v" prefix
+	Parse(v string) (string, error)
+	// Bump returns the canonical version that follows current for bump
+	Bump(current string, bump config.BumpType) (string, error)
+	// Format renders a canonical version for version files and tags
+	Format(v string) string
+	// Compare returns -1, 0 or 1 as a is lower than, equal to or higher than b
+	Compare(a, b string) (int, error)
+}
+
+// SchemeFactory builds a scheme from the [version] section.
+type SchemeFactory func(cfg config.VersionConfig) Scheme
+
+var schemes = map[string]SchemeFactory{}
+
+// Register makes a scheme available as version.format = name. Built-in
+// schemes are "semver", "v-prefix", "calver", "four-part" and "pep440";
+// custom schemes register from an init function of a custom build.
+func Register(name string, factory SchemeFactory) {
+	schemes[name] = factory
+	config.RegisterFormat(name)
+}
+
+// SchemeFor returns the scheme selected by cfg, semver when none is set.
+func SchemeFor(cfg *config.Config) Scheme {
+	if factory, ok := schemes[cfg.Version.Format]; ok {
+		return factory(cfg.Version)
+	}
+	return schemes["semver"](cfg.Version)
+}
+
+func init() {
+	Register("semver", func(cfg config.VersionConfig) Scheme {
+		return semverScheme{prerelease: cfg.Prerelease}
+	})
+	Register("v-prefix", func(cfg config.VersionConfig) Scheme {
+		return semverScheme{prefix: true, prerelease: cfg.Prerelease}
+	})
+	Register("calver", func(cfg config.VersionConfig) Scheme {
+		return calverScheme{pattern: cfg.CalVerPattern}
+	})
+	Register("four-part", func(cfg config.VersionConfig) Scheme {
+		return fourPartScheme{buildSegment: cfg.BuildSegment}
+	})
+	Register("pep440", func(cfg config.VersionConfig) Scheme {
+		return pep440Scheme{prerelease: cfg.Prerelease}
+	})
+}
+
+// zeroMajor reports whether the leading number of v, after any "v" prefix
+// or PEP 440 epoch, is 0.
+func zeroMajor(v string) bool {
+	v = strings.TrimPrefix(v, "v")
+	if _, release, ok := strings.Cut(v, "!"); ok {
+		v = release
+	}
+	major, _, _ := strings.Cut(v, ".")
+	return major == "0"
+}
@@ -0,0 +1,60 @@
+package version
+
+import (
+	"strings"
+
+	"github.com/yendefrr/commet/internal/config"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// semverScheme is Semantic Versioning, optionally written with a "v" prefix
+// and released as pre-releases with the configured identifier.
+type semverScheme struct {
+	prefix     bool
+	prerelease string
+}
+
+func (s semverScheme) Parse(v string) (string, error) {
+	ver, err := semver.NewVersion(strings.TrimPrefix(v, "v"))
+	if err != nil {
+		return "", err
+	}
+	return ver.String(), nil
+}
+
+func (s semverScheme) Bump(current string, bump config.BumpType) (string, error) {
+	ver, err := semver.NewVersion(current)
+	if err != nil {
+		return "", err
+	}
+
+	next := increment(ver, bump)
+
+	if s.prerelease != "" {
+		if next, err = nextPrerelease(ver, next, s.prerelease); err != nil {
+			return "", err
+		}
+	}
+
+	return next.String(), nil
+}
+
+func (s semverScheme) Format(v string) string {
+	if s.prefix {
+		return "v" + v
+	}
+	return v
+}
+
+func (s semverScheme) Compare(a, b string) (int, error) {
+	va, err := semver.NewVersion(strings.TrimPrefix(a, "v"))
+	if err != nil {
+		return 0, err
+	}
+	vb, err := semver.NewVersion(strings.TrimPrefix(b, "v"))
+	if err != nil {
+		return 0, err
+	}
+	return va.Compare(vb), nil
+}
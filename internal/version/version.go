@@ -2,7 +2,11 @@ package version
 
 import (
 	"fmt"
+	"os"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/yendefrr/commet/internal/config"
 	"github.com/yendefrr/commet/internal/parser"
@@ -12,33 +16,325 @@ import (
 
 type Calculator struct {
 	config *config.Config
+	scheme Scheme
 }
 
 func NewCalculator(cfg *config.Config) *Calculator {
-	return &Calculator{config: cfg}
+	return &Calculator{config: cfg, scheme: SchemeFor(cfg)}
+}
+
+// Scheme returns the version scheme selected by version.format.
+func (c *Calculator) Scheme() Scheme {
+	return c.scheme
 }
 
 func (c *Calculator) Calculate(current string, commits []*parser.Commit) (string, config.BumpType, error) {
+	if _, err := c.scheme.Parse(current); err != nil {
+		return "", config.BumpNone, fmt.Errorf("invalid current version %s: %w", current, err)
+	}
+
+	bump := c.releaseBump(commits)
+	if bump == config.BumpNone {
+		return current, config.BumpNone, nil
+	}
+
+	if c.config.Version.ZeroVer && zeroMajor(current) {
+		bump = demote(bump)
+	}
+
+	next, err := c.Apply(current, bump)
+	if err != nil {
+		return "", config.BumpNone, err
+	}
+
+	return next, bump, nil
+}
+
+// Apply bumps current by bump without looking at any commits, e.g. for a
+// bump forced on the command line. Pre-release settings still apply; calver
+// versions move to the current date regardless of bump.
+func (c *Calculator) Apply(current string, bump config.BumpType) (string, error) {
+	if bump == config.BumpNone {
+		return current, nil
+	}
+
+	ver, err := c.scheme.Parse(current)
+	if err != nil {
+		return "", fmt.Errorf("invalid current version %s: %w", current, err)
+	}
+
+	next, err := c.scheme.Bump(ver, bump)
+	if err != nil {
+		return "", err
+	}
+
+	return c.scheme.Format(next), nil
+}
+
+// envPlaceholder matches {env:NAME} in build metadata templates.
+var envPlaceholder = regexp.MustCompile(`\{env:([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// buildNumberVars are the CI variables holding the build number, in order
+// of preference: Jenkins, GitHub Actions, GitLab, Azure Pipelines, CircleCI.
+var buildNumberVars = []string{"BUILD_NUMBER", "GITHUB_RUN_NUMBER", "CI_PIPELINE_IID", "BUILD_BUILDID", "CIRCLE_BUILD_NUM"}
+
+// BuildNumber returns the build number set by the CI system.
+func BuildNumber() (string, error) {
+	for _, name := range buildNumberVars {
+		if value := os.Getenv(name); value != "" {
+			return value, nil
+		}
+	}
+	return "", fmt.Errorf("{build} needs a CI build number in one of %s", strings.Join(buildNumberVars, ", "))
+}
+
+// Expand fills a version template such as tag_format or version.template:
+// {version} with ver and {build} with the CI build number, e.g.
+// "{version}+{build}" gives 1.3.0+57.
+func Expand(template, ver string) (string, error) {
+	result := strings.ReplaceAll(template, "{version}", ver)
+	if !strings.Contains(result, "{build}") {
+		return result, nil
+	}
+
+	build, err := BuildNumber()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.ReplaceAll(result, "{build}", build), nil
+}
+
+// BuildMetadata expands a version.build_metadata template such as
+// "build.{build}.sha.{sha}". Placeholders: {sha} (abbreviated commit hash),
+// {date} (UTC, YYYYMMDD), {build} (CI build number) and {env:NAME}.
+func BuildMetadata(template, sha string) (string, error) {
+	replacer := strings.NewReplacer(
+		"{sha}", sha,
+		"{date}", time.Now().UTC().Format("20060102"),
+	)
+	metadata := replacer.Replace(template)
+
+	if strings.Contains(metadata, "{build}") {
+		build, err := BuildNumber()
+		if err != nil {
+			return "", err
+		}
+		metadata = strings.ReplaceAll(metadata, "{build}", build)
+	}
+
+	var missing []string
+	metadata = envPlaceholder.ReplaceAllStringFunc(metadata, func(placeholder string) string {
+		name := envPlaceholder.FindStringSubmatch(placeholder)[1]
+		value, ok := os.LookupEnv(name)
+		if !ok || value == "" {
+			missing = append(missing, name)
+		}
+		return value
+	})
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("build metadata needs environment variable(s) %s", strings.Join(missing, ", "))
+	}
+
+	return metadata, nil
+}
+
+// WithMetadata returns ver with its build metadata replaced by metadata,
+// e.g. 1.3.0 and "build.42" give 1.3.0+build.42. Metadata does not take
+// part in version precedence.
+func (c *Calculator) WithMetadata(ver, metadata string) (string, error) {
+	parsed, err := c.parseVersion(ver)
+	if err != nil {
+		return "", fmt.Errorf("invalid version %s: %w", ver, err)
+	}
+
+	withMetadata, err := parsed.SetMetadata(metadata)
+	if err != nil {
+		return "", fmt.Errorf("invalid build metadata %s: %w", metadata, err)
+	}
+
+	return c.formatVersion(&withMetadata), nil
+}
+
+// nextPrerelease turns the release target into a pre-release with identifier
+// id: 1.3.0 becomes 1.3.0-rc.1, and when current is already a pre-release of
+// target the counter moves on, so 1.3.0-rc.1 becomes 1.3.0-rc.2 and
+// 1.3.0-beta.2 becomes 1.3.0-rc.1.
+func nextPrerelease(current *semver.Version, target semver.Version, id string) (semver.Version, error) {
+	counter := 1
+
+	if current.Prerelease() != "" && sameCore(current, &target) {
+		currentID, currentCounter, _ := strings.Cut(current.Prerelease(), ".")
+		if currentID == id {
+			n, err := strconv.Atoi(currentCounter)
+			if err != nil {
+				return semver.Version{}, fmt.Errorf("cannot continue pre-release %s: counter is not a number", current.Prerelease())
+			}
+			counter = n + 1
+		}
+	}
+
+	next, err := target.SetPrerelease(fmt.Sprintf("%s.%d", id, counter))
+	if err != nil {
+		return semver.Version{}, fmt.Errorf("invalid pre-release identifier %s: %w", id, err)
+	}
+
+	if !next.GreaterThan(current) {
+		return semver.Version{}, fmt.Errorf("pre-release %s would not be newer than %s", next.String(), current.String())
+	}
+
+	return next, nil
+}
+
+// sameCore reports whether a and b share major, minor and patch.
+func sameCore(a, b *semver.Version) bool {
+	return a.Major() == b.Major() && a.Minor() == b.Minor() && a.Patch() == b.Patch()
+}
+
+// Promote returns the stable release a pre-release leads up to, e.g. 2.0.0
+// for 2.0.0-rc.3. Build metadata is dropped as well.
+func (c *Calculator) Promote(current string) (string, error) {
 	ver, err := c.parseVersion(current)
 	if err != nil {
-		return "", config.BumpNone, fmt.Errorf("invalid current version %s: %w", current, err)
+		return "", fmt.Errorf("invalid current version %s: %w", current, err)
+	}
+
+	if ver.Prerelease() == "" {
+		return "", fmt.Errorf("%s is not a pre-release", current)
+	}
+
+	stable := semver.New(ver.Major(), ver.Minor(), ver.Patch(), "", "")
+	return c.formatVersion(stable), nil
+}
+
+// NextSnapshot returns the development version that follows a release,
+// e.g. 1.5.0-SNAPSHOT after 1.4.0 with the default minor snapshot bump.
+func (c *Calculator) NextSnapshot(released string) (string, error) {
+	ver, err := c.parseVersion(released)
+	if err != nil {
+		return "", fmt.Errorf("invalid released version %s: %w", released, err)
+	}
+
+	bump := c.config.Snapshot.Bump
+	if bump == "" || bump == config.BumpNone {
+		bump = config.BumpMinor
+	}
+
+	next := increment(ver, bump)
+	next, err = next.SetPrerelease(strings.TrimPrefix(c.config.Snapshot.Suffix, "-"))
+	if err != nil {
+		return "", fmt.Errorf("invalid snapshot suffix %s: %w", c.config.Snapshot.Suffix, err)
+	}
+
+	return c.formatVersion(&next), nil
+}
+
+// PostReleaseVersion fills the git.post_release_version template for a release.
+// Supported placeholders: {version}, {major}, {minor}, {patch}, {next_major},
+// {next_minor} and {next_patch}, e.g. "{next_patch}-dev" gives 1.4.1-dev after 1.4.0.
+func (c *Calculator) PostReleaseVersion(released, template string) (string, error) {
+	ver, err := c.parseVersion(released)
+	if err != nil {
+		return "", fmt.Errorf("invalid released version %s: %w", released, err)
+	}
+
+	nextMajor := ver.IncMajor()
+	nextMinor := ver.IncMinor()
+	nextPatch := ver.IncPatch()
+
+	replacer := strings.NewReplacer(
+		"{version}", ver.String(),
+		"{major}", fmt.Sprint(ver.Major()),
+		"{minor}", fmt.Sprint(ver.Minor()),
+		"{patch}", fmt.Sprint(ver.Patch()),
+		"{next_major}", nextMajor.String(),
+		"{next_minor}", nextMinor.String(),
+		"{next_patch}", nextPatch.String(),
+	)
+
+	result := replacer.Replace(template)
+	next, err := c.parseVersion(result)
+	if err != nil {
+		return "", fmt.Errorf("post-release version %s is not a valid version: %w", result, err)
 	}
 
-	bump := c.DetermineBump(commits)
+	return c.formatVersion(next), nil
+}
 
-	var newVer semver.Version
+// demote lowers a bump by one level for 0.x versions, where breaking
+// changes bump minor and features bump patch.
+func demote(bump config.BumpType) config.BumpType {
 	switch bump {
 	case config.BumpMajor:
-		newVer = ver.IncMajor()
+		return config.BumpMinor
 	case config.BumpMinor:
-		newVer = ver.IncMinor()
-	case config.BumpPatch:
-		newVer = ver.IncPatch()
+		return config.BumpPatch
 	default:
-		return current, config.BumpNone, nil
+		return bump
+	}
+}
+
+// increment applies bump to ver. A pre-release version such as 1.5.0-SNAPSHOT
+// already stands for the release it precedes, so it is finalized to 1.5.0
+// unless the bump needs a higher component than the one it opened.
+func increment(ver *semver.Version, bump config.BumpType) semver.Version {
+	if ver.Prerelease() != "" {
+		final, _ := ver.SetPrerelease("")
+		final, _ = final.SetMetadata("")
+
+		switch {
+		case bump == config.BumpMajor && (final.Minor() != 0 || final.Patch() != 0):
+			return final.IncMajor()
+		case bump == config.BumpMinor && final.Patch() != 0:
+			return final.IncMinor()
+		default:
+			return final
+		}
+	}
+
+	switch bump {
+	case config.BumpMajor:
+		return ver.IncMajor()
+	case config.BumpMinor:
+		return ver.IncMinor()
+	default:
+		return ver.IncPatch()
+	}
+}
+
+// releaseBump is DetermineBump raised by roll-up thresholds, and to
+// version.min_bump when there are commits.
+func (c *Calculator) releaseBump(commits []*parser.Commit) config.BumpType {
+	bump := maxBump(c.DetermineBump(commits), c.RollupBump(commits))
+	if len(commits) > 0 && c.config.Version.MinBump != "" {
+		bump = maxBump(bump, c.config.Version.MinBump)
+	}
+	return bump
+}
+
+// RollupBump returns the bump that commits add up to under the [rollup]
+// thresholds, e.g. minor for ten patch-level commits with patch_threshold = 10,
+// or none when no threshold is reached.
+func (c *Calculator) RollupBump(commits []*parser.Commit) config.BumpType {
+	rollup := c.config.Rollup
+	if rollup.PatchThreshold == 0 && rollup.MinorThreshold == 0 {
+		return config.BumpNone
 	}
 
-	return c.formatVersion(&newVer), bump, nil
+	counts := make(map[config.BumpType]int)
+	for _, commit := range commits {
+		counts[c.DetermineBump([]*parser.Commit{commit})]++
+	}
+
+	switch {
+	case rollup.MinorThreshold > 0 && counts[config.BumpMinor] >= rollup.MinorThreshold:
+		return config.BumpMajor
+	case rollup.PatchThreshold > 0 && counts[config.BumpPatch] >= rollup.PatchThreshold:
+		return config.BumpMinor
+	default:
+		return config.BumpNone
+	}
 }
 
 func (c *Calculator) DetermineBump(commits []*parser.Commit) config.BumpType {
@@ -49,8 +345,7 @@ func (c *Calculator) DetermineBump(commits []*parser.Commit) config.BumpType {
 			return config.BumpMajor
 		}
 
-		commitBump := c.config.GetBumpType(commit.Type)
-
+		commitBump := c.config.CommitBump(commit.Type, commit.Scope, commit.Boards, commit.Files, commit.Bump)
 		bump = maxBump(bump, commitBump)
 	}
 
@@ -73,9 +368,10 @@ func (c *Calculator) formatVersion(ver *semver.Version) string {
 
 func maxBump(a, b config.BumpType) config.BumpType {
 	precedence := map[config.BumpType]int{
-		config.BumpMajor: 3,
-		config.BumpMinor: 2,
-		config.BumpPatch: 1,
+		config.BumpMajor: 4,
+		config.BumpMinor: 3,
+		config.BumpPatch: 2,
+		config.BumpBuild: 1,
 		config.BumpNone:  0,
 	}
 
@@ -85,22 +381,35 @@ func maxBump(a, b config.BumpType) config.BumpType {
 	return b
 }
 
+// IsValid reports whether versionStr is a semantic version or a four-part
+// major.minor.patch.build version.
 func IsValid(versionStr string) bool {
 	versionStr = strings.TrimPrefix(versionStr, "v")
-	_, err := semver.NewVersion(versionStr)
+	if _, err := semver.NewVersion(versionStr); err == nil {
+		return true
+	}
+	_, err := parseFourPart(versionStr)
 	return err == nil
 }
 
+// Compare returns -1, 0 or 1 as v1 is lower than, equal to or higher than v2.
+// Four-part versions compare segment by segment, with 1.2.3 equal to 1.2.3.0.
 func Compare(v1, v2 string) (int, error) {
-	ver1, err := semver.NewVersion(strings.TrimPrefix(v1, "v"))
+	ver1, err1 := semver.NewVersion(strings.TrimPrefix(v1, "v"))
+	ver2, err2 := semver.NewVersion(strings.TrimPrefix(v2, "v"))
+	if err1 == nil && err2 == nil {
+		return ver1.Compare(ver2), nil
+	}
+
+	four1, err := parseFourPart(v1)
 	if err != nil {
 		return 0, fmt.Errorf("invalid version v1: %w", err)
 	}
 
-	ver2, err := semver.NewVersion(strings.TrimPrefix(v2, "v"))
+	four2, err := parseFourPart(v2)
 	if err != nil {
 		return 0, fmt.Errorf("invalid version v2: %w", err)
 	}
 
-	return ver1.Compare(ver2), nil
+	return four1.compare(four2), nil
 }
@@ -1,7 +1,9 @@
 package version
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/yendefrr/commet/internal/config"
 	"github.com/yendefrr/commet/internal/parser"
@@ -214,6 +216,20 @@ func TestDetermineBump(t *testing.T) {
 			},
 			expectedBump: config.BumpNone,
 		},
+		{
+			name: "bump override raises a fix",
+			commits: []*parser.Commit{
+				{Type: "Fix", Bump: "minor"},
+			},
+			expectedBump: config.BumpMinor,
+		},
+		{
+			name: "bump override lowers a feature",
+			commits: []*parser.Commit{
+				{Type: "Feature", Bump: "none"},
+			},
+			expectedBump: config.BumpNone,
+		},
 		{
 			name:         "no commits",
 			commits:      []*parser.Commit{},
@@ -231,6 +247,35 @@ func TestDetermineBump(t *testing.T) {
 	}
 }
 
+func TestDetermineBumpSecurity(t *testing.T) {
+	cfg := &config.Config{
+		BumpRules: map[string]config.BumpType{
+			"Security": config.BumpNone,
+			"Feature":  config.BumpMinor,
+		},
+	}
+	calc := NewCalculator(cfg)
+
+	tests := []struct {
+		name   string
+		commit *parser.Commit
+		want   config.BumpType
+	}{
+		{"at least patch", &parser.Commit{Type: "Security"}, config.BumpPatch},
+		{"any case", &parser.Commit{Type: "security"}, config.BumpPatch},
+		{"not lowered by an override", &parser.Commit{Type: "Security", Bump: "none"}, config.BumpPatch},
+		{"raised by an override", &parser.Commit{Type: "Security", Bump: "minor"}, config.BumpMinor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := calc.DetermineBump([]*parser.Commit{tt.commit}); got != tt.want {
+				t.Errorf("DetermineBump() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestIsValid(t *testing.T) {
 	tests := []struct {
 		version string
@@ -242,6 +287,8 @@ func TestIsValid(t *testing.T) {
 		{"10.20.30", true},
 		{"1.2", true},    // semver library accepts this as 1.2.0
 		{"1", true},      // semver library accepts this as 1.0.0
+		{"1.2.3.4", true},
+		{"1.2.3.4.5", false},
 		{"invalid", false},
 		{"", false},
 	}
@@ -268,6 +315,9 @@ func TestCompare(t *testing.T) {
 		{"2.0.0", "1.9.9", 1},
 		{"v1.2.3", "v1.2.3", 0},
 		{"v1.2.3", "1.2.3", 0},
+		{"1.2.3.10", "1.2.3.9", 1},
+		{"1.2.3", "1.2.3.0", 0},
+		{"1.2.3.1", "1.2.4", -1},
 	}
 
 	for _, tt := range tests {
@@ -284,3 +334,754 @@ func TestCompare(t *testing.T) {
 		})
 	}
 }
+
+func TestCalculateFromSnapshot(t *testing.T) {
+	cfg := &config.Config{
+		Version: config.VersionConfig{
+			Format: "semver",
+		},
+		BumpRules: map[string]config.BumpType{
+			"Fix":      config.BumpPatch,
+			"Feature":  config.BumpMinor,
+			"Breaking": config.BumpMajor,
+		},
+	}
+
+	calc := NewCalculator(cfg)
+
+	tests := []struct {
+		name            string
+		currentVersion  string
+		commitType      string
+		expectedVersion string
+	}{
+		{"patch strips qualifier", "1.5.0-SNAPSHOT", "Fix", "1.5.0"},
+		{"minor strips qualifier", "1.5.0-SNAPSHOT", "Feature", "1.5.0"},
+		{"major goes past snapshot", "1.5.0-SNAPSHOT", "Breaking", "2.0.0"},
+		{"major snapshot is kept", "2.0.0-SNAPSHOT", "Breaking", "2.0.0"},
+		{"minor goes past patch snapshot", "1.4.1-SNAPSHOT", "Feature", "1.5.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, _, err := calc.Calculate(tt.currentVersion, []*parser.Commit{{Type: tt.commitType}})
+			if err != nil {
+				t.Errorf("Calculate() error = %v", err)
+				return
+			}
+
+			if version != tt.expectedVersion {
+				t.Errorf("Calculate() version = %v, want %v", version, tt.expectedVersion)
+			}
+		})
+	}
+}
+
+func TestCalculatePrerelease(t *testing.T) {
+	tests := []struct {
+		name            string
+		currentVersion  string
+		identifier      string
+		commitType      string
+		expectedVersion string
+		wantErr         bool
+	}{
+		{"starts pre-release", "1.2.3", "rc", "Feature", "1.3.0-rc.1", false},
+		{"increments counter", "1.3.0-rc.1", "rc", "Fix", "1.3.0-rc.2", false},
+		{"minor on minor pre-release stays", "1.3.0-rc.2", "rc", "Feature", "1.3.0-rc.3", false},
+		{"major goes past minor pre-release", "1.3.0-rc.2", "rc", "Breaking", "2.0.0-rc.1", false},
+		{"moves to later channel", "1.3.0-beta.4", "rc", "Fix", "1.3.0-rc.1", false},
+		{"refuses earlier channel", "1.3.0-rc.1", "beta", "Fix", "", true},
+		{"finalizes without identifier", "1.3.0-rc.2", "", "Fix", "1.3.0", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				Version: config.VersionConfig{
+					Format:     "semver",
+					Prerelease: tt.identifier,
+				},
+				BumpRules: map[string]config.BumpType{
+					"Fix":      config.BumpPatch,
+					"Feature":  config.BumpMinor,
+					"Breaking": config.BumpMajor,
+				},
+			}
+
+			version, _, err := NewCalculator(cfg).Calculate(tt.currentVersion, []*parser.Commit{{Type: tt.commitType}})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Calculate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if version != tt.expectedVersion {
+				t.Errorf("Calculate() version = %v, want %v", version, tt.expectedVersion)
+			}
+		})
+	}
+}
+
+func TestNextSnapshot(t *testing.T) {
+	tests := []struct {
+		bump     config.BumpType
+		suffix   string
+		expected string
+	}{
+		{config.BumpMinor, "-SNAPSHOT", "1.5.0-SNAPSHOT"},
+		{config.BumpPatch, "-SNAPSHOT", "1.4.1-SNAPSHOT"},
+		{config.BumpMajor, "-dev", "2.0.0-dev"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			calc := NewCalculator(&config.Config{
+				Snapshot: config.SnapshotConfig{Bump: tt.bump, Suffix: tt.suffix},
+			})
+
+			version, err := calc.NextSnapshot("1.4.0")
+			if err != nil {
+				t.Errorf("NextSnapshot() error = %v", err)
+				return
+			}
+
+			if version != tt.expected {
+				t.Errorf("NextSnapshot() = %v, want %v", version, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPostReleaseVersion(t *testing.T) {
+	tests := []struct {
+		template string
+		expected string
+	}{
+		{"{next_patch}-dev", "1.4.1-dev"},
+		{"{next_minor}-dev", "1.5.0-dev"},
+		{"{major}.{minor}.{patch}-post", "1.4.0-post"},
+	}
+
+	calc := NewCalculator(&config.Config{})
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			version, err := calc.PostReleaseVersion("1.4.0", tt.template)
+			if err != nil {
+				t.Errorf("PostReleaseVersion() error = %v", err)
+				return
+			}
+
+			if version != tt.expected {
+				t.Errorf("PostReleaseVersion() = %v, want %v", version, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBuildMetadata(t *testing.T) {
+	t.Setenv("BUILD_NUMBER", "42")
+
+	metadata, err := BuildMetadata("build.{env:BUILD_NUMBER}.sha.{sha}", "abc1234")
+	if err != nil {
+		t.Fatalf("BuildMetadata() error = %v", err)
+	}
+	if metadata != "build.42.sha.abc1234" {
+		t.Errorf("BuildMetadata() = %v, want build.42.sha.abc1234", metadata)
+	}
+
+	if _, err := BuildMetadata("build.{env:COMMET_UNSET_VARIABLE}", ""); err == nil {
+		t.Error("BuildMetadata() with unset variable should fail")
+	}
+
+	calc := NewCalculator(&config.Config{Version: config.VersionConfig{Format: "semver"}})
+
+	version, err := calc.WithMetadata("1.3.0+build.41", metadata)
+	if err != nil {
+		t.Fatalf("WithMetadata() error = %v", err)
+	}
+	if version != "1.3.0+build.42.sha.abc1234" {
+		t.Errorf("WithMetadata() = %v, want 1.3.0+build.42.sha.abc1234", version)
+	}
+
+	if result, _ := Compare("1.3.0+build.1", "1.3.0+build.2"); result != 0 {
+		t.Errorf("Compare() with different metadata = %d, want 0", result)
+	}
+}
+
+func TestNextCalVer(t *testing.T) {
+	now := time.Date(2026, time.March, 5, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		pattern  string
+		current  string
+		expected string
+		wantErr  bool
+	}{
+		{"first release of the month", "YYYY.MM.MICRO", "2026.2.3", "2026.3.0", false},
+		{"second release of the month", "YYYY.MM.MICRO", "2026.3.0", "2026.3.1", false},
+		{"zero padded", "0Y.0M.MICRO", "26.03.4", "26.03.5", false},
+		{"from semver", "YYYY.MM.MICRO", "0.1.0", "2026.3.0", false},
+		{"v prefix on current", "YYYY.MM.MICRO", "v2026.3.1", "2026.3.2", false},
+		{"daily without micro", "YYYY.0M.0D", "2026.03.04", "2026.03.05", false},
+		{"same day without micro", "YYYY.0M.0D", "2026.03.05", "", true},
+		{"no date segment", "MICRO", "1", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, err := nextCalVer(tt.pattern, tt.current, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("nextCalVer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if version != tt.expected {
+				t.Errorf("nextCalVer() = %v, want %v", version, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCalculateZeroVer(t *testing.T) {
+	cfg := &config.Config{
+		Version: config.VersionConfig{
+			Format:  "semver",
+			ZeroVer: true,
+		},
+		BumpRules: map[string]config.BumpType{
+			"Fix":      config.BumpPatch,
+			"Feature":  config.BumpMinor,
+			"Breaking": config.BumpMajor,
+		},
+	}
+
+	calc := NewCalculator(cfg)
+
+	tests := []struct {
+		name            string
+		currentVersion  string
+		commitType      string
+		expectedVersion string
+	}{
+		{"breaking bumps minor", "0.3.0", "Breaking", "0.4.0"},
+		{"feature bumps patch", "0.3.0", "Feature", "0.3.1"},
+		{"fix bumps patch", "0.3.0", "Fix", "0.3.1"},
+		{"stable versions unaffected", "1.3.0", "Breaking", "2.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, _, err := calc.Calculate(tt.currentVersion, []*parser.Commit{{Type: tt.commitType}})
+			if err != nil {
+				t.Fatalf("Calculate() error = %v", err)
+			}
+
+			if version != tt.expectedVersion {
+				t.Errorf("Calculate() version = %v, want %v", version, tt.expectedVersion)
+			}
+		})
+	}
+}
+
+func TestDetermineBumpScopeRules(t *testing.T) {
+	cfg := &config.Config{
+		BumpRules: map[string]config.BumpType{
+			"Fix":        config.BumpPatch,
+			"Fix(deps)":  config.BumpNone,
+			"Fix(core)":  config.BumpMinor,
+			"Feature":    config.BumpMinor,
+		},
+	}
+
+	calc := NewCalculator(cfg)
+
+	tests := []struct {
+		name     string
+		commit   *parser.Commit
+		expected config.BumpType
+	}{
+		{"scoped rule wins", &parser.Commit{Type: "Fix", Scope: "deps"}, config.BumpNone},
+		{"other scope uses type rule", &parser.Commit{Type: "Fix", Scope: "api"}, config.BumpPatch},
+		{"no scope uses type rule", &parser.Commit{Type: "Fix"}, config.BumpPatch},
+		{"highest scoped rule of several", &parser.Commit{Type: "Fix", Scope: "deps, core"}, config.BumpMinor},
+		{"unmatched scope falls back to type rule", &parser.Commit{Type: "Fix", Scope: "deps,api"}, config.BumpPatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if bump := calc.DetermineBump([]*parser.Commit{tt.commit}); bump != tt.expected {
+				t.Errorf("DetermineBump() = %v, want %v", bump, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDetermineBumpPathRules(t *testing.T) {
+	cfg := &config.Config{
+		BumpRules: map[string]config.BumpType{
+			"Fix":     config.BumpPatch,
+			"Feature": config.BumpMinor,
+		},
+		PathRules: []config.PathRuleConfig{
+			{Paths: []string{"docs/**", "**/*_test.go"}, Bump: config.BumpNone},
+			{Paths: []string{"examples"}, Bump: config.BumpPatch},
+		},
+	}
+
+	calc := NewCalculator(cfg)
+
+	tests := []struct {
+		name     string
+		commit   *parser.Commit
+		expected config.BumpType
+	}{
+		{"docs only", &parser.Commit{Type: "Feature", Files: []string{"docs/guide/intro.md"}}, config.BumpNone},
+		{"docs and tests", &parser.Commit{Type: "Fix", Files: []string{"docs/a.md", "internal/parser/parser_test.go"}}, config.BumpNone},
+		{"root test file", &parser.Commit{Type: "Fix", Files: []string{"main_test.go"}}, config.BumpNone},
+		{"source file keeps bump", &parser.Commit{Type: "Feature", Files: []string{"docs/a.md", "main.go"}}, config.BumpMinor},
+		{"capped by directory rule", &parser.Commit{Type: "Feature", Files: []string{"examples/basic/main.go"}}, config.BumpPatch},
+		{"no files keeps bump", &parser.Commit{Type: "Feature"}, config.BumpMinor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if bump := calc.DetermineBump([]*parser.Commit{tt.commit}); bump != tt.expected {
+				t.Errorf("DetermineBump() = %v, want %v", bump, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDetermineBumpBoardRules(t *testing.T) {
+	cfg := &config.Config{
+		BumpRules: map[string]config.BumpType{
+			"Fix":  config.BumpPatch,
+			"Docs": config.BumpNone,
+		},
+		BoardRules: map[string]config.BumpType{
+			"BUG":  config.BumpPatch,
+			"FEAT": config.BumpMinor,
+		},
+	}
+
+	calc := NewCalculator(cfg)
+
+	tests := []struct {
+		name     string
+		commit   *parser.Commit
+		expected config.BumpType
+	}{
+		{"type rule wins", &parser.Commit{Type: "Docs", Board: "FEAT-1", Boards: []string{"FEAT-1"}}, config.BumpNone},
+		{"unknown type uses board", &parser.Commit{Type: "Crash", Board: "BUG-123", Boards: []string{"BUG-123"}}, config.BumpPatch},
+		{"highest board of several", &parser.Commit{Type: "Update", Board: "BUG-1", Boards: []string{"BUG-1", "FEAT-2"}}, config.BumpMinor},
+		{"unknown board", &parser.Commit{Type: "Update", Board: "OPS-7", Boards: []string{"OPS-7"}}, config.BumpNone},
+		{"no board", &parser.Commit{Type: "Update"}, config.BumpNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if bump := calc.DetermineBump([]*parser.Commit{tt.commit}); bump != tt.expected {
+				t.Errorf("DetermineBump() = %v, want %v", bump, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCalculateCustomLevels(t *testing.T) {
+	cfg := &config.Config{
+		Version: config.VersionConfig{Format: "four-part"},
+		BumpRules: map[string]config.BumpType{
+			"Feature": config.BumpMinor,
+			"Hotfix":  "hotfix",
+			"Deploy":  "deploy",
+		},
+		BumpLevels: map[string]config.BumpType{
+			"hotfix": config.BumpBuild,
+			"deploy": config.BumpPatch,
+		},
+	}
+
+	calc := NewCalculator(cfg)
+
+	tests := []struct {
+		name     string
+		commits  []*parser.Commit
+		expected string
+		bump     config.BumpType
+	}{
+		{"hotfix raises build", []*parser.Commit{{Type: "Hotfix"}}, "1.2.3.5", config.BumpBuild},
+		{"deploy raises patch", []*parser.Commit{{Type: "Deploy"}}, "1.2.4.0", config.BumpPatch},
+		{"highest level wins", []*parser.Commit{{Type: "Hotfix"}, {Type: "Feature"}}, "1.3.0.0", config.BumpMinor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, bump, err := calc.Calculate("1.2.3.4", tt.commits)
+			if err != nil {
+				t.Fatalf("Calculate() error = %v", err)
+			}
+			if got != tt.expected || bump != tt.bump {
+				t.Errorf("Calculate() = %v, %v, want %v, %v", got, bump, tt.expected, tt.bump)
+			}
+		})
+	}
+}
+
+func TestCalculateCaseInsensitiveTypes(t *testing.T) {
+	cfg := &config.Config{
+		BumpRules: map[string]config.BumpType{
+			"Feature":   config.BumpMinor,
+			"Fix":       config.BumpPatch,
+			"Fix(deps)": config.BumpNone,
+		},
+	}
+
+	tests := []struct {
+		name        string
+		insensitive bool
+		commits     []*parser.Commit
+		expected    string
+	}{
+		{"exact case only", false, []*parser.Commit{{Type: "FEATURE"}}, "1.2.3"},
+		{"any case", true, []*parser.Commit{{Type: "FEATURE"}}, "1.3.0"},
+		{"scoped rule", true, []*parser.Commit{{Type: "fix", Scope: "deps"}}, "1.2.3"},
+		{"scope without rule", true, []*parser.Commit{{Type: "fix", Scope: "api"}}, "1.2.4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg.CaseInsensitiveTypes = tt.insensitive
+			got, _, err := NewCalculator(cfg).Calculate("1.2.3", tt.commits)
+			if err != nil {
+				t.Fatalf("Calculate() error = %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Calculate() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPromote(t *testing.T) {
+	calc := NewCalculator(&config.Config{})
+
+	tests := []struct {
+		current  string
+		expected string
+		wantErr  bool
+	}{
+		{"2.0.0-rc.3", "2.0.0", false},
+		{"1.4.0-beta.1+build.7", "1.4.0", false},
+		{"1.4.0", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.current, func(t *testing.T) {
+			got, err := calc.Promote(tt.current)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Promote() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("Promote() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCalculateFourPart(t *testing.T) {
+	rules := map[string]config.BumpType{
+		"Fix":      config.BumpPatch,
+		"Feature":  config.BumpMinor,
+		"Breaking": config.BumpMajor,
+		"Docs":     config.BumpBuild,
+	}
+
+	tests := []struct {
+		name            string
+		buildSegment    string
+		currentVersion  string
+		commitType      string
+		expectedVersion string
+	}{
+		{"build bump", "reset", "1.2.3.4", "Docs", "1.2.3.5"},
+		{"patch resets build", "reset", "1.2.3.4", "Fix", "1.2.4.0"},
+		{"minor resets build", "reset", "1.2.3.4", "Feature", "1.3.0.0"},
+		{"major resets build", "reset", "1.2.3.4", "Breaking", "2.0.0.0"},
+		{"patch keeps counting", "increment", "1.2.3.4", "Fix", "1.2.4.5"},
+		{"three segments start a build number", "increment", "1.2.3", "Feature", "1.3.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				Version:   config.VersionConfig{Format: "four-part", BuildSegment: tt.buildSegment},
+				BumpRules: rules,
+			}
+
+			version, _, err := NewCalculator(cfg).Calculate(tt.currentVersion, []*parser.Commit{{Type: tt.commitType}})
+			if err != nil {
+				t.Fatalf("Calculate() error = %v", err)
+			}
+
+			if version != tt.expectedVersion {
+				t.Errorf("Calculate() version = %v, want %v", version, tt.expectedVersion)
+			}
+		})
+	}
+}
+
+func TestCalculateMinBump(t *testing.T) {
+	cfg := &config.Config{
+		Version: config.VersionConfig{Format: "semver", MinBump: config.BumpPatch},
+		BumpRules: map[string]config.BumpType{
+			"Docs":    config.BumpNone,
+			"Feature": config.BumpMinor,
+		},
+	}
+
+	calc := NewCalculator(cfg)
+
+	tests := []struct {
+		name            string
+		commits         []*parser.Commit
+		expectedVersion string
+	}{
+		{"none raised to patch", []*parser.Commit{{Type: "Docs"}}, "1.2.4"},
+		{"higher bumps unaffected", []*parser.Commit{{Type: "Docs"}, {Type: "Feature"}}, "1.3.0"},
+		{"no commits no release", nil, "1.2.3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, _, err := calc.Calculate("1.2.3", tt.commits)
+			if err != nil {
+				t.Fatalf("Calculate() error = %v", err)
+			}
+
+			if version != tt.expectedVersion {
+				t.Errorf("Calculate() version = %v, want %v", version, tt.expectedVersion)
+			}
+		})
+	}
+}
+
+func TestInLine(t *testing.T) {
+	tests := []struct {
+		version string
+		line    string
+		want    bool
+	}{
+		{"1.2.8", "1.2.x", true},
+		{"v1.2.0", "1.2.x", true},
+		{"1.2.9-rc.1", "1.2.x", true},
+		{"1.2.9+build.5", "1.2.x", true},
+		{"1.3.0", "1.2.x", false},
+		{"1.20.0", "1.2.x", false},
+		{"1.7.3", "1.x", true},
+		{"2.0.0", "1.x", false},
+		{"1.2.3.4", "1.2.x", true},
+	}
+
+	for _, tt := range tests {
+		if got := InLine(tt.version, tt.line); got != tt.want {
+			t.Errorf("InLine(%q, %q) = %v, want %v", tt.version, tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestAliases(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{"1.4.2", "1 1.4"},
+		{"v1.4.2", "v1 v1.4"},
+		{"2.0.0+build.7", "2 2.0"},
+		{"1.5.0-rc.1", ""},
+		{"1.2.3.4", ""},
+		{"2026.3.0", "2026 2026.3"},
+	}
+
+	for _, tt := range tests {
+		if got := strings.Join(Aliases(tt.version), " "); got != tt.want {
+			t.Errorf("Aliases(%q) = %q, want %q", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestCalculatePEP440(t *testing.T) {
+	tests := []struct {
+		name           string
+		currentVersion string
+		prerelease     string
+		commitType     string
+		expected       string
+	}{
+		{"patch", "1.2.3", "", "Fix", "1.2.4"},
+		{"minor", "1.2.3", "", "Feature", "1.3.0"},
+		{"major keeps epoch", "1!1.2.3", "", "Breaking", "1!2.0.0"},
+		{"two segments", "1.2", "", "Fix", "1.2.1"},
+		{"post release", "1.2.3.post1", "", "Fix", "1.2.4"},
+		{"finalize rc", "1.3.0rc2", "", "Fix", "1.3.0"},
+		{"finalize dev", "1.3.0.dev4", "", "Feature", "1.3.0"},
+		{"first rc", "1.2.3", "rc", "Feature", "1.3.0rc1"},
+		{"next rc", "1.3.0rc1", "rc", "Fix", "1.3.0rc2"},
+		{"beta to rc", "1.3.0b2", "rc", "Fix", "1.3.0rc1"},
+		{"alpha alias", "1.2.3", "alpha", "Fix", "1.2.4a1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				Version: config.VersionConfig{Format: "pep440", Prerelease: tt.prerelease},
+				BumpRules: map[string]config.BumpType{
+					"Fix":      config.BumpPatch,
+					"Feature":  config.BumpMinor,
+					"Breaking": config.BumpMajor,
+				},
+			}
+
+			version, _, err := NewCalculator(cfg).Calculate(tt.currentVersion, []*parser.Commit{{Type: tt.commitType}})
+			if err != nil {
+				t.Fatalf("Calculate() error = %v", err)
+			}
+			if version != tt.expected {
+				t.Errorf("Calculate() = %v, want %v", version, tt.expected)
+			}
+		})
+	}
+}
+
+func TestComparePEP440(t *testing.T) {
+	// Ascending PEP 440 order
+	ordered := []string{
+		"1.0.dev1", "1.0a1.dev1", "1.0a1", "1.0a2", "1.0b1", "1.0rc1", "1.0",
+		"1.0.post1.dev1", "1.0.post1", "1.0.1", "1.1", "2.0", "1!0.1",
+	}
+
+	scheme := SchemeFor(&config.Config{Version: config.VersionConfig{Format: "pep440"}})
+	for i := range ordered {
+		for j := range ordered {
+			want := 0
+			switch {
+			case i < j:
+				want = -1
+			case i > j:
+				want = 1
+			}
+
+			got, err := scheme.Compare(ordered[i], ordered[j])
+			if err != nil {
+				t.Fatalf("Compare(%s, %s) error = %v", ordered[i], ordered[j], err)
+			}
+			if got != want {
+				t.Errorf("Compare(%s, %s) = %d, want %d", ordered[i], ordered[j], got, want)
+			}
+		}
+	}
+}
+
+// evenScheme is a custom scheme that only releases even patch numbers.
+type evenScheme struct{ semverScheme }
+
+func (s evenScheme) Bump(current string, bump config.BumpType) (string, error) {
+	next, err := s.semverScheme.Bump(current, bump)
+	if err != nil || bump != config.BumpPatch {
+		return next, err
+	}
+	return s.semverScheme.Bump(next, bump)
+}
+
+func TestRegisterScheme(t *testing.T) {
+	Register("even", func(cfg config.VersionConfig) Scheme { return evenScheme{} })
+
+	cfg := config.DefaultConfig()
+	cfg.Version.Format = "even"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	version, _, err := NewCalculator(cfg).Calculate("1.2.0", []*parser.Commit{{Type: "Fix"}})
+	if err != nil {
+		t.Fatalf("Calculate() error = %v", err)
+	}
+	if version != "1.2.2" {
+		t.Errorf("Calculate() = %v, want 1.2.2", version)
+	}
+}
+
+func TestExpand(t *testing.T) {
+	t.Setenv("BUILD_NUMBER", "")
+	t.Setenv("GITHUB_RUN_NUMBER", "412")
+
+	tests := []struct {
+		template string
+		expected string
+	}{
+		{"{version}", "1.3.0"},
+		{"v{version}", "v1.3.0"},
+		{"{version}+{build}", "1.3.0+412"},
+		{"{version}.{build}", "1.3.0.412"},
+	}
+
+	for _, tt := range tests {
+		got, err := Expand(tt.template, "1.3.0")
+		if err != nil {
+			t.Fatalf("Expand(%q) error = %v", tt.template, err)
+		}
+		if got != tt.expected {
+			t.Errorf("Expand(%q) = %v, want %v", tt.template, got, tt.expected)
+		}
+	}
+
+	for _, name := range buildNumberVars {
+		t.Setenv(name, "")
+	}
+	if _, err := Expand("{version}+{build}", "1.3.0"); err == nil {
+		t.Error("Expand() without a build number should fail")
+	}
+}
+
+func TestCalculateRollup(t *testing.T) {
+	cfg := &config.Config{
+		Version: config.VersionConfig{Format: "semver"},
+		BumpRules: map[string]config.BumpType{
+			"Fix":     config.BumpPatch,
+			"Feature": config.BumpMinor,
+			"Docs":    config.BumpNone,
+		},
+		Rollup: config.RollupConfig{PatchThreshold: 3, MinorThreshold: 2},
+	}
+	calc := NewCalculator(cfg)
+
+	commits := func(types ...string) []*parser.Commit {
+		var result []*parser.Commit
+		for _, commitType := range types {
+			result = append(result, &parser.Commit{Type: commitType})
+		}
+		return result
+	}
+
+	tests := []struct {
+		name     string
+		commits  []*parser.Commit
+		expected string
+	}{
+		{"below patch threshold", commits("Fix", "Fix", "Docs"), "1.2.4"},
+		{"patches roll up to minor", commits("Fix", "Fix", "Fix"), "1.3.0"},
+		{"single feature", commits("Feature", "Fix"), "1.3.0"},
+		{"features roll up to major", commits("Feature", "Feature"), "2.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, _, err := calc.Calculate("1.2.3", tt.commits)
+			if err != nil {
+				t.Fatalf("Calculate() error = %v", err)
+			}
+			if version != tt.expected {
+				t.Errorf("Calculate() = %v, want %v", version, tt.expected)
+			}
+		})
+	}
+}